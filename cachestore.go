@@ -4,9 +4,15 @@ Package cachestore is the caching (key->value) service abstraction layer
 package cachestore
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand/v2"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,173 +23,2114 @@ import (
 
 // Set will set a key->value using the current engine
 //
-// NOTE: redis only supports dependency keys at this time
+// The value expires after the default TTL (see: WithDefaultTTL), otherwise it never expires
+// NOTE: memcached does not support dependency keys
 // Value should be used as a string for best results
-func (c *Client) Set(ctx context.Context, key string, value interface{}, dependencies ...string) error {
+func (c *Client) Set(ctx context.Context, key string, value interface{}, dependencies ...string) (err error) {
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationSet).End()
 
-	// Require a key to be present
-	if len(key) == 0 {
-		return ErrKeyRequired
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationSet, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.stored(1, err)
+		c.observe(ctx, operationSet, key, start, writeResult(err))
+		c.onSet(operationSet, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return err
 	}
 
-	// Redis
-	if c.Engine() == Redis {
-		return cache.Set(ctx, c.options.redis, key, value, dependencies...)
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Compress the value (if enabled) and check the size
+	if value, err = c.encodeValue(value); err != nil {
+		return err
+	} else if err = c.checkValueSize(valueToBytes(value)); err != nil {
+		return err
+	}
+
+	// Use the default TTL (if set)
+	ttl := c.options.defaultTTL
+
+	// Redis (and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		c.setFallback(key, valueToBytes(value), ttl)
+		if err = c.retry(ctx, func() error {
+			return setWithTTLRedis(ctx, c.options.redis, key, value, ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, key)
+		c.setLocal(key, valueToBytes(value), ttl)
+		return nil
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		return setMemcached(c.options.memcached, key, valueToBytes(value), ttl)
+	}
+
+	// FreeCache (and link the dependencies)
+	if err = setFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key, valueToBytes(value), int(ttl.Seconds()),
+	); err != nil {
+		return err
+	}
+	c.linkDependencies(ctx, key, dependencies)
+	return nil
+}
+
+// SetBytes will set a key->value of raw bytes using the current engine (no string conversion or serializer)
+//
+// The value expires after the default TTL (see: WithDefaultTTL), otherwise it never expires
+// NOTE: memcached does not support dependency keys
+func (c *Client) SetBytes(ctx context.Context, key string, value []byte, dependencies ...string) (err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationSetBytes).End()
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationSetBytes, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.stored(1, err)
+		c.observe(ctx, operationSetBytes, key, start, writeResult(err))
+		c.onSet(operationSetBytes, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Compress the value (if enabled, always with a header) and check the size
+	if value, err = c.compressBytes(value); err != nil {
+		return err
+	} else if err = c.checkValueSize(value); err != nil {
+		return err
+	}
+
+	// Use the default TTL (if set)
+	ttl := c.options.defaultTTL
+
+	// Redis (and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		c.setFallback(key, value, ttl)
+		if err = c.retry(ctx, func() error {
+			return setWithTTLRedis(ctx, c.options.redis, key, value, ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, key)
+		c.setLocal(key, value, ttl)
+		return nil
 	}
 
-	// FreeCache
-	return c.options.freeCache.Set([]byte(key), []byte(value.(string)), 0)
+	// Memcached
+	if c.Engine() == Memcached {
+		return setMemcached(c.options.memcached, key, value, ttl)
+	}
+
+	// FreeCache (and link the dependencies)
+	if err = setFreeCache(c.options.freeCache, c.options.freeCacheLimit, key, value, int(ttl.Seconds())); err != nil {
+		return err
+	}
+	c.linkDependencies(ctx, key, dependencies)
+	return nil
 }
 
 // SetTTL will set a key->value using the current engine with a TTL
 //
-// NOTE: redis only supports dependency keys at this time
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the value never expires
+// NOTE: memcached does not support dependency keys
 // Value should be used as a string for best results
 func (c *Client) SetTTL(ctx context.Context, key string, value interface{}, ttl time.Duration, dependencies ...string) error {
+	return c.setTTL(ctx, operationSetTTL, key, value, ttl, dependencies...)
+}
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+// setTTL will set a key->value with a TTL (operation is used for the errors, metrics and hooks)
+func (c *Client) setTTL(ctx context.Context, operation, key string, value interface{},
+	ttl time.Duration, dependencies ...string) (err error) {
 
-	// Require a key to be present
-	if len(key) == 0 {
-		return ErrKeyRequired
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operation, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.stored(1, err)
+		c.observe(ctx, operation, key, start, writeResult(err))
+		c.onSet(operation, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return err
 	}
 
-	// Redis
-	if c.Engine() == Redis {
-		return cache.SetExp(ctx, c.options.redis, key, value, ttl, dependencies...)
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Compress the value (if enabled) and check the size
+	if value, err = c.encodeValue(value); err != nil {
+		return err
+	} else if err = c.checkValueSize(valueToBytes(value)); err != nil {
+		return err
+	}
+
+	// A zero TTL uses the default TTL (if set), then add the jitter (if enabled)
+	ttl = c.jitterTTL(c.ttlOrDefault(ttl))
+
+	// Redis (and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		c.setFallback(key, valueToBytes(value), ttl)
+		if err = c.retry(ctx, func() error {
+			return setWithTTLRedis(ctx, c.options.redis, key, value, ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, key)
+		c.setLocal(key, valueToBytes(value), ttl)
+		return nil
 	}
 
-	// FreeCache
-	return c.options.freeCache.Set([]byte(key), []byte(value.(string)), int(ttl.Seconds()))
+	// Memcached
+	if c.Engine() == Memcached {
+		return setMemcached(c.options.memcached, key, valueToBytes(value), ttl)
+	}
+
+	// FreeCache (and link the dependencies)
+	if err = setFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key, valueToBytes(value), int(ttl.Seconds()),
+	); err != nil {
+		return err
+	}
+	c.linkDependencies(ctx, key, dependencies)
+	return nil
 }
 
 // Get will return a value from a given key
 //
 // Redis will be an interface{} but really a string (empty string)
+// A miss returns an empty string and no error, or ErrKeyNotFound using WithStrictMisses()
 func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	value, found, err := c.get(ctx, operationGet, key)
+	if err == nil && !found && c.options.strictMisses {
+		err = ErrKeyNotFound
+	}
+	return value, c.wrapError(operationGet, key, err)
+}
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+// get will return a value from a given key and if the key was found (operation is used for the metrics and hooks)
+func (c *Client) get(ctx context.Context, operation, key string) (value string, found bool, err error) {
 
-	// Require a key to be present
-	if len(key) == 0 {
-		return "", ErrKeyRequired
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operation).End()
+
+	// Update the statistics and metrics, run the hooks (hit or miss)
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.read(found, err)
+		c.observe(ctx, operation, key, start, readResult(found, err))
+		c.onRead(operation, key, found, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return "", false, err
 	}
 
-	// Switch on the engine
-	if c.Engine() == Redis {
-		str, err := cache.Get(ctx, c.options.redis, key)
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return "", false, err
+	}
+
+	// Read the value (shared by the concurrent reads of the key, see: WithSingleFlight)
+	shared, err := c.shareRead(ctx, sharedReadValue+key, func(ctx context.Context) (interface{}, error) {
+		value, found, readErr := c.readValue(ctx, key)
+		return sharedValue{found: found, value: value}, readErr
+	})
+	result, _ := shared.(sharedValue)
+	return result.value, result.found, err
+}
+
+// readValue will read the value of a key (already sanitized and prefixed) and if the key was found
+func (c *Client) readValue(ctx context.Context, key string) (value string, found bool, err error) {
+
+	// Switch on the engine (check the local tier first)
+	if c.Engine().usesRedis() {
+		if data, ok := c.getLocal(key); ok {
+			value, err = c.decodeString(string(data))
+			return value, true, err
+		}
+		var str string
+		err = c.retry(ctx, func() (getErr error) {
+			str, getErr = cache.Get(ctx, c.readRedis(), key)
+			return getErr
+		})
 		if err != nil && errors.Is(err, redis.ErrNil) {
-			return "", nil
+			return "", false, nil
 		} else if err != nil {
-			return "", err
+			data, fallbackFound, ok := c.readFallback(key, err)
+			if !ok {
+				return "", false, err
+			} else if !fallbackFound {
+				return "", false, nil
+			}
+			value, err = c.decodeString(string(data))
+			return value, true, err
+		}
+		c.setLocal(key, []byte(str), 0)
+		value, err = c.decodeString(str)
+		return value, true, err
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		var data []byte
+		data, err = getMemcached(c.options.memcached, key)
+		if err != nil && errors.Is(err, ErrKeyNotFound) {
+			return "", false, nil
+		} else if err != nil {
+			return "", false, err
+		}
+		if data, err = c.decompressValue(data); err != nil {
+			return "", true, err
 		}
-		return str, nil
+		return string(data), true, nil
 	}
 
 	// Check using FreeCache
 	data, err := c.options.freeCache.Get([]byte(key))
 	if err != nil && errors.Is(err, freecache.ErrNotFound) { // Ignore this error
-		return "", nil
+		return "", false, nil
 	} else if err != nil { // Real error getting the cache value
+		return "", false, err
+	}
+	if data, err = c.decompressValue(data); err != nil {
+		return "", true, err
+	}
+	return string(data), true, nil
+}
+
+// GetBytes will return the raw bytes stored at the key (no string conversion or serializer)
+//
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) GetBytes(ctx context.Context, key string) (value []byte, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationGetBytes).End()
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationGetBytes, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks (ErrKeyNotFound is a miss)
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.readModel(err)
+		c.observe(ctx, operationGetBytes, key, start, modelResult(err))
+		c.onReadModel(operationGetBytes, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Redis (check the local tier first)
+	if c.Engine().usesRedis() {
+		var ok bool
+		if value, ok = c.getLocal(key); !ok {
+			if err = c.retry(ctx, func() (getErr error) {
+				value, getErr = cache.GetBytes(ctx, c.readRedis(), key)
+				return getErr
+			}); err != nil {
+				if errors.Is(err, redis.ErrNil) {
+					return nil, ErrKeyNotFound
+				}
+				return nil, err
+			}
+			c.setLocal(key, value, 0)
+		}
+	} else if c.Engine() == Memcached { // Memcached (missing keys are ErrKeyNotFound)
+		if value, err = getMemcached(c.options.memcached, key); err != nil {
+			return nil, err
+		}
+	} else if value, err = c.options.freeCache.Get([]byte(key)); err != nil { // FreeCache
+		if errors.Is(err, freecache.ErrNotFound) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+
+	// Decompress the value (if enabled)
+	return c.decompressValue(value)
+}
+
+// GetRaw will return the stored JSON of the key without decoding it (ie: forward it verbatim in an HTTP response)
+//
+// The value is decompressed and validated, a value that is not well-formed JSON returns ErrNotJSON (ie: msgpack)
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) GetRaw(ctx context.Context, key string) (raw json.RawMessage, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationGetRaw).End()
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationGetRaw, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks (ErrKeyNotFound is a miss)
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.readModel(err)
+		c.observe(ctx, operationGetRaw, key, start, modelResult(err))
+		c.onReadModel(operationGetRaw, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Get the stored value (without the version header, see: WithModelVersioning)
+	var b []byte
+	if b, err = c.getModelBytes(ctx, key); err != nil {
+		return nil, err
+	} else if b, err = c.checkVersion(ctx, b, nil); err != nil {
+		return nil, err
+	} else if !json.Valid(b) {
+		return nil, ErrNotJSON
+	}
+
+	// The concurrent reads share the same bytes (see: WithSingleFlight)
+	if c.options.reads != nil {
+		b = bytes.Clone(b)
+	}
+	return b, nil
+}
+
+// Exists will return true if the key is found in the cache (without transferring the value)
+func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
+
+	// Sanitize the key, require it and add the prefix
+	key, err := c.buildKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Redis (check the local tier first)
+	if c.Engine().usesRedis() {
+		if _, ok := c.getLocal(key); ok {
+			return true, nil
+		}
+		var found bool
+		err = c.retry(ctx, func() (existsErr error) {
+			found, existsErr = cache.Exists(ctx, c.readRedis(), key)
+			return existsErr
+		})
+		return found, err
+	}
+
+	// Memcached (has no exists command, the value is fetched)
+	if c.Engine() == Memcached {
+		if _, err := getMemcached(c.options.memcached, key); err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+
+	// FreeCache (only report the presence of the key)
+	if _, err := c.options.freeCache.Get([]byte(key)); err != nil {
+		if errors.Is(err, freecache.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Touch will update the expiration of an existing key without rewriting the value
+//
+// A ttl of zero (or less) will persist the key (no expiration)
+func (c *Client) Touch(ctx context.Context, key string, ttl time.Duration) error {
+
+	// Sanitize the key, require it and add the prefix
+	key, err := c.buildKey(key)
+	if err != nil {
+		return err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+	return c.touch(ctx, key, ttl)
+}
+
+// touch will update the expiration of an existing key (already sanitized and prefixed) using the current engine
+func (c *Client) touch(ctx context.Context, key string, ttl time.Duration) error {
+
+	// Redis (the local copy is removed, it would keep the old expiration)
+	if c.Engine().usesRedis() {
+		c.deleteLocal(key)
+		return c.retry(ctx, func() error {
+			return touchRedis(ctx, c.options.redis, key, ttl)
+		})
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		return touchMemcached(c.options.memcached, key, ttl)
+	}
+
+	// FreeCache (zero is no expiration)
+	if err := c.options.freeCache.Touch([]byte(key), int(ttl.Seconds())); err != nil {
+		if errors.Is(err, freecache.ErrNotFound) {
+			return ErrKeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetAndRefresh will return the value of the key and reset its expiration to the TTL in a single atomic step
+//
+// Used for a sliding expiration (ie: sessions), the key cannot expire between the read and the refresh
+// A zero TTL removes the expiration (same as Touch), a missing key returns ErrKeyNotFound (nothing is written)
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) GetAndRefresh(ctx context.Context, key string, ttl time.Duration) (value string, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationGetAndRefresh).End()
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationGetAndRefresh, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks (ErrKeyNotFound is a miss)
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.readModel(err)
+		c.observe(ctx, operationGetAndRefresh, key, start, modelResult(err))
+		c.onReadModel(operationGetAndRefresh, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return "", err
+	}
+
+	// Redis (always read from redis, the local copy is replaced with the new expiration)
+	var data []byte
+	if c.Engine().usesRedis() {
+		if data, err = getAndRefreshRedis(ctx, c.options.redis, key, ttl); err != nil {
+			return "", err
+		}
+		c.setLocal(key, data, ttl)
+	} else if c.Engine() == Memcached { // Memcached cannot read and touch a key atomically
+		return "", c.engineNotSupported()
+	} else if data, err = getAndRefreshFreeCache(c.options.freeCache, key, int(ttl.Seconds())); err != nil { // FreeCache
+		return "", err
+	}
+
+	// Decompress the value (if enabled)
+	if data, err = c.decompressValue(data); err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
 
 // Delete will remove a key from the cache
-func (c *Client) Delete(ctx context.Context, key string) error {
+func (c *Client) Delete(ctx context.Context, key string) (err error) {
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationDelete).End()
 
-	// Require a key to be present
-	if len(key) == 0 {
-		return ErrKeyRequired
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationDelete, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.deleted(1, err)
+		c.observe(ctx, operationDelete, key, start, writeResult(err))
+		c.onError(operationDelete, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return err
 	}
 
-	// Switch on the engine
-	if c.Engine() == Redis {
-		_, err := cache.DeleteWithoutDependency(ctx, c.options.redis, key)
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
 		return err
 	}
 
+	// Switch on the engine (remove from both tiers)
+	if c.Engine().usesRedis() {
+		c.deleteLocal(key)
+		c.deleteFallback(key)
+		return c.retry(ctx, func() error {
+			_, deleteErr := cache.DeleteWithoutDependency(ctx, c.options.redis, key)
+			return deleteErr
+		})
+	} else if c.Engine() == Memcached {
+		return deleteMemcached(c.options.memcached, key)
+	}
+
 	// Use FreeCache
 	_ = c.options.freeCache.Del([]byte(key))
 	return nil
 }
 
-// SetModel will set any model or struct (parsing Model->JSON (bytes))
+// DeleteMany will remove several keys from the cache in a single call
 //
-// Model needs to be a pointer to a struct
-// NOTE: redis only supports dependency keys at this time
-func (c *Client) SetModel(ctx context.Context, key string, model interface{},
-	ttl time.Duration, dependencies ...string) error {
+// Redis uses a single DEL for all the keys, keys that do not exist are ignored
+// Every key is required (ErrKeyRequired), nil is returned even if only some keys existed
+func (c *Client) DeleteMany(ctx context.Context, keys ...string) (err error) {
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func() {
+		c.options.stats.deleted(len(keys), err)
+		c.observe(ctx, operationDeleteMany, "", start, writeResult(err))
+		c.onError(operationDeleteMany, "", err)
+	}()
 
-	// Require a key to be present
-	if len(key) == 0 {
-		return ErrKeyRequired
-	}
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
-	// Redis
-	if c.Engine() == Redis {
-		return cache.SetToJSON(ctx, c.options.redis, key, model, ttl, dependencies...)
+	// Sanitize, require and prefix all keys
+	keys, err = c.buildKeys(keys)
+	if err != nil {
+		return err
+	} else if len(keys) == 0 {
+		return nil
 	}
 
-	// Parse into JSON
-	responseBytes, err := json.Marshal(&model)
-	if err != nil {
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
 		return err
 	}
 
-	// FreeCache (store the bytes)
-	return c.options.freeCache.Set([]byte(key), responseBytes, int(ttl.Seconds()))
+	// Switch on the engine (remove from both tiers)
+	if c.Engine().usesRedis() {
+		c.deleteLocal(keys...)
+		c.deleteFallback(keys...)
+		return c.retry(ctx, func() error {
+			_, deleteErr := deleteMultiRedis(ctx, c.options.redis, keys)
+			return deleteErr
+		})
+	} else if c.Engine() == Memcached {
+		for _, key := range keys {
+			if err = deleteMemcached(c.options.memcached, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Use FreeCache (loop each key)
+	for _, key := range keys {
+		_ = c.options.freeCache.Del([]byte(key))
+	}
+	return nil
 }
 
-// GetModel will get a model (parsing JSON (bytes) -> Model)
+// DeleteByPattern will remove all the keys matching the pattern (glob style: user:123:*) and return the total removed
 //
-// Model needs to be a pointer to a struct
-func (c *Client) GetModel(ctx context.Context, key string, model interface{}) error {
+// Redis uses a cursor based SCAN (never KEYS) with a DEL for each batch, see: WithScanCount()
+// The key prefix is added to the pattern (only keys under the prefix are matched)
+// NOTE: freecache and memcached cannot match keys by pattern (ErrEngineNotSupported)
+func (c *Client) DeleteByPattern(ctx context.Context, pattern string) (total int, err error) {
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(pattern string) {
+		c.options.stats.deleted(total, err)
+		c.observe(ctx, operationDeleteByPattern, pattern, start, writeResult(err))
+		c.onError(operationDeleteByPattern, pattern, err)
+	}(pattern)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Require the pattern and add the prefix
+	if pattern = strings.TrimSpace(pattern); len(pattern) == 0 {
+		return 0, ErrKeyRequired
+	}
+	pattern = escapePattern(c.options.keyPrefix) + pattern
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	// Only Redis can match keys by pattern
+	if !c.Engine().usesRedis() {
+		return 0, c.engineNotSupported()
+	}
+
+	// Remove the local and fallback copies of the matching keys
+	return deleteByPatternRedis(ctx, c.options.redis, pattern, c.options.scanCount, func(keys []string) {
+		c.deleteLocal(keys...)
+		c.deleteFallback(keys...)
+	})
+}
+
+// Scan will call fn for each key matching the pattern (glob style: user:123:*) without loading all the keys at once
+//
+// Redis uses a cursor based SCAN (never KEYS) in batches, see: WithScanCount()
+// The key prefix is added to the pattern and removed from the keys passed to fn
+// Iterating stops if fn returns an error (returned as is) or if the context is done (checked between batches)
+// A key can be passed more than once if it was added or removed during the iteration (SCAN guarantee)
+// NOTE: freecache and memcached cannot enumerate keys (ErrEngineNotSupported)
+func (c *Client) Scan(ctx context.Context, pattern string, fn func(key string) error) (err error) {
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(pattern string) {
+		c.observe(ctx, operationScan, pattern, start, writeResult(err))
+		c.onError(operationScan, pattern, err)
+	}(pattern)
 
-	// Sanitize the key (trailing or leading spaces)
-	key = strings.TrimSpace(key)
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
-	// Require a key to be present
-	if len(key) == 0 {
+	// Require the pattern and add the prefix
+	if pattern = strings.TrimSpace(pattern); len(pattern) == 0 {
 		return ErrKeyRequired
 	}
+	pattern = escapePattern(c.options.keyPrefix) + pattern
 
-	// Redis
-	if c.Engine() == Redis {
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
 
-		// Get the record as bytes
-		b, err := cache.GetBytes(ctx, c.options.redis, key)
-		if err != nil {
-			if errors.Is(err, redis.ErrNil) {
-				return ErrKeyNotFound
+	// Only Redis can enumerate the keys
+	if !c.Engine().usesRedis() {
+		return c.engineNotSupported()
+	}
+
+	return scanRedis(ctx, c.options.redis, pattern, c.options.scanCount, func(_ redis.Conn, keys []string) error {
+		for _, key := range keys {
+			if err := fn(c.stripKey(key)); err != nil {
+				return err
 			}
-			return err
 		}
+		return nil
+	})
+}
 
-		// Sanity check to make sure there is a value to unmarshal
-		if len(b) == 0 {
-			return ErrKeyNotFound
-		}
+// GetMulti will return the values for several keys in a single call
+//
+// Keys that are not found will be absent from the returned map
+func (c *Client) GetMulti(ctx context.Context, keys ...string) (results map[string]string, err error) {
 
-		return json.Unmarshal(b, &model)
-	} else if c.Engine() == FreeCache {
-		if b, err := c.options.freeCache.Get([]byte(key)); err == nil && len(b) > 0 {
-			return json.Unmarshal(b, &model)
+	// Update the metrics, run the hooks (the hits and misses are counted below)
+	start := time.Now()
+	defer func() {
+		c.observe(ctx, operationGetMulti, "", start, writeResult(err))
+		c.onError(operationGetMulti, "", err)
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize, require and prefix all keys (the requested keys are kept for the results, see: WithKeyHashing)
+	requested := keys
+	keys, err = c.buildKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Read the values (the missing keys are absent)
+	var values map[string]string
+	if values, err = c.readMulti(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	// Decompress the values (if enabled), the results use the requested keys (without the prefix or the hashing)
+	results = make(map[string]string, len(values))
+	for i, key := range keys {
+		if value, found := values[key]; found {
+			if results[c.trimKey(requested[i])], err = c.decodeString(value); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// Not found
-	return ErrKeyNotFound
+	// Count the keys found and missing (and run the hooks)
+	c.options.stats.hits.Add(int64(len(results)))
+	c.options.stats.misses.Add(int64(len(keys) - len(results)))
+	for i, key := range keys {
+		_, found := values[key]
+		c.onRead(operationGetMulti, c.trimKey(requested[i]), found, nil)
+	}
+	return results, nil
+}
+
+// readMulti will read the stored values of several keys (already sanitized and prefixed)
+//
+// The missing keys are absent from the values (not decompressed)
+func (c *Client) readMulti(ctx context.Context, keys []string) (values map[string]string, err error) {
+
+	// Redis (single MGET round trip for the keys missing from the local tier)
+	if c.Engine().usesRedis() {
+		if err = c.retry(ctx, func() (getErr error) {
+			values, getErr = c.getMultiTiered(ctx, keys)
+			return getErr
+		}); err != nil {
+			return nil, err
+		}
+		return values, nil
+	} else if c.Engine() == Memcached { // Memcached (single request per server)
+		return getMultiMemcached(c.options.memcached, keys)
+	}
+
+	// FreeCache (loop each key)
+	values = make(map[string]string, len(keys))
+	for _, key := range keys {
+		var data []byte
+		if data, err = c.options.freeCache.Get([]byte(key)); err != nil {
+			if errors.Is(err, freecache.ErrNotFound) { // Missing keys are skipped
+				continue
+			}
+			return nil, err
+		}
+		values[key] = string(data)
+	}
+	return values, nil
+}
+
+// SetMulti will set several key->value pairs in a single call
+//
+// Each value expires after the default TTL (see: WithDefaultTTL), otherwise it never expires
+// NOTE: memcached does not support dependency keys
+func (c *Client) SetMulti(ctx context.Context, items map[string]string, dependencies ...string) (err error) {
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func() {
+		c.options.stats.stored(len(items), err)
+		c.observe(ctx, operationSetMulti, "", start, writeResult(err))
+		if err != nil {
+			c.onError(operationSetMulti, "", err)
+			return
+		}
+		for key := range items {
+			c.onSet(operationSetMulti, key, nil)
+		}
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize, require and prefix all keys, then compress and check the size of each value
+	sanitized := make(map[string]string, len(items))
+	for key, value := range items {
+		key, err := c.buildKey(key)
+		if err != nil {
+			return err
+		}
+		var encoded interface{}
+		if encoded, err = c.encodeValue(value); err != nil {
+			return err
+		}
+		encodedBytes := valueToBytes(encoded)
+		if err = c.checkValueSize(encodedBytes); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		sanitized[key] = string(encodedBytes)
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Use the default TTL (if set)
+	ttl := c.options.defaultTTL
+
+	// Redis (pipelined MSET, and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		for key, value := range sanitized {
+			c.setFallback(key, []byte(value), ttl)
+		}
+		if err := c.retry(ctx, func() error {
+			return setMultiRedis(ctx, c.options.redis, sanitized, ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, sortedKeys(sanitized)...)
+		for key, value := range sanitized {
+			c.setLocal(key, []byte(value), ttl)
+		}
+		return nil
+	}
+
+	// Memcached (loop each key)
+	if c.Engine() == Memcached {
+		for key, value := range sanitized {
+			if err := setMemcached(c.options.memcached, key, []byte(value), ttl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// FreeCache (loop each key, and link the dependencies)
+	for key, value := range sanitized {
+		if err := setFreeCache(
+			c.options.freeCache, c.options.freeCacheLimit, key, []byte(value), int(ttl.Seconds()),
+		); err != nil {
+			return err
+		}
+		c.linkDependencies(ctx, key, dependencies)
+	}
+	return nil
+}
+
+// Increment will atomically add the delta to the counter stored at the key and return the new value
+//
+// A missing key will start at zero
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	return c.incrementBy(ctx, incrementByCommand, key, delta)
+}
+
+// Decrement will atomically subtract the delta from the counter stored at the key and return the new value
+//
+// A missing key will start at zero
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return c.incrementBy(ctx, decrementByCommand, key, delta)
+}
+
+// SetInt will set a key->integer with a TTL, stored as the decimal string (compatible with Increment and Decrement)
+//
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the integer never expires
+// The integer is never compressed (see: WithCompression)
+func (c *Client) SetInt(ctx context.Context, key string, n int64, ttl time.Duration) error {
+	return c.setTTL(ctx, operationSetInt, key, n, ttl)
+}
+
+// GetInt will return the integer stored at the key (ie: SetInt, Increment or Decrement)
+//
+// Returns ErrKeyNotFound if the key does not exist, or ErrValueNotNumeric if the value is not an integer
+func (c *Client) GetInt(ctx context.Context, key string) (int64, error) {
+	return c.getInt(ctx, operationGetInt, key)
+}
+
+// getInt will return the integer stored at the key (operation is used for the errors, metrics and hooks)
+func (c *Client) getInt(ctx context.Context, operation, key string) (int64, error) {
+	value, found, err := c.get(ctx, operation, key)
+	if err != nil {
+		return 0, c.wrapError(operation, key, err)
+	} else if !found {
+		return 0, c.wrapError(operation, key, ErrKeyNotFound)
+	}
+
+	var n int64
+	if n, err = strconv.ParseInt(value, 10, 64); err != nil {
+		return 0, c.wrapError(operation, key, fmt.Errorf("%w: %s", ErrValueNotNumeric, err.Error()))
+	}
+	return n, nil
+}
+
+// SetDuration will set a key->duration with a TTL, stored as the integer nanoseconds (see: SetInt)
+//
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the duration never expires
+func (c *Client) SetDuration(ctx context.Context, key string, d, ttl time.Duration) error {
+	return c.setTTL(ctx, operationSetDuration, key, int64(d), ttl)
+}
+
+// GetDuration will return the duration stored at the key (see: SetDuration)
+//
+// Returns ErrKeyNotFound if the key does not exist, or ErrValueNotNumeric if the value is not an integer
+func (c *Client) GetDuration(ctx context.Context, key string) (time.Duration, error) {
+	n, err := c.getInt(ctx, operationGetDuration, key)
+	return time.Duration(n), err
+}
+
+// SetTime will set a key->time with a TTL, stored as RFC3339Nano (the time zone offset is kept, not the location name)
+//
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the time never expires
+// The monotonic clock reading is not stored, the year must be between 0 and 9999
+func (c *Client) SetTime(ctx context.Context, key string, t time.Time, ttl time.Duration) error {
+	value, err := t.MarshalText()
+	if err != nil {
+		return c.wrapError(operationSetTime, key, err)
+	}
+	return c.setTTL(ctx, operationSetTime, key, string(value), ttl)
+}
+
+// GetTime will return the time stored at the key (see: SetTime)
+//
+// Returns ErrKeyNotFound if the key does not exist, or ErrValueNotTime if the value is not a RFC3339 time
+func (c *Client) GetTime(ctx context.Context, key string) (time.Time, error) {
+	value, found, err := c.get(ctx, operationGetTime, key)
+	if err != nil {
+		return time.Time{}, c.wrapError(operationGetTime, key, err)
+	} else if !found {
+		return time.Time{}, c.wrapError(operationGetTime, key, ErrKeyNotFound)
+	}
+
+	var t time.Time
+	if t, err = time.Parse(time.RFC3339Nano, value); err != nil {
+		return time.Time{}, c.wrapError(operationGetTime, key, fmt.Errorf("%w: %s", ErrValueNotTime, err.Error()))
+	}
+	return t, nil
+}
+
+// incrementBy will run the counter command (INCRBY or DECRBY) using the current engine
+func (c *Client) incrementBy(ctx context.Context, command, key string, delta int64) (int64, error) {
+
+	// Sanitize the key, require it and add the prefix
+	key, err := c.buildKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	// Redis (the local and fallback copies are removed)
+	if c.Engine().usesRedis() {
+		c.deleteLocal(key)
+		c.deleteFallback(key)
+		return incrementRedis(ctx, c.options.redis, command, key, delta)
+	}
+
+	// Memcached counters cannot be negative or start from a missing key
+	if c.Engine() == Memcached {
+		return 0, c.engineNotSupported()
+	}
+
+	// FreeCache has no atomic counter, use a lock around the read-modify-write
+	release, err := c.lockCounter(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer release() // Always release the lock (even if the context is done)
+
+	if command == decrementByCommand {
+		delta = -delta
+	}
+	return incrementFreeCache(c.options.freeCache, key, delta, c.options.clock.Now())
+}
+
+// Append will atomically append the value to the string stored at the key and return the new length (bytes)
+//
+// A missing key is created (no expiration), the existing expiration of the key is preserved
+// The value is appended as is (no serializer or compression), read it using Get or GetBytes
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) Append(ctx context.Context, key, value string) (int, error) {
+
+	// Sanitize the key, require it and add the prefix
+	key, err := c.buildKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return 0, err
+	}
+
+	// Redis (the local and fallback copies are removed)
+	if c.Engine().usesRedis() {
+		c.deleteLocal(key)
+		c.deleteFallback(key)
+		return appendRedis(ctx, c.options.redis, key, value)
+	}
+
+	// Memcached cannot append to a missing key or return the length
+	if c.Engine() == Memcached {
+		return 0, c.engineNotSupported()
+	}
+
+	// FreeCache has no atomic append, use a lock around the read-modify-write
+	release, err := c.lockCounter(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer release() // Always release the lock (even if the context is done)
+
+	return appendFreeCache(c.options.freeCache, c.options.freeCacheLimit, key, value, c.options.clock.Now())
+}
+
+// GetSet will atomically set the key->value and return the previous value (if the key existed)
+//
+// The value expires after the default TTL (see: WithDefaultTTL), otherwise it never expires
+// A missing key returns an empty value and existed=false
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) GetSet(ctx context.Context, key, value string) (old string, existed bool, err error) {
+
+	// Sanitize the key, require it and add the prefix
+	if key, err = c.buildKey(key); err != nil {
+		return "", false, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return "", false, err
+	}
+
+	// Compress the value (if enabled) and check the size
+	encoded, err := c.encodeValue(value)
+	if err != nil {
+		return "", false, err
+	} else if err = c.checkValueSize(valueToBytes(encoded)); err != nil {
+		return "", false, err
+	}
+
+	// Use the default TTL (if set)
+	ttl := c.options.defaultTTL
+
+	// Redis (the previous value is always read from redis, and the local tier)
+	// The fallback cache is written even if redis fails
+	if c.Engine().usesRedis() {
+		c.setFallback(key, valueToBytes(encoded), ttl)
+		if old, existed, err = getSetRedis(ctx, c.options.redis, key, encoded, ttl); err != nil {
+			return "", false, err
+		}
+		c.setLocal(key, valueToBytes(encoded), ttl)
+		if !existed {
+			return "", false, nil
+		}
+		old, err = c.decodeString(old)
+		return old, true, err
+	}
+
+	// Memcached cannot swap a value atomically
+	if c.Engine() == Memcached {
+		return "", false, c.engineNotSupported()
+	}
+
+	// FreeCache (the read and write happen under the FreeCache segment lock)
+	var data []byte
+	if data, existed, err = getSetFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key, valueToBytes(encoded), int(ttl.Seconds()),
+	); err != nil || !existed {
+		return "", false, err
+	}
+	if data, err = c.decompressValue(data); err != nil {
+		return "", true, err
+	}
+	return string(data), true, nil
+}
+
+// CompareAndSwap will set the key->value only if the current value is the expected value (returns true if swapped)
+//
+// A missing key or a different value returns false without an error, nothing is written
+// The values are compared as stored (the same compression is required, see: WithCompression)
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the value never expires
+// NOTE: memcached is not supported (ErrEngineNotSupported)
+func (c *Client) CompareAndSwap(ctx context.Context, key, expected, newValue string,
+	ttl time.Duration) (swapped bool, err error) {
+
+	// Update the statistics and metrics, run the hooks (only if swapped)
+	start := time.Now()
+	defer func(key string) {
+		if swapped {
+			c.options.stats.stored(1, err)
+			c.onSet(operationCompareAndSwap, key, err)
+		}
+		c.observe(ctx, operationCompareAndSwap, key, start, replaceResult(swapped, err))
+		c.onError(operationCompareAndSwap, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	if key, err = c.buildKey(key); err != nil {
+		return false, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Compress the values (if enabled) and check the size
+	encodedExpected, err := c.encodeValue(expected)
+	if err != nil {
+		return false, err
+	}
+	encoded, err := c.encodeValue(newValue)
+	if err != nil {
+		return false, err
+	} else if err = c.checkValueSize(valueToBytes(encoded)); err != nil {
+		return false, err
+	}
+
+	// A zero TTL uses the default TTL (if set)
+	ttl = c.ttlOrDefault(ttl)
+
+	// Redis (and the local tier, a failed swap removes any local copy, the fallback copy is only kept after a swap)
+	// Not retried, the swap may have happened before the connection failed
+	if c.Engine().usesRedis() {
+		c.deleteFallback(key)
+		if swapped, err = compareAndSwapRedis(ctx, c.options.redis, key, encodedExpected, encoded, ttl); err != nil {
+			return false, err
+		} else if !swapped {
+			c.deleteLocal(key)
+			return false, nil
+		}
+		c.setFallback(key, valueToBytes(encoded), ttl)
+		c.setLocal(key, valueToBytes(encoded), ttl)
+		return true, nil
+	}
+
+	// Memcached cannot compare a value atomically
+	if c.Engine() == Memcached {
+		return false, c.engineNotSupported()
+	}
+
+	// FreeCache (the compare and write happen under the FreeCache segment lock)
+	return compareAndSwapFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key,
+		valueToBytes(encodedExpected), valueToBytes(encoded), int(ttl.Seconds()),
+	)
+}
+
+// SetModel will set any model or struct (parsing Model->Serializer (bytes))
+//
+// Model needs to be a pointer to a struct, or a slice or map (ie: query results, a pointer to a slice or map also works)
+// A nil slice or map is read back as nil, an empty slice or map as empty (the order of a slice is kept)
+// A model implementing encoding.BinaryMarshaler is stored using MarshalBinary (not the serializer, ie: time.Time)
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the model never expires
+// NOTE: memcached does not support dependency keys
+func (c *Client) SetModel(ctx context.Context, key string, model interface{},
+	ttl time.Duration, dependencies ...string) (err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationSetModel).End()
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationSetModel, key, err)
+	}(key)
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.stored(1, err)
+		c.observe(ctx, operationSetModel, key, start, writeResult(err))
+		c.onSet(operationSetModel, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Parse using the serializer (JSON by default)
+	responseBytes, err := c.marshalModel(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	// A zero TTL uses the default TTL (if set), then add the jitter (if enabled)
+	return c.setModelBytes(ctx, key, responseBytes, c.jitterTTL(c.ttlOrDefault(ttl)), dependencies)
+}
+
+// SetModelIfChanged will set any model or struct only if the serialized model differs from the stored value
+//
+// Returns true if the model was written, an unchanged model is not written but the expiration is
+// refreshed if a ttl is set (a zero TTL uses the default TTL, see: WithDefaultTTL)
+// The stored value is always read from the engine (the local tier can be stale), the read and write are not atomic
+// CAUTION: the serializer must be deterministic for equal models to compare equal, JSON is (struct fields in order,
+// sorted map keys) but a serializer with a random field order will always write
+// NOTE: memcached does not support dependency keys
+func (c *Client) SetModelIfChanged(ctx context.Context, key string, model interface{},
+	ttl time.Duration, dependencies ...string) (changed bool, err error) {
+
+	// Update the statistics and metrics, run the hooks (an unchanged model is not a set)
+	start := time.Now()
+	defer func(key string) {
+		if changed || err != nil {
+			c.options.stats.stored(1, err)
+			c.onSet(operationSetModelIfChanged, key, err)
+		}
+		c.observe(ctx, operationSetModelIfChanged, key, start, writeResult(err))
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Parse using the serializer (JSON by default)
+	responseBytes, err := c.marshalModel(ctx, model)
+	if err != nil {
+		return false, err
+	}
+
+	// A zero TTL uses the default TTL (if set)
+	ttl = c.ttlOrDefault(ttl)
+
+	// Compare with the stored value (only refresh the expiration if unchanged)
+	current, err := c.getStoredBytes(ctx, key)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return false, err
+	} else if err == nil && bytes.Equal(current, responseBytes) {
+		if ttl > 0 {
+			return false, c.touch(ctx, key, ttl)
+		}
+		return false, nil
+	}
+
+	if err = c.setModelBytes(ctx, key, responseBytes, ttl, dependencies); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setModelBytes will compress (if enabled) and store the serialized model using the current engine
+func (c *Client) setModelBytes(ctx context.Context, key string, responseBytes []byte,
+	ttl time.Duration, dependencies []string) (err error) {
+
+	// Compress (if enabled) and check the size
+	if responseBytes, err = c.compressValue(responseBytes); err != nil {
+		return err
+	} else if err = c.checkValueSize(responseBytes); err != nil {
+		return err
+	}
+
+	// Redis (and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		c.setFallback(key, responseBytes, ttl)
+		if err = c.retry(ctx, func() error {
+			return setWithTTLRedis(ctx, c.options.redis, key, string(responseBytes), ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, key)
+		c.setLocal(key, responseBytes, ttl)
+		return nil
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		return setMemcached(c.options.memcached, key, responseBytes, ttl)
+	}
+
+	// FreeCache (store the bytes, and link the dependencies)
+	if err = setFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key, responseBytes, int(ttl.Seconds()),
+	); err != nil {
+		return err
+	}
+	c.linkDependencies(ctx, key, dependencies)
+	return nil
+}
+
+// SetModelMulti will set several models or structs in a single call (parsing Model->Serializer (bytes))
+//
+// All models are serialized first, if any fails nothing is written (ErrModelMarshal with the key)
+// Redis uses a single pipeline for all the models, the other engines loop each key
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the models never expire
+// NOTE: memcached does not support dependency keys
+func (c *Client) SetModelMulti(ctx context.Context, items map[string]interface{},
+	ttl time.Duration, dependencies ...string) (err error) {
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func() {
+		c.options.stats.stored(len(items), err)
+		c.observe(ctx, operationSetModelMulti, "", start, writeResult(err))
+		if err != nil {
+			c.onError(operationSetModelMulti, "", err)
+			return
+		}
+		for key := range items {
+			c.onSet(operationSetModelMulti, key, nil)
+		}
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize, require and prefix all keys, then serialize, compress and check the size of each model
+	serialized := make(map[string]string, len(items))
+	for key, model := range items {
+		var prefixed string
+		if prefixed, err = c.buildKey(key); err != nil {
+			return err
+		}
+		var responseBytes []byte
+		if responseBytes, err = c.marshalModel(ctx, model); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrModelMarshal, key, err)
+		}
+		if responseBytes, err = c.compressValue(responseBytes); err != nil {
+			return err
+		} else if err = c.checkValueSize(responseBytes); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		serialized[prefixed] = string(responseBytes)
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// A zero TTL uses the default TTL (if set)
+	ttl = c.ttlOrDefault(ttl)
+
+	// Redis (pipelined MSET, and the local tier, the fallback cache is written even if redis fails)
+	if c.Engine().usesRedis() {
+		for key, value := range serialized {
+			c.setFallback(key, []byte(value), ttl)
+		}
+		if err = c.retry(ctx, func() error {
+			return setMultiRedis(ctx, c.options.redis, serialized, ttl, c.prefixKeys(dependencies)...)
+		}); err != nil {
+			return err
+		}
+		c.trimDependencies(ctx, dependencies, sortedKeys(serialized)...)
+		for key, value := range serialized {
+			c.setLocal(key, []byte(value), ttl)
+		}
+		return nil
+	}
+
+	// Memcached (loop each key)
+	if c.Engine() == Memcached {
+		for key, value := range serialized {
+			if err = setMemcached(c.options.memcached, key, []byte(value), ttl); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// FreeCache (loop each key, and link the dependencies)
+	for key, value := range serialized {
+		if err = setFreeCache(
+			c.options.freeCache, c.options.freeCacheLimit, key, []byte(value), int(ttl.Seconds()),
+		); err != nil {
+			return err
+		}
+		c.linkDependencies(ctx, key, dependencies)
+	}
+	return nil
+}
+
+// ReplaceModel will set any model or struct only if the key already exists (returns true if the key was overwritten)
+//
+// A missing key (expired or invalidated) returns false without an error, nothing is written
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the model never expires
+// NOTE: memcached does not support dependency keys
+func (c *Client) ReplaceModel(ctx context.Context, key string, model interface{},
+	ttl time.Duration, dependencies ...string) (replaced bool, err error) {
+
+	// Update the statistics and metrics, run the hooks (only if replaced)
+	start := time.Now()
+	defer func(key string) {
+		if replaced {
+			c.options.stats.stored(1, err)
+			c.onSet(operationReplaceModel, key, err)
+		}
+		c.observe(ctx, operationReplaceModel, key, start, replaceResult(replaced, err))
+		c.onError(operationReplaceModel, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return false, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Parse using the serializer (JSON by default), compress (if enabled) and check the size
+	responseBytes, err := c.marshalModel(ctx, model)
+	if err != nil {
+		return false, err
+	}
+	if responseBytes, err = c.compressValue(responseBytes); err != nil {
+		return false, err
+	} else if err = c.checkValueSize(responseBytes); err != nil {
+		return false, err
+	}
+
+	// A zero TTL uses the default TTL (if set)
+	ttl = c.ttlOrDefault(ttl)
+
+	// Redis (and the local tier, a missing key removes any local copy, the fallback copy is only kept after a replace)
+	if c.Engine().usesRedis() {
+		c.deleteFallback(key)
+		if err = c.retry(ctx, func() (replaceErr error) {
+			replaced, replaceErr = replaceRedis(
+				ctx, c.options.redis, key, string(responseBytes), ttl, c.prefixKeys(dependencies)...,
+			)
+			return replaceErr
+		}); err != nil {
+			return false, err
+		} else if !replaced {
+			c.deleteLocal(key)
+			return false, nil
+		}
+		c.trimDependencies(ctx, dependencies, key)
+		c.setFallback(key, responseBytes, ttl)
+		c.setLocal(key, responseBytes, ttl)
+		return true, nil
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		return replaceMemcached(c.options.memcached, key, responseBytes, ttl)
+	}
+
+	// FreeCache (store the bytes, and link the dependencies)
+	if replaced, err = replaceFreeCache(
+		c.options.freeCache, c.options.freeCacheLimit, key, responseBytes, int(ttl.Seconds()),
+	); err != nil || !replaced {
+		return false, err
+	}
+	c.linkDependencies(ctx, key, dependencies)
+	return true, nil
+}
+
+// GetModel will get a model (parsing Serializer (bytes) -> Model)
+//
+// Model needs to be a pointer to a struct, or a pointer to a slice or map (the existing entries are replaced)
+// A model implementing encoding.BinaryUnmarshaler reads the values stored using MarshalBinary (see: SetModel)
+// A missing key under the prefix of a model loader is loaded and stored (read-through, see: WithModelLoader)
+// Returns ErrKeyNotFound if the key does not exist, or ErrModelUnmarshal if the value cannot be decoded
+func (c *Client) GetModel(ctx context.Context, key string, model interface{}) error {
+	err := c.getModel(ctx, operationGetModel, key, model)
+	if errors.Is(err, ErrKeyNotFound) {
+		err = c.readThroughModel(ctx, key, model, err)
+	}
+	return c.wrapError(operationGetModel, key, err)
+}
+
+// GetModelWithTTL will get a model (parsing Serializer (bytes) -> Model) and the remaining ttl in a single call
+//
+// A ttl of zero is no expiration, memcached cannot read the expiration (ErrEngineNotSupported)
+// Returns ErrKeyNotFound if the key does not exist, or ErrModelUnmarshal if the value cannot be decoded
+func (c *Client) GetModelWithTTL(ctx context.Context, key string, model interface{}) (ttl time.Duration, err error) {
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.readModel(err)
+		c.observe(ctx, operationGetModelWithTTL, key, start, modelResult(err))
+		c.onReadModel(operationGetModelWithTTL, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Get the serialized model and decode it
+	var b []byte
+	if b, ttl, err = c.getModelBytesWithTTL(ctx, key); err != nil {
+		return 0, err
+	} else if err = c.unmarshalModel(ctx, b, model); err != nil {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// GetModelMulti will get several models (parsing Serializer (bytes) -> Model) in a single call
+//
+// Out needs to be a pointer to a slice, or a map (or a pointer to a map) with string keys, of models or pointers to models
+// The slice is replaced by the models found (in the order of the keys), the map is keyed by the requested key
+// Found are the keys found (in the order of the keys), the missing keys are not an error (partial hit)
+// Redis uses a single MGET round trip (the keys missing from the local tier), the other engines loop each key
+// A stored version that does not match is a missing key (see: WithModelVersioning)
+// Returns ErrInvalidModelOutput if out is not supported, or ErrModelUnmarshal (with the key) if a value cannot be decoded
+// NOTE: the model loaders are not used (see: WithModelLoader)
+func (c *Client) GetModelMulti(ctx context.Context, keys []string, out interface{}) (found []string, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationGetModelMulti).End()
+
+	// Add the operation and engine to the error
+	defer func() {
+		err = c.wrapError(operationGetModelMulti, "", err)
+	}()
+
+	// Update the metrics, run the hooks (the hits and misses are counted below)
+	start := time.Now()
+	defer func() {
+		c.observe(ctx, operationGetModelMulti, "", start, writeResult(err))
+		c.onError(operationGetModelMulti, "", err)
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Check the output (slice or map of models)
+	output := reflect.ValueOf(out)
+	if output.Kind() == reflect.Pointer && !output.IsNil() && output.Elem().Kind() == reflect.Map {
+		if output.Elem().IsNil() {
+			output.Elem().Set(reflect.MakeMap(output.Elem().Type()))
+		}
+		output = output.Elem()
+	}
+	if !isModelMultiOutput(output) {
+		return nil, fmt.Errorf("%w: %T", ErrInvalidModelOutput, out)
+	}
+
+	// Sanitize, require and prefix all keys (the requested keys are kept for the results, see: WithKeyHashing)
+	requested := keys
+	if keys, err = c.buildKeys(keys); err != nil {
+		return nil, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Read the values (the missing keys are absent)
+	var values map[string]string
+	if values, err = c.readMulti(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	// Decode the models found (the slice is replaced)
+	var models reflect.Value
+	if output.Kind() == reflect.Pointer {
+		models = reflect.MakeSlice(output.Elem().Type(), 0, len(values))
+	}
+	found = make([]string, 0, len(values))
+	for i, key := range keys {
+		var model reflect.Value
+		if model, err = c.decodeModelMulti(ctx, values[key], output); errors.Is(err, ErrKeyNotFound) {
+			err = nil
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.trimKey(requested[i]), err)
+		}
+		found = append(found, c.trimKey(requested[i]))
+		if output.Kind() == reflect.Map {
+			output.SetMapIndex(reflect.ValueOf(c.trimKey(requested[i])).Convert(output.Type().Key()), model)
+		} else {
+			models = reflect.Append(models, model)
+		}
+	}
+	if output.Kind() == reflect.Pointer {
+		output.Elem().Set(models)
+	}
+
+	// Count the keys found and missing (and run the hooks)
+	c.options.stats.hits.Add(int64(len(found)))
+	c.options.stats.misses.Add(int64(len(keys) - len(found)))
+	for i := range keys {
+		key := c.trimKey(requested[i])
+		c.onRead(operationGetModelMulti, key, slices.Contains(found, key), nil)
+	}
+	return found, nil
+}
+
+// isModelMultiOutput will return true if the output is a pointer to a slice, or a map with string keys (see: GetModelMulti)
+func isModelMultiOutput(output reflect.Value) bool {
+	if output.Kind() == reflect.Pointer && !output.IsNil() {
+		return output.Elem().Kind() == reflect.Slice
+	}
+	return output.Kind() == reflect.Map && !output.IsNil() && output.Type().Key().Kind() == reflect.String
+}
+
+// decodeModelMulti will decode the stored value into a new element of the output (see: GetModelMulti)
+//
+// Returns ErrKeyNotFound if the value is empty (missing key) or the stored version does not match
+func (c *Client) decodeModelMulti(ctx context.Context, value string, output reflect.Value) (reflect.Value, error) {
+	if len(value) == 0 {
+		return reflect.Value{}, ErrKeyNotFound
+	}
+	data, err := c.decompressValue([]byte(value))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	// A new model (the element is a model, or a pointer to a model)
+	elemType := output.Type().Elem()
+	if output.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	model := reflect.New(elemType)
+	if elemType.Kind() == reflect.Pointer {
+		model.Elem().Set(reflect.New(elemType.Elem()))
+		model = model.Elem()
+	}
+	if err = c.unmarshalModel(ctx, data, model.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	if elemType.Kind() == reflect.Pointer {
+		return model, nil
+	}
+	return model.Elem(), nil
+}
+
+// getModel will get a model from a given key (operation is used for the metrics and hooks)
+func (c *Client) getModel(ctx context.Context, operation, key string, model interface{}) (err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operation).End()
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	defer func(key string) {
+		c.options.stats.readModel(err)
+		c.observe(ctx, operation, key, start, modelResult(err))
+		c.onReadModel(operation, key, err)
+	}(key)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Get the serialized model and decode it
+	var b []byte
+	if b, err = c.getModelBytes(ctx, key); err != nil {
+		return err
+	}
+	return c.unmarshalModel(ctx, b, model)
+}
+
+// getModelBytes will get the serialized model (decompressed) from a given key
+//
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) getModelBytes(ctx context.Context, key string) (b []byte, err error) {
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Read the model (shared by the concurrent reads of the key, see: WithSingleFlight)
+	shared, err := c.shareRead(ctx, sharedReadModel+key, func(ctx context.Context) (interface{}, error) {
+		return c.readModelBytes(ctx, key)
+	})
+	b, _ = shared.([]byte)
+	return b, err
+}
+
+// readModelBytes will read the serialized model (decompressed) of a key (already sanitized and prefixed)
+//
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) readModelBytes(ctx context.Context, key string) (b []byte, err error) {
+
+	// Redis
+	if c.Engine().usesRedis() {
+
+		// Get the record as bytes (check the local tier first)
+		var ok bool
+		if b, ok = c.getLocal(key); !ok {
+			if err = c.retry(ctx, func() (getErr error) {
+				b, getErr = cache.GetBytes(ctx, c.readRedis(), key)
+				return getErr
+			}); err != nil {
+				if errors.Is(err, redis.ErrNil) {
+					return nil, ErrKeyNotFound
+				}
+				var found, ok bool
+				if b, found, ok = c.readFallback(key, err); !ok {
+					return nil, err
+				} else if !found || len(b) == 0 {
+					return nil, ErrKeyNotFound
+				}
+				return c.decompressValue(b)
+			}
+
+			// Sanity check to make sure there is a value to unmarshal
+			if len(b) == 0 {
+				return nil, ErrKeyNotFound
+			}
+			c.setLocal(key, b, 0)
+		}
+		return c.decompressValue(b)
+	} else if c.Engine() == Memcached {
+		if b, err = getMemcached(c.options.memcached, key); err != nil {
+			return nil, err
+		} else if len(b) == 0 {
+			return nil, ErrKeyNotFound
+		}
+		return c.decompressValue(b)
+	} else if c.Engine() == FreeCache {
+		if b, err = c.options.freeCache.Get([]byte(key)); err == nil && len(b) > 0 {
+			return c.decompressValue(b)
+		}
+	}
+
+	// Not found
+	return nil, ErrKeyNotFound
+}
+
+// getStoredBytes will get the stored value (decompressed) of a key (already sanitized and prefixed)
+//
+// Redis is always read (not the local tier), the FreeCache statistics are not updated
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) getStoredBytes(ctx context.Context, key string) (b []byte, err error) {
+	if c.Engine().usesRedis() {
+		if err = c.retry(ctx, func() (getErr error) {
+			b, getErr = cache.GetBytes(ctx, c.options.redis, key)
+			return getErr
+		}); errors.Is(err, redis.ErrNil) {
+			return nil, ErrKeyNotFound
+		}
+	} else if c.Engine() == Memcached {
+		b, err = getMemcached(c.options.memcached, key)
+	} else if b, err = c.options.freeCache.Peek([]byte(key)); errors.Is(err, freecache.ErrNotFound) {
+		return nil, ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.decompressValue(b)
+}
+
+// getModelBytesWithTTL will get the serialized model (decompressed) and the remaining ttl from a given key
+//
+// Returns ErrKeyNotFound if the key does not exist
+func (c *Client) getModelBytesWithTTL(ctx context.Context, key string) (b []byte, ttl time.Duration, err error) {
+
+	// Sanitize the key, require it and add the prefix
+	key, err = c.buildKey(key)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, 0, err
+	}
+
+	// Redis (the local tier does not know the remaining ttl, always read from Redis)
+	if c.Engine().usesRedis() {
+		if err = c.retry(ctx, func() (getErr error) {
+			b, ttl, getErr = getWithTTLRedis(ctx, c.readRedis(), key)
+			return getErr
+		}); err != nil {
+			return nil, 0, err
+		}
+		c.setLocal(key, b, ttl)
+	} else if c.Engine() == Memcached {
+		return nil, 0, c.engineNotSupported()
+	} else if c.Engine() == FreeCache {
+		var expireAt uint32
+		if b, expireAt, err = c.options.freeCache.GetWithExpiration([]byte(key)); err != nil || len(b) == 0 {
+			return nil, 0, ErrKeyNotFound
+		}
+		ttl = time.Duration(remainingFreeCacheTTL(c.options.clock.Now(), expireAt)) * time.Second
+	} else {
+		return nil, 0, ErrKeyNotFound
+	}
+
+	// Decompress the value
+	if b, err = c.decompressValue(b); err != nil {
+		return nil, 0, err
+	}
+	return b, ttl, nil
+}
+
+// ttlOrDefault will return the ttl, or the default TTL if the ttl is zero (see: WithDefaultTTL)
+func (c *Client) ttlOrDefault(ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		return c.options.defaultTTL
+	}
+	return ttl
+}
+
+// jitterTTL will add a random offset (0 to the max jitter) to the ttl (see: WithTTLJitter)
+//
+// The ttl is never reduced, no expiration (zero) is not changed
+func (c *Client) jitterTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || c.options.ttlJitter <= 0 {
+		return ttl
+	}
+	return ttl + rand.N(c.options.ttlJitter+1) //nolint:gosec // jitter does not need a secure random number
+}
+
+// buildKey will require an open client (see: checkClosed), sanitize the key (trailing or leading spaces, see: WithDisableKeyTrimming), require it to be present, validate it, hash it (if enabled) and add the key prefix
+func (c *Client) buildKey(key string) (string, error) {
+	if err := c.checkClosed(); err != nil {
+		return "", err
+	} else if key = c.trimKey(key); len(key) == 0 {
+		return "", ErrKeyRequired
+	} else if err := c.validateKey(key); err != nil {
+		return "", err
+	} else if key = c.hashKey(key); len(key) == 0 {
+		return "", ErrKeyRequired
+	} else if err := c.checkKeyLength(key); err != nil {
+		return "", err
+	}
+	return c.options.keyPrefix + key, nil
+}
+
+// trimKey will remove the leading and trailing spaces of the key (unless disabled, see: WithDisableKeyTrimming)
+func (c *Client) trimKey(key string) string {
+	if c.options.disableKeyTrimming {
+		return key
+	}
+	return strings.TrimSpace(key)
+}
+
+// validateKey will run the key validator (if set, see: WithKeyValidator), the error is wrapped with ErrInvalidKey
+func (c *Client) validateKey(key string) error {
+	if c.options.keyValidator == nil {
+		return nil
+	} else if err := c.options.keyValidator(key); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	return nil
+}
+
+// checkKeyLength will return ErrKeyTooLong if the key (hashed, without the prefix) exceeds the max length (see: WithMaxKeyLength)
+func (c *Client) checkKeyLength(key string) error {
+	if c.options.maxKeyLength > 0 && len(key) > c.options.maxKeyLength {
+		return fmt.Errorf("%w: key is %d bytes, the limit is %d bytes", ErrKeyTooLong, len(key), c.options.maxKeyLength)
+	}
+	return nil
+}
+
+// checkValueSize will return ErrValueTooLarge if the stored value exceeds the max size (see: WithMaxValueSize)
+func (c *Client) checkValueSize(value []byte) error {
+	if c.options.maxValueSize > 0 && len(value) > c.options.maxValueSize {
+		return fmt.Errorf("%w: value is %d bytes, the limit is %d bytes", ErrValueTooLarge, len(value), c.options.maxValueSize)
+	}
+	return nil
+}
+
+// buildKeys will build all the keys (see: buildKey)
+func (c *Client) buildKeys(keys []string) ([]string, error) {
+	built := make([]string, 0, len(keys))
+	for _, key := range keys {
+		key, err := c.buildKey(key)
+		if err != nil {
+			return nil, err
+		}
+		built = append(built, key)
+	}
+	return built, nil
+}
+
+// prefixKeys will add the key prefix to all the keys (used for dependencies)
+func (c *Client) prefixKeys(keys []string) []string {
+	if len(c.options.keyPrefix) == 0 || len(keys) == 0 {
+		return keys
+	}
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.options.keyPrefix + key
+	}
+	return prefixed
+}
+
+// stripKey will remove the key prefix from a key
+func (c *Client) stripKey(key string) string {
+	return strings.TrimPrefix(key, c.options.keyPrefix)
 }
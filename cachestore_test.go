@@ -3,10 +3,19 @@ package cachestore
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 	"github.com/rafaeljusto/redigomock"
 	"github.com/stretchr/testify/assert"
@@ -15,9 +24,11 @@ import (
 
 const (
 	testAppName              = "test-app"
+	testDependantKey         = "test-dependency"
 	testIdleTimeout          = 240 * time.Second
 	testKey                  = "test-key"
 	testLocalConnectionURL   = RedisPrefix + "localhost:" + DefaultRedisPort
+	testMasterName           = "test-master"
 	testMaxActiveConnections = 0
 	testMaxConnLifetime      = 60 * time.Second
 	testMaxIdleConnections   = 10
@@ -34,14 +45,14 @@ type genericStruct struct {
 }
 
 // newMockRedisClient will create a new redis mock client
-func newMockRedisClient(t *testing.T) (ClientInterface, *redigomock.Conn) {
+func newMockRedisClient(t *testing.T, opts ...ClientOps) (ClientInterface, *redigomock.Conn) {
 	redisClient, conn := loadMockRedis(
 		testIdleTimeout, testMaxConnLifetime, testMaxActiveConnections, testMaxIdleConnections,
 	)
 	require.NotNil(t, redisClient)
 	require.NotNil(t, conn)
 
-	c, err := NewClient(context.Background(), WithRedisConnection(redisClient))
+	c, err := NewClient(context.Background(), append([]ClientOps{WithRedisConnection(redisClient)}, opts...)...)
 	require.NotNil(t, c)
 	require.NoError(t, err)
 	return c, conn
@@ -49,6 +60,7 @@ func newMockRedisClient(t *testing.T) (ClientInterface, *redigomock.Conn) {
 
 // cacheTestCase is the test case struct
 type cacheTestCase struct {
+	clock  *testClock
 	engine Engine
 	name   string
 	opts   ClientOps
@@ -59,9 +71,20 @@ type cacheTestCase struct {
 func (c cacheTestCase) FastForward(duration time.Duration) {
 	if c.engine == Redis && c.redis != nil {
 		c.redis.FastForward(duration)
+	} else if c.engine == FreeCache && c.clock != nil {
+		c.clock.Advance(duration)
 	}
 }
 
+// TTL will return the remaining ttl of a key for the engine
+func (c cacheTestCase) TTL(client ClientInterface, key string) time.Duration {
+	if c.engine == Redis && c.redis != nil {
+		return c.redis.TTL(key)
+	}
+	ttl, _ := client.FreeCache().TTL([]byte(key))
+	return time.Duration(ttl) * time.Second
+}
+
 // TestClient_SetRedis will test the method Set() and Get()
 func TestClient_SetRedis(t *testing.T) {
 	t.Run("["+Redis.String()+"] [mocked] - valid get/set using redis", func(t *testing.T) {
@@ -255,6 +278,286 @@ func TestClient_Get(t *testing.T) {
 	})
 }
 
+// TestClient_StrictMisses will test the option WithStrictMisses() (a miss vs a stored empty value)
+func TestClient_StrictMisses(t *testing.T) {
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - default, a miss is an empty value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, ""))
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "", value)
+
+			value, err = c.Get(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			assert.Equal(t, "", value)
+		})
+
+		t.Run(testCase.name+" - strict, a miss returns ErrKeyNotFound", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithStrictMisses())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, ""))
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "", value)
+
+			value, err = c.Get(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Equal(t, "", value)
+
+			// The miss is still counted as a miss
+			stats := c.Stats()
+			assert.Equal(t, int64(1), stats.Hits)
+			assert.Equal(t, int64(1), stats.Misses)
+		})
+
+		t.Run(testCase.name+" - strict, an expired key returns ErrKeyNotFound", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithStrictMisses())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.SetTTL(context.Background(), testKey, testValue, 2*time.Second))
+			testCase.FastForward(3 * time.Second)
+
+			_, err = c.Get(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+}
+
+// TestClient_SetBytes will test the methods SetBytes() and GetBytes()
+func TestClient_SetBytes(t *testing.T) {
+
+	// Binary payload with embedded null bytes (and invalid UTF-8)
+	binaryValue := []byte{0x00, 0x01, 0x00, 0xfe, 0xff, 'v', 'a', 'l', 0x00}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetBytes(context.Background(), "", binaryValue)
+			require.ErrorIs(t, err, ErrKeyRequired)
+
+			_, err = c.GetBytes(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			var value []byte
+			value, err = c.GetBytes(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Nil(t, value)
+		})
+
+		t.Run(testCase.name+" - the errors include the operation and key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxValueSize(4))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.SetBytes(context.Background(), testKey, binaryValue)
+			require.ErrorIs(t, err, ErrValueTooLarge)
+			assert.Contains(t, err.Error(), `cachestore: set_bytes key="test-key"`)
+
+			_, err = c.GetBytes(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Contains(t, err.Error(), `cachestore: get_bytes key="test-key-missing"`)
+		})
+
+		t.Run(testCase.name+" - binary round trip", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.SetBytes(context.Background(), testKey, binaryValue, testDependantKey)
+			require.NoError(t, err)
+
+			var value []byte
+			value, err = c.GetBytes(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, binaryValue, value)
+		})
+
+		t.Run(testCase.name+" - binary round trip (compressed)", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithCompression(CompressionSnappy), WithCompressionThreshold(0),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.SetBytes(context.Background(), testKey, binaryValue)
+			require.NoError(t, err)
+
+			var value []byte
+			value, err = c.GetBytes(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, binaryValue, value)
+		})
+
+		for _, algo := range []CompressionType{CompressionGzip, CompressionSnappy} {
+			t.Run(testCase.name+" ["+algo.String()+"] - values starting with a header byte", func(t *testing.T) {
+				c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithCompression(algo))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+				defer c.Close(context.Background())
+
+				for _, header := range []byte{
+					compressionHeaderGzip, compressionHeaderSnappy, compressionHeaderNone,
+					binaryMarker, versionMarker, msgpackMarker,
+				} {
+					payload := []byte{header, 0x01, 0x02, 0x00}
+					require.NoError(t, c.SetBytes(context.Background(), testKey, payload))
+
+					var value []byte
+					value, err = c.GetBytes(context.Background(), testKey)
+					require.NoError(t, err, "header %x", header)
+					assert.Equal(t, payload, value)
+
+					// A []byte value of Set is binary as well
+					require.NoError(t, c.Set(context.Background(), testKey, payload))
+					value, err = c.GetBytes(context.Background(), testKey)
+					require.NoError(t, err, "header %x", header)
+					assert.Equal(t, payload, value)
+				}
+			})
+		}
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - raw bytes are sent", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.Command(cache.SetCommand, testKey, binaryValue).Expect("OK")
+		err := c.SetBytes(context.Background(), testKey, binaryValue)
+		require.NoError(t, err)
+		assert.True(t, setCmd.Called)
+
+		getCmd := conn.Command(cache.GetCommand, testKey).Expect(binaryValue)
+		var value []byte
+		value, err = c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, binaryValue, value)
+		assert.True(t, getCmd.Called)
+	})
+
+	t.Run("["+Tiered.String()+"] - local tier is populated", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		require.NoError(t, r.Set(testKey, string(binaryValue)))
+
+		value, err := c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, binaryValue, value)
+
+		var data []byte
+		data, err = local.Get([]byte(testKey))
+		require.NoError(t, err)
+		assert.Equal(t, binaryValue, data)
+	})
+}
+
+// TestClient_GetRaw will test the method GetRaw()
+func TestClient_GetRaw(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - valid json", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			model := &genericStruct{StringField: testValue, IntField: 42}
+			require.NoError(t, c.SetModel(context.Background(), testKey, model, 0))
+
+			var raw json.RawMessage
+			raw, err = c.GetRaw(context.Background(), testKey)
+			require.NoError(t, err)
+			expected, err := json.Marshal(model)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(raw))
+
+			// A JSON string set as is
+			require.NoError(t, c.Set(context.Background(), testKey+"-list", `[1,2,3]`))
+			raw, err = c.GetRaw(context.Background(), testKey+"-list")
+			require.NoError(t, err)
+			assert.Equal(t, json.RawMessage(`[1,2,3]`), raw)
+		})
+
+		t.Run(testCase.name+" - invalid json", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, `{"broken":`))
+			var raw json.RawMessage
+			raw, err = c.GetRaw(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrNotJSON)
+			assert.Contains(t, err.Error(), "get_raw")
+			assert.Nil(t, raw)
+		})
+
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			var raw json.RawMessage
+			raw, err = c.GetRaw(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Nil(t, raw)
+			assert.Equal(t, int64(1), c.Stats().Misses)
+
+			_, err = c.GetRaw(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - msgpack is not json", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithMsgpack())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+			_, err = c.GetRaw(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrNotJSON)
+		})
+
+		t.Run(testCase.name+" - the version header is removed", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithModelVersioning())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			ctx := ContextWithModelVersion(context.Background(), 2)
+			require.NoError(t, c.SetModel(ctx, testKey, &genericStruct{StringField: testValue}, 0))
+			var raw json.RawMessage
+			raw, err = c.GetRaw(ctx, testKey)
+			require.NoError(t, err)
+			assert.True(t, json.Valid(raw))
+
+			_, err = c.GetRaw(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrModelVersionMismatch)
+		})
+	}
+}
+
 // TestClient_Set will test the method Set()
 func TestClient_Set(t *testing.T) {
 
@@ -312,6 +615,39 @@ func TestClient_Set(t *testing.T) {
 		})
 	}
 
+	t.Run("["+FreeCache.String()+"] - value too large", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithFreeCacheSize(MinFreeCacheSize))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		largeValue := strings.Repeat("x", 2048)
+		err = c.Set(context.Background(), testKey, largeValue)
+		require.ErrorIs(t, err, ErrValueTooLarge)
+		require.ErrorIs(t, err, freecache.ErrLargeEntry)
+		assert.Contains(t, err.Error(), "value is 2048 bytes, the limit is 480 bytes")
+
+		err = c.SetTTL(context.Background(), testKey, largeValue, time.Minute)
+		require.ErrorIs(t, err, ErrValueTooLarge)
+		err = c.SetModel(context.Background(), testKey, &genericStruct{StringField: largeValue}, 0)
+		require.ErrorIs(t, err, ErrValueTooLarge)
+
+		// The value was not stored
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("["+FreeCache.String()+"] - value too large (existing connection)", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCacheConnection(freecache.NewCache(MinFreeCacheSize)))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		err = c.Set(context.Background(), testKey, strings.Repeat("x", 2048))
+		require.ErrorIs(t, err, ErrValueTooLarge)
+		assert.Contains(t, err.Error(), "1/1024 of the freecache size")
+	})
+
 	t.Run("["+Redis.String()+"] [mock] - empty key", func(t *testing.T) {
 		c, _ := newMockRedisClient(t)
 
@@ -391,6 +727,27 @@ func TestClient_SetTTL(t *testing.T) {
 			assert.Equal(t, testValue, val.(string))
 		})
 
+		t.Run(testCase.name+" - zero ttl never expires", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetTTL(context.Background(), testKey, testValue, 0)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey))
+
+			testCase.FastForward(time.Hour)
+
+			var val string
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+		})
+
 		t.Run(testCase.name+" - check that key expires", func(t *testing.T) {
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
@@ -407,9 +764,6 @@ func TestClient_SetTTL(t *testing.T) {
 
 			testCase.FastForward(2 * time.Second)
 
-			// Wait enough time for the key to expire
-			time.Sleep(2 * time.Second)
-
 			// Check the key is empty
 			var val interface{}
 			val, err = c.Get(context.Background(), "test-ttl")
@@ -439,33 +793,35 @@ func TestClient_SetTTL(t *testing.T) {
 	})
 }
 
-// TestClient_Delete will test the method Delete()
-func TestClient_Delete(t *testing.T) {
+// TestClient_DefaultTTL will test the option WithDefaultTTL() for the set methods
+func TestClient_DefaultTTL(t *testing.T) {
 
 	testCases := getInMemoryTestCases(t)
 	for _, testCase := range testCases {
-		t.Run(testCase.name+" - empty key", func(t *testing.T) {
-			c, err := NewClient(context.Background(), testCase.opts)
+		t.Run(testCase.name+" - default ttl is applied", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithDefaultTTL(time.Hour))
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.Delete(context.Background(), "")
-			require.Error(t, err)
-			require.ErrorIs(t, err, ErrKeyRequired)
-		})
-
-		t.Run(testCase.name+" - just spaces", func(t *testing.T) {
-			c, err := NewClient(context.Background(), testCase.opts)
-			require.NotNil(t, c)
-			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
 
-			err = c.Delete(context.Background(), "   ")
-			require.Error(t, err)
-			require.ErrorIs(t, err, ErrKeyRequired)
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			require.NoError(t, c.SetBytes(context.Background(), testKey+"-bytes", []byte(testValue)))
+			require.NoError(t, c.SetTTL(context.Background(), testKey+"-ttl", testValue, 0))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0))
+			require.NoError(t, c.SetMulti(context.Background(), map[string]string{testKey + "-multi": testValue}))
+
+			for _, key := range []string{testKey, testKey + "-bytes", testKey + "-ttl", testKey + "-model", testKey + "-multi"} {
+				ttl := testCase.TTL(c, key)
+				assert.Greater(t, ttl, 59*time.Minute, key)
+				assert.LessOrEqual(t, ttl, time.Hour, key)
+			}
 		})
 
-		t.Run(testCase.name+" - valid key", func(t *testing.T) {
-			c, err := NewClient(context.Background(), testCase.opts)
+		t.Run(testCase.name+" - explicit ttl overrides the default", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithDefaultTTL(time.Hour))
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
@@ -473,19 +829,17 @@ func TestClient_Delete(t *testing.T) {
 				_ = c.EmptyCache(context.Background())
 			}()
 
-			err = c.Set(context.Background(), testKey, "")
-			require.NoError(t, err)
-
-			err = c.Delete(context.Background(), testKey)
-			require.NoError(t, err)
+			require.NoError(t, c.SetTTL(context.Background(), testKey+"-ttl", testValue, time.Minute))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, time.Minute))
 
-			var val string
-			val, err = c.Get(context.Background(), testKey)
-			require.NoError(t, err)
-			assert.Equal(t, "", val)
+			for _, key := range []string{testKey + "-ttl", testKey + "-model"} {
+				ttl := testCase.TTL(c, key)
+				assert.Greater(t, ttl, 50*time.Second, key)
+				assert.LessOrEqual(t, ttl, time.Minute, key)
+			}
 		})
 
-		t.Run(testCase.name+" - valid key, with leading and trailing spaces", func(t *testing.T) {
+		t.Run(testCase.name+" - no default ttl", func(t *testing.T) {
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
 			require.NoError(t, err)
@@ -494,162 +848,352 @@ func TestClient_Delete(t *testing.T) {
 				_ = c.EmptyCache(context.Background())
 			}()
 
-			err = c.Set(context.Background(), " "+testKey+" ", testValue)
-			require.NoError(t, err)
-
-			err = c.Delete(context.Background(), testKey)
-			require.NoError(t, err)
-
-			var val string
-			val, err = c.Get(context.Background(), testKey)
-			require.NoError(t, err)
-			assert.Equal(t, "", val)
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0))
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey))
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey+"-model"))
 		})
 	}
 
-	t.Run("["+Redis.String()+"] [mock] - empty key", func(t *testing.T) {
-		c, _ := newMockRedisClient(t)
-
-		err := c.Delete(context.Background(), "")
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrKeyRequired)
-	})
-
-	t.Run("["+Redis.String()+"] [mock] - valid key", func(t *testing.T) {
-		c, conn := newMockRedisClient(t)
-
-		// Mock the redis string
-		delCmd := conn.Command(cache.DeleteCommand, testKey).Expect(1)
+	t.Run("["+Redis.String()+"] [mock] - SETEX and PEXPIRE commands", func(t *testing.T) {
+		c, conn := newMockRedisClient(t, WithDefaultTTL(time.Minute))
 
-		err := c.Delete(context.Background(), testKey)
-		require.NoError(t, err)
+		setCmd := conn.Command(cache.SetExpirationCommand, testKey, int64(60), testValue).Expect(testValue)
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		assert.True(t, setCmd.Called)
 
-		assert.True(t, delCmd.Called)
+		conn.Command(multiSetCommand, testKey, testValue).Expect("OK")
+		expireCmd := conn.Command(pExpireCommand, testKey, int64(60000)).Expect(int64(1))
+		require.NoError(t, c.SetMulti(context.Background(), map[string]string{testKey: testValue}))
+		assert.True(t, expireCmd.Called)
 	})
 }
 
-// TestClient_GetModel will test the method GetModel()
-func TestClient_GetModel(t *testing.T) {
-
-	testModel := &genericStruct{
-		StringField: testValue,
-		IntField:    123,
-		BoolField:   true,
-		FloatField:  12.34,
-	}
+// TestClient_Exists will test the method Exists()
+func TestClient_Exists(t *testing.T) {
 
 	testCases := getInMemoryTestCases(t)
 	for _, testCase := range testCases {
 		t.Run(testCase.name+" - empty key", func(t *testing.T) {
-			testModelEmpty := new(genericStruct)
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.GetModel(context.Background(), "", testModelEmpty)
+			_, err = c.Exists(context.Background(), "")
 			require.Error(t, err)
 			require.ErrorIs(t, err, ErrKeyRequired)
 		})
 
 		t.Run(testCase.name+" - just spaces", func(t *testing.T) {
-			testModelEmpty := new(genericStruct)
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.GetModel(context.Background(), "   ", testModelEmpty)
+			_, err = c.Exists(context.Background(), "   ")
 			require.Error(t, err)
 			require.ErrorIs(t, err, ErrKeyRequired)
 		})
 
-		t.Run(testCase.name+" - valid key", func(t *testing.T) {
-			testModelEmpty := new(genericStruct)
+		t.Run(testCase.name+" - key not found", func(t *testing.T) {
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			defer func() {
-				_ = c.EmptyCache(context.Background())
-			}()
-
-			err = c.SetModel(context.Background(), testKey, testModel, 0)
-			require.NoError(t, err)
-
-			err = c.GetModel(context.Background(), testKey, testModelEmpty)
+			var found bool
+			found, err = c.Exists(context.Background(), testKey+"-missing")
 			require.NoError(t, err)
-			assert.Equal(t, testModel.StringField, testModelEmpty.StringField)
-			assert.Equal(t, testModel.IntField, testModelEmpty.IntField)
-			assert.Equal(t, testModel.BoolField, testModelEmpty.BoolField)
-			assert.InDelta(t, testModel.FloatField, testModelEmpty.FloatField, 0)
+			assert.False(t, found)
 		})
 
-		t.Run(testCase.name+" - record does not exist", func(t *testing.T) {
-			testModelEmpty := new(genericStruct)
+		t.Run(testCase.name+" - stored empty string", func(t *testing.T) {
 			c, err := NewClient(context.Background(), testCase.opts)
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.GetModel(context.Background(), testKey, testModelEmpty)
-			require.Error(t, err)
-			require.ErrorIs(t, err, ErrKeyNotFound)
-			assert.NotEqual(t, testModel.StringField, testModelEmpty.StringField)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.Set(context.Background(), testKey, "")
+			require.NoError(t, err)
+
+			var found bool
+			found, err = c.Exists(context.Background(), " "+testKey+" ")
+			require.NoError(t, err)
+			assert.True(t, found)
 		})
 	}
 
-	t.Run("["+Redis.String()+"] [mock] - empty key", func(t *testing.T) {
-		testModelEmpty := new(genericStruct)
-		c, _ := newMockRedisClient(t)
+	t.Run("["+Redis.String()+"] [mock] - valid key", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
 
-		err := c.GetModel(context.Background(), "", testModelEmpty)
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrKeyRequired)
+		existsCmd := conn.Command(cache.ExistsCommand, testKey).Expect(int64(1))
+
+		found, err := c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, existsCmd.Called)
+		assert.True(t, found)
 	})
+}
 
-	t.Run("["+Redis.String()+"] [mock] - record does not exist", func(t *testing.T) {
-		testModelEmpty := new(genericStruct)
-		c, conn := newMockRedisClient(t)
+// TestClient_TTLJitter will test the option WithTTLJitter() for SetTTL and SetModel
+func TestClient_TTLJitter(t *testing.T) {
 
-		getCmd := conn.Command(cache.GetCommand, testKey).Expect(nil)
+	const (
+		baseTTL   = time.Hour
+		maxJitter = 10 * time.Minute
+		keys      = 100
+	)
 
-		err := c.GetModel(context.Background(), testKey, testModelEmpty)
-		require.Error(t, err)
-		require.ErrorIs(t, err, ErrKeyNotFound)
-		assert.True(t, getCmd.Called)
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - the ttls are spread across the jitter window", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithTTLJitter(maxJitter))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			minTTL, maxTTL := baseTTL+maxJitter, time.Duration(0)
+			for i := 0; i < keys; i++ {
+				key := testKey + "-" + strconv.Itoa(i)
+				if i%2 == 0 {
+					require.NoError(t, c.SetTTL(context.Background(), key, testValue, baseTTL))
+				} else {
+					require.NoError(t, c.SetModel(context.Background(), key, &genericStruct{StringField: testValue}, baseTTL))
+				}
+
+				// Never below the requested ttl
+				ttl := testCase.TTL(c, key)
+				require.GreaterOrEqual(t, ttl, baseTTL)
+				require.LessOrEqual(t, ttl, baseTTL+maxJitter)
+				minTTL, maxTTL = min(minTTL, ttl), max(maxTTL, ttl)
+			}
+			assert.Greater(t, maxTTL-minTTL, maxJitter/2)
+		})
+
+		t.Run(testCase.name+" - no expiration is not changed", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithTTLJitter(maxJitter))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetTTL(context.Background(), testKey, testValue, 0))
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		assert.Equal(t, baseTTL, c.jitterTTL(baseTTL))
+
+		WithTTLJitter(maxJitter)(c.options)
+		assert.Equal(t, time.Duration(0), c.jitterTTL(0))
+		assert.Equal(t, -time.Second, c.jitterTTL(-time.Second))
 	})
+}
 
-	t.Run("["+Redis.String()+"] [mock] - record exists", func(t *testing.T) {
-		testModelEmpty := new(genericStruct)
-		c, conn := newMockRedisClient(t)
+// TestClient_Touch will test the method Touch()
+func TestClient_Touch(t *testing.T) {
 
-		responseBytes, err := json.Marshal(&testModel)
-		require.NoError(t, err)
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
 
-		setCmd := conn.Command(cache.SetCommand, testKey, string(responseBytes))
+			err = c.Touch(context.Background(), "   ", time.Minute)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
 
-		err = c.SetModel(context.Background(), testKey, testModel, 0)
-		require.NoError(t, err)
-		assert.True(t, setCmd.Called)
+		t.Run(testCase.name+" - key not found", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
 
-		getCmd := conn.Command(cache.GetCommand, testKey).Expect(responseBytes)
+			err = c.Touch(context.Background(), testKey+"-missing", time.Minute)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
 
-		err = c.GetModel(context.Background(), testKey, testModelEmpty)
+		t.Run(testCase.name+" - extend the ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetTTL(context.Background(), testKey, testValue, 5*time.Second)
+			require.NoError(t, err)
+
+			err = c.Touch(context.Background(), testKey, time.Hour)
+			require.NoError(t, err)
+			assert.Greater(t, testCase.TTL(c, testKey), time.Minute)
+
+			var val string
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+		})
+
+		t.Run(testCase.name+" - zero ttl persists the key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetTTL(context.Background(), testKey, testValue, time.Hour)
+			require.NoError(t, err)
+
+			err = c.Touch(context.Background(), testKey, 0)
+			require.NoError(t, err)
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey))
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - PEXPIRE command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		expireCmd := conn.Command(pExpireCommand, testKey, int64(60000)).Expect(int64(1))
+
+		err := c.Touch(context.Background(), testKey, time.Minute)
 		require.NoError(t, err)
-		assert.True(t, getCmd.Called)
+		assert.True(t, expireCmd.Called)
+	})
 
-		assert.Equal(t, testModel.StringField, testModelEmpty.StringField)
+	t.Run("["+Redis.String()+"] [mock] - key not found", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		expireCmd := conn.Command(pExpireCommand, testKey, int64(60000)).Expect(int64(0))
+
+		err := c.Touch(context.Background(), testKey, time.Minute)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		assert.True(t, expireCmd.Called)
 	})
 }
 
-// TestClient_SetModel will test the method SetModel()
-func TestClient_SetModel(t *testing.T) {
+// TestClient_GetAndRefresh will test the method GetAndRefresh()
+func TestClient_GetAndRefresh(t *testing.T) {
 
-	testModel := &genericStruct{
-		StringField: testValue,
-		IntField:    123,
-		BoolField:   true,
-		FloatField:  12.34,
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.GetAndRefresh(context.Background(), "   ", time.Minute)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - key not found (nothing is written)", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.GetAndRefresh(context.Background(), testKey+"-missing", time.Minute)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Empty(t, val)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - returns the value and extends the ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetTTL(context.Background(), testKey, testValue, 5*time.Second)
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.GetAndRefresh(context.Background(), testKey, time.Hour)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+			assert.Greater(t, testCase.TTL(c, testKey), time.Minute)
+
+			// Still there after the original ttl
+			testCase.FastForward(10 * time.Second)
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+
+			stats := c.Stats()
+			assert.Equal(t, int64(2), stats.Hits)
+		})
+
+		t.Run(testCase.name+" - zero ttl persists the key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetTTL(context.Background(), testKey, testValue, time.Hour)
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.GetAndRefresh(context.Background(), testKey, 0)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+			assert.Equal(t, time.Duration(0), testCase.TTL(c, testKey))
+		})
+
+		t.Run(testCase.name+" - compressed value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithCompression(CompressionSnappy), WithCompressionThreshold(0),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.Set(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.GetAndRefresh(context.Background(), testKey, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+		})
 	}
 
+	t.Run("["+Redis.String()+"] [tiered] - refreshes the local tier", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+		require.NoError(t, r.Set(testKey, testValue))
+
+		val, err := c.GetAndRefresh(context.Background(), testKey, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+		assert.Equal(t, time.Hour, r.TTL(testKey))
+
+		var data []byte
+		data, err = local.Get([]byte(testKey))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(data))
+	})
+}
+
+// TestClient_Delete will test the method Delete()
+func TestClient_Delete(t *testing.T) {
+
 	testCases := getInMemoryTestCases(t)
 	for _, testCase := range testCases {
 		t.Run(testCase.name+" - empty key", func(t *testing.T) {
@@ -657,7 +1201,7 @@ func TestClient_SetModel(t *testing.T) {
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.SetModel(context.Background(), "", testModel, 0)
+			err = c.Delete(context.Background(), "")
 			require.Error(t, err)
 			require.ErrorIs(t, err, ErrKeyRequired)
 		})
@@ -667,7 +1211,7 @@ func TestClient_SetModel(t *testing.T) {
 			require.NotNil(t, c)
 			require.NoError(t, err)
 
-			err = c.Set(context.Background(), "   ", testModel)
+			err = c.Delete(context.Background(), "   ")
 			require.Error(t, err)
 			require.ErrorIs(t, err, ErrKeyRequired)
 		})
@@ -681,33 +1225,2859 @@ func TestClient_SetModel(t *testing.T) {
 				_ = c.EmptyCache(context.Background())
 			}()
 
-			err = c.SetModel(context.Background(), testKey, testModel, 0)
+			err = c.Set(context.Background(), testKey, "")
+			require.NoError(t, err)
+
+			err = c.Delete(context.Background(), testKey)
 			require.NoError(t, err)
+
+			var val string
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "", val)
+		})
+
+		t.Run(testCase.name+" - valid key, with leading and trailing spaces", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.Set(context.Background(), " "+testKey+" ", testValue)
+			require.NoError(t, err)
+
+			err = c.Delete(context.Background(), testKey)
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "", val)
 		})
 	}
 
+	t.Run("["+Redis.String()+"] [mock] - empty key", func(t *testing.T) {
+		c, _ := newMockRedisClient(t)
+
+		err := c.Delete(context.Background(), "")
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
 	t.Run("["+Redis.String()+"] [mock] - valid key", func(t *testing.T) {
 		c, conn := newMockRedisClient(t)
 
-		responseBytes, err := json.Marshal(&testModel)
-		require.NoError(t, err)
-
-		setCmd := conn.Command(cache.SetCommand, testKey, string(responseBytes))
+		// Mock the redis string
+		delCmd := conn.Command(cache.DeleteCommand, testKey).Expect(1)
 
-		err = c.SetModel(context.Background(), testKey, testModel, 0)
+		err := c.Delete(context.Background(), testKey)
 		require.NoError(t, err)
 
-		assert.True(t, setCmd.Called)
+		assert.True(t, delCmd.Called)
 	})
 }
 
+// TestClient_DeleteMany will test the method DeleteMany()
+func TestClient_DeleteMany(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.DeleteMany(context.Background(), testKey, "  ")
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - no keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.DeleteMany(context.Background())
+			require.NoError(t, err)
+		})
+
+		t.Run(testCase.name+" - valid keys, some missing", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetMulti(context.Background(), map[string]string{
+				testKey + "-1": testValue + "-1",
+				testKey + "-2": testValue + "-2",
+				testKey + "-3": testValue + "-3",
+			})
+			require.NoError(t, err)
+
+			err = c.DeleteMany(context.Background(), testKey+"-1", " "+testKey+"-2 ", testKey+"-missing")
+			require.NoError(t, err)
+
+			var values map[string]string
+			values, err = c.GetMulti(context.Background(), testKey+"-1", testKey+"-2", testKey+"-3")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{testKey + "-3": testValue + "-3"}, values)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - single command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		delCmd := conn.Command(cache.DeleteCommand, testKey+"-1", testKey+"-2", testKey+"-3").Expect(int64(2))
+
+		err := c.DeleteMany(context.Background(), testKey+"-1", testKey+"-2", testKey+"-3")
+		require.NoError(t, err)
+
+		assert.True(t, delCmd.Called)
+		assert.Equal(t, 1, conn.Stats(delCmd))
+	})
+}
+
+// TestClient_DeleteByPattern will test the method DeleteByPattern()
+func TestClient_DeleteByPattern(t *testing.T) {
+
+	t.Run("empty pattern", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		_, err = c.DeleteByPattern(context.Background(), "  ")
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("["+FreeCache.String()+"] not supported", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		_, err = c.DeleteByPattern(context.Background(), "user:123:*")
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] matching keys are removed", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		// Record the commands (SCAN is used, never KEYS)
+		var mu sync.Mutex
+		var commands []string
+		r.Server().SetPreHook(func(_ *server.Peer, cmd string, args ...string) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if cmd == scanCommand {
+				commands = append(commands, cmd+" "+args[len(args)-1])
+			} else {
+				commands = append(commands, cmd)
+			}
+			return false
+		})
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(2))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, r.Set("user:123:"+strconv.Itoa(i), testValue))
+		}
+		require.NoError(t, r.Set("user:1234:0", testValue))
+		require.NoError(t, r.Set("user:456:0", testValue))
+		require.NoError(t, r.Set("other-key", testValue))
+
+		var total int
+		total, err = c.DeleteByPattern(context.Background(), "user:123:*")
+		require.NoError(t, err)
+		assert.Equal(t, 5, total)
+		assert.ElementsMatch(t, []string{"other-key", "user:1234:0", "user:456:0"}, r.Keys())
+
+		mu.Lock()
+		assert.Contains(t, commands, scanCommand+" 2")
+		assert.NotContains(t, commands, "KEYS")
+		mu.Unlock()
+
+		// No matching keys
+		total, err = c.DeleteByPattern(context.Background(), "user:123:*")
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] only keys under the key prefix", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), "user:123:name", testValue))
+		require.NoError(t, r.Set("user:123:name", testValue))
+
+		var total int
+		total, err = c.DeleteByPattern(context.Background(), "user:123:*")
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []string{"user:123:name"}, r.Keys())
+	})
+}
+
+// TestClient_Scan will test the method Scan()
+func TestClient_Scan(t *testing.T) {
+
+	t.Run("empty pattern", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		err = c.Scan(context.Background(), "  ", func(string) error { return nil })
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("["+FreeCache.String()+"] not supported", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		err = c.Scan(context.Background(), "user:123:*", func(string) error { return nil })
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] matching keys", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(2))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, r.Set("user:123:"+strconv.Itoa(i), testValue))
+		}
+		require.NoError(t, r.Set("user:1234:0", testValue))
+		require.NoError(t, r.Set("other-key", testValue))
+
+		var keys []string
+		err = c.Scan(context.Background(), "user:123:*", func(key string) error {
+			keys = append(keys, key)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"user:123:0", "user:123:1", "user:123:2", "user:123:3", "user:123:4"}, keys)
+
+		// No matching keys
+		keys = nil
+		err = c.Scan(context.Background(), "missing:*", func(key string) error {
+			keys = append(keys, key)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] stops when fn returns an error", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(2))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, r.Set("user:123:"+strconv.Itoa(i), testValue))
+		}
+
+		errStop := errors.New("stop")
+		var calls int
+		err = c.Scan(context.Background(), "user:123:*", func(string) error {
+			calls++
+			return errStop
+		})
+		require.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] stops when the context is done", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(1))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, r.Set("user:123:"+strconv.Itoa(i), testValue))
+		}
+
+		// Cancel after the first batch
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var calls int
+		err = c.Scan(ctx, "user:123:*", func(string) error {
+			calls++
+			cancel()
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] only keys under the key prefix", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), "user:123:name", testValue))
+		require.NoError(t, r.Set("user:123:other", testValue))
+
+		var keys []string
+		err = c.Scan(context.Background(), "user:123:*", func(key string) error {
+			keys = append(keys, key)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"user:123:name"}, keys)
+	})
+}
+
+// TestClient_GetModel will test the method GetModel()
+func TestClient_GetModel(t *testing.T) {
+
+	testModel := &genericStruct{
+		StringField: testValue,
+		IntField:    123,
+		BoolField:   true,
+		FloatField:  12.34,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			testModelEmpty := new(genericStruct)
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.GetModel(context.Background(), "", testModelEmpty)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - just spaces", func(t *testing.T) {
+			testModelEmpty := new(genericStruct)
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.GetModel(context.Background(), "   ", testModelEmpty)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - valid key", func(t *testing.T) {
+			testModelEmpty := new(genericStruct)
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, testModel, 0)
+			require.NoError(t, err)
+
+			err = c.GetModel(context.Background(), testKey, testModelEmpty)
+			require.NoError(t, err)
+			assert.Equal(t, testModel.StringField, testModelEmpty.StringField)
+			assert.Equal(t, testModel.IntField, testModelEmpty.IntField)
+			assert.Equal(t, testModel.BoolField, testModelEmpty.BoolField)
+			assert.InDelta(t, testModel.FloatField, testModelEmpty.FloatField, 0)
+		})
+
+		t.Run(testCase.name+" - record does not exist", func(t *testing.T) {
+			testModelEmpty := new(genericStruct)
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.GetModel(context.Background(), testKey, testModelEmpty)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.NotEqual(t, testModel.StringField, testModelEmpty.StringField)
+		})
+	}
+
+	// Missing keys and values that cannot be decoded return distinct errors
+	var errorTests = []struct {
+		name        string
+		value       string
+		expectedErr error
+		jsonErr     interface{}
+	}{
+		{"missing key", "", ErrKeyNotFound, nil},
+		{"corrupt json", `{"string_field":`, ErrModelUnmarshal, new(*json.SyntaxError)},
+		{"type mismatch", `{"int_field":"not-a-number"}`, ErrModelUnmarshal, new(*json.UnmarshalTypeError)},
+	}
+	for _, testCase := range testCases {
+		for _, test := range errorTests {
+			t.Run(testCase.name+" - "+test.name, func(t *testing.T) {
+				c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+				defer c.Close(context.Background())
+
+				if len(test.value) > 0 {
+					require.NoError(t, c.Set(context.Background(), testKey, test.value))
+				}
+
+				err = c.GetModel(context.Background(), testKey, new(genericStruct))
+				require.ErrorIs(t, err, test.expectedErr)
+				if test.jsonErr != nil { // The serializer error is kept
+					require.NotErrorIs(t, err, ErrKeyNotFound)
+					require.ErrorAs(t, err, test.jsonErr)
+				}
+			})
+		}
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - empty key", func(t *testing.T) {
+		testModelEmpty := new(genericStruct)
+		c, _ := newMockRedisClient(t)
+
+		err := c.GetModel(context.Background(), "", testModelEmpty)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("["+Redis.String()+"] [mock] - record does not exist", func(t *testing.T) {
+		testModelEmpty := new(genericStruct)
+		c, conn := newMockRedisClient(t)
+
+		getCmd := conn.Command(cache.GetCommand, testKey).Expect(nil)
+
+		err := c.GetModel(context.Background(), testKey, testModelEmpty)
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		assert.True(t, getCmd.Called)
+	})
+
+	t.Run("["+Redis.String()+"] [mock] - record exists", func(t *testing.T) {
+		testModelEmpty := new(genericStruct)
+		c, conn := newMockRedisClient(t)
+
+		responseBytes, err := json.Marshal(&testModel)
+		require.NoError(t, err)
+
+		setCmd := conn.Command(cache.SetCommand, testKey, string(responseBytes))
+
+		err = c.SetModel(context.Background(), testKey, testModel, 0)
+		require.NoError(t, err)
+		assert.True(t, setCmd.Called)
+
+		getCmd := conn.Command(cache.GetCommand, testKey).Expect(responseBytes)
+
+		err = c.GetModel(context.Background(), testKey, testModelEmpty)
+		require.NoError(t, err)
+		assert.True(t, getCmd.Called)
+
+		assert.Equal(t, testModel.StringField, testModelEmpty.StringField)
+	})
+}
+
+// TestClient_ModelCollections will test the method SetModel() and GetModel() using slices and maps
+func TestClient_ModelCollections(t *testing.T) {
+
+	populated := []genericStruct{
+		{StringField: "third", IntField: 3},
+		{StringField: "first", IntField: 1, BoolField: true},
+		{StringField: "second", IntField: 2, FloatField: 2.5},
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		for _, serializer := range []string{"json", "msgpack"} {
+			opts := []ClientOps{testCase.opts}
+			if serializer == "msgpack" {
+				opts = append(opts, WithMsgpack())
+			}
+			name := testCase.name + " [" + serializer + "]"
+
+			t.Run(name+" - slices", func(t *testing.T) {
+				c, err := NewClient(context.Background(), opts...)
+				require.NoError(t, err)
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				// Populated (the order is kept), the slice or a pointer to the slice
+				for _, model := range []interface{}{populated, &populated} {
+					require.NoError(t, c.SetModel(context.Background(), testKey, model, 0))
+					out := []genericStruct{{StringField: "replaced"}}
+					require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+					assert.Equal(t, populated, out)
+				}
+
+				// Empty is read back as empty
+				require.NoError(t, c.SetModel(context.Background(), testKey, []genericStruct{}, 0))
+				out := []genericStruct{{StringField: "replaced"}}
+				require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+				assert.NotNil(t, out)
+				assert.Empty(t, out)
+
+				// Nil is read back as nil
+				var nilSlice []genericStruct
+				require.NoError(t, c.SetModel(context.Background(), testKey, nilSlice, 0))
+				out = []genericStruct{{StringField: "replaced"}}
+				require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+				assert.Nil(t, out)
+			})
+
+			t.Run(name+" - maps", func(t *testing.T) {
+				c, err := NewClient(context.Background(), opts...)
+				require.NoError(t, err)
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				// Populated (the existing entries are replaced)
+				models := map[string]genericStruct{"first": populated[1], "second": populated[2]}
+				require.NoError(t, c.SetModel(context.Background(), testKey, models, 0))
+				out := map[string]genericStruct{"existing": {StringField: "replaced"}}
+				require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+				assert.Equal(t, models, out)
+
+				// Empty is read back as empty
+				require.NoError(t, c.SetModel(context.Background(), testKey, map[string]genericStruct{}, 0))
+				out = map[string]genericStruct{"existing": {StringField: "replaced"}}
+				require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+				assert.NotNil(t, out)
+				assert.Empty(t, out)
+
+				// Nil is read back as nil
+				var nilMap map[string]genericStruct
+				require.NoError(t, c.SetModel(context.Background(), testKey, &nilMap, 0))
+				out = map[string]genericStruct{"existing": {StringField: "replaced"}}
+				require.NoError(t, c.GetModel(context.Background(), testKey, &out))
+				assert.Nil(t, out)
+			})
+		}
+	}
+}
+
+// TestClient_GetModelWithTTL will test the method GetModelWithTTL()
+func TestClient_GetModelWithTTL(t *testing.T) {
+
+	testModel := &genericStruct{
+		StringField: testValue,
+		IntField:    123,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - value and remaining ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, testModel, time.Minute))
+
+			model := new(genericStruct)
+			var ttl time.Duration
+			ttl, err = c.GetModelWithTTL(context.Background(), testKey, model)
+			require.NoError(t, err)
+			assert.Equal(t, testModel, model)
+			assert.Greater(t, ttl, 50*time.Second)
+			assert.LessOrEqual(t, ttl, time.Minute)
+		})
+
+		t.Run(testCase.name+" - no expiration", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, testModel, 0))
+
+			model := new(genericStruct)
+			var ttl time.Duration
+			ttl, err = c.GetModelWithTTL(context.Background(), testKey, model)
+			require.NoError(t, err)
+			assert.Equal(t, testModel, model)
+			assert.Equal(t, time.Duration(0), ttl)
+		})
+
+		t.Run(testCase.name+" - compressed value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts,
+				WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, testModel, time.Minute))
+
+			model := new(genericStruct)
+			var ttl time.Duration
+			ttl, err = c.GetModelWithTTL(context.Background(), testKey, model)
+			require.NoError(t, err)
+			assert.Equal(t, testModel, model)
+			assert.Positive(t, ttl)
+		})
+
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.GetModelWithTTL(context.Background(), "", new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			var ttl time.Duration
+			ttl, err = c.GetModelWithTTL(context.Background(), testKey+"-missing", new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Equal(t, time.Duration(0), ttl)
+		})
+
+		t.Run(testCase.name+" - value cannot be decoded", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetTTL(context.Background(), testKey, `{"string_field":`, time.Minute))
+
+			var ttl time.Duration
+			ttl, err = c.GetModelWithTTL(context.Background(), testKey, new(genericStruct))
+			require.ErrorIs(t, err, ErrModelUnmarshal)
+			require.NotErrorIs(t, err, ErrKeyNotFound)
+			assert.Equal(t, time.Duration(0), ttl)
+		})
+	}
+
+	t.Run("["+Tiered.String()+"] - reads the ttl from redis and populates the local tier", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, testModel, time.Minute))
+		local.Clear()
+		r.FastForward(30 * time.Second)
+
+		model := new(genericStruct)
+		ttl, err := c.GetModelWithTTL(context.Background(), testKey, model)
+		require.NoError(t, err)
+		assert.Equal(t, testModel, model)
+		assert.Equal(t, 30*time.Second, ttl)
+		assert.Equal(t, int64(1), local.EntryCount())
+	})
+}
+
+// TestClient_Increment will test the methods Increment() and Decrement()
+func TestClient_Increment(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.Increment(context.Background(), "   ", 1)
+			require.ErrorIs(t, err, ErrKeyRequired)
+
+			_, err = c.Decrement(context.Background(), "", 1)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing key starts at zero", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var value int64
+			value, err = c.Increment(context.Background(), testKey, 5)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), value)
+
+			value, err = c.Increment(context.Background(), testKey, 2)
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), value)
+
+			value, err = c.Decrement(context.Background(), testKey, 10)
+			require.NoError(t, err)
+			assert.Equal(t, int64(-3), value)
+
+			var stored string
+			stored, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "-3", stored)
+		})
+
+		t.Run(testCase.name+" - non-numeric value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.Set(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+
+			_, err = c.Increment(context.Background(), testKey, 1)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrValueNotNumeric)
+		})
+
+		t.Run(testCase.name+" - concurrent increments", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, incErr := c.Increment(context.Background(), testKey, 1)
+					assert.NoError(t, incErr)
+				}()
+			}
+			wg.Wait()
+
+			var value int64
+			value, err = c.Increment(context.Background(), testKey, 0)
+			require.NoError(t, err)
+			assert.Equal(t, int64(20), value)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - INCRBY command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		incrCmd := conn.Command(incrementByCommand, testKey, int64(3)).Expect(int64(3))
+
+		value, err := c.Increment(context.Background(), testKey, 3)
+		require.NoError(t, err)
+		assert.True(t, incrCmd.Called)
+		assert.Equal(t, int64(3), value)
+	})
+
+	t.Run("["+FreeCache.String()+"] - the counter lock is internal", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithObservabilityContext())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		// Not recorded (metrics and hooks)
+		ctx := ContextWithCacheEvents(context.Background())
+		_, err = c.Increment(ctx, testKey, 1)
+		require.NoError(t, err)
+		assert.Empty(t, CacheEventsFromContext(ctx))
+
+		// Not listed
+		release, err := c.(*Client).lockCounter(context.Background(), testKey)
+		require.NoError(t, err)
+		defer release()
+		var locks []LockInfo
+		locks, err = c.ListLocks(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, locks)
+	})
+}
+
+// TestClient_SetInt will test the methods SetInt() and GetInt()
+func TestClient_SetInt(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetInt(context.Background(), "", 1, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+
+			_, err = c.GetInt(context.Background(), "   ")
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - set and get", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetInt(context.Background(), testKey, -42, 0)
+			require.NoError(t, err)
+
+			var n int64
+			n, err = c.GetInt(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, int64(-42), n)
+
+			// Stored as the decimal string
+			var stored string
+			stored, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "-42", stored)
+		})
+
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.GetInt(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+
+		t.Run(testCase.name+" - non-numeric value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.Set(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+
+			_, err = c.GetInt(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrValueNotNumeric)
+			assert.Contains(t, err.Error(), `cachestore: get_int key="test-key"`)
+			assert.Contains(t, err.Error(), `parsing "`+testValue+`": invalid syntax`)
+		})
+
+		t.Run(testCase.name+" - set int then increment", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetInt(context.Background(), testKey, 10, 0)
+			require.NoError(t, err)
+
+			var n int64
+			n, err = c.Increment(context.Background(), testKey, 5)
+			require.NoError(t, err)
+			assert.Equal(t, int64(15), n)
+
+			n, err = c.GetInt(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, int64(15), n)
+		})
+
+		t.Run(testCase.name+" - the ttl is used", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetInt(context.Background(), testKey, 1, 2*time.Second)
+			require.NoError(t, err)
+
+			testCase.FastForward(3 * time.Second)
+
+			_, err = c.GetInt(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+
+		t.Run(testCase.name+" - not compressed", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), testCase.opts, WithCompression(CompressionGzip), WithCompressionThreshold(1),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetInt(context.Background(), testKey, 1234567890, 0)
+			require.NoError(t, err)
+
+			var n int64
+			n, err = c.Increment(context.Background(), testKey, 1)
+			require.NoError(t, err)
+			assert.Equal(t, int64(1234567891), n)
+		})
+	}
+}
+
+// TestClient_SetDuration will test the methods SetDuration() and GetDuration()
+func TestClient_SetDuration(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - set and get", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			for _, d := range []time.Duration{
+				0, time.Nanosecond, 90 * time.Minute, -15 * time.Second, math.MaxInt64, math.MinInt64,
+			} {
+				require.NoError(t, c.SetDuration(context.Background(), testKey, d, 0))
+
+				var stored time.Duration
+				stored, err = c.GetDuration(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Equal(t, d, stored)
+			}
+
+			// Stored as the integer nanoseconds
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.FormatInt(math.MinInt64, 10), value)
+		})
+
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.GetDuration(context.Background(), testKey+"-missing")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+
+			_, err = c.GetDuration(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - malformed value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, "90m"))
+
+			_, err = c.GetDuration(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrValueNotNumeric)
+			assert.Contains(t, err.Error(), `cachestore: get_duration key="test-key"`)
+		})
+	}
+}
+
+// TestClient_SetTime will test the methods SetTime() and GetTime()
+func TestClient_SetTime(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - set and get", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			offset := time.FixedZone("", -7*60*60)
+			for _, tm := range []time.Time{
+				{},
+				time.Date(2024, 2, 29, 13, 14, 15, 123456789, time.UTC),
+				time.Date(2024, 2, 29, 13, 14, 15, 0, offset),
+				time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+				time.Date(1, 1, 1, 0, 0, 0, 1, time.UTC),
+			} {
+				require.NoError(t, c.SetTime(context.Background(), testKey, tm, 0))
+
+				var stored time.Time
+				stored, err = c.GetTime(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.True(t, tm.Equal(stored), "expected %s, got %s", tm, stored)
+				_, expectedOffset := tm.Zone()
+				_, storedOffset := stored.Zone()
+				assert.Equal(t, expectedOffset, storedOffset)
+			}
+		})
+
+		t.Run(testCase.name+" - zero value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetTime(context.Background(), testKey, time.Time{}, 0))
+
+			var stored time.Time
+			stored, err = c.GetTime(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, stored.IsZero())
+		})
+
+		t.Run(testCase.name+" - the monotonic clock is not stored", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			now := time.Now()
+			require.NoError(t, c.SetTime(context.Background(), testKey, now, 0))
+
+			var stored time.Time
+			stored, err = c.GetTime(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, now.Equal(stored))
+			assert.Equal(t, now.Round(0).String(), stored.Local().String())
+		})
+
+		t.Run(testCase.name+" - year out of range", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetTime(context.Background(), testKey, time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC), 0)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `cachestore: set_time key="test-key"`)
+
+			_, err = c.GetTime(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+
+		t.Run(testCase.name+" - malformed value", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, "2024-02-29 13:14:15"))
+
+			_, err = c.GetTime(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrValueNotTime)
+			assert.Contains(t, err.Error(), `cachestore: get_time key="test-key"`)
+			assert.Contains(t, err.Error(), `parsing time "2024-02-29 13:14:15"`)
+		})
+
+		t.Run(testCase.name+" - the ttl is used", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetTime(context.Background(), testKey, time.Now(), 2*time.Second))
+			require.NoError(t, c.SetDuration(context.Background(), testKey+"-duration", time.Second, 2*time.Second))
+			testCase.FastForward(3 * time.Second)
+
+			_, err = c.GetTime(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+
+			_, err = c.GetDuration(context.Background(), testKey+"-duration")
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+}
+
+// TestClient_Append will test the method Append()
+func TestClient_Append(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.Append(context.Background(), "   ", testValue)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing key is created", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var length int
+			length, err = c.Append(context.Background(), testKey, "first;")
+			require.NoError(t, err)
+			assert.Equal(t, 6, length)
+
+			length, err = c.Append(context.Background(), testKey, "second;")
+			require.NoError(t, err)
+			assert.Equal(t, 13, length)
+
+			var stored string
+			stored, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "first;second;", stored)
+		})
+
+		t.Run(testCase.name+" - expiration is preserved", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetTTL(context.Background(), testKey, "first;", time.Minute))
+			_, err = c.Append(context.Background(), testKey, "second;")
+			require.NoError(t, err)
+
+			testCase.FastForward(2 * time.Minute)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - concurrent appends", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					_, appendErr := c.Append(context.Background(), testKey, "["+strconv.Itoa(i)+"]")
+					assert.NoError(t, appendErr)
+				}(i)
+			}
+			wg.Wait()
+
+			// All the values are preserved (in any order)
+			var stored string
+			stored, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			for i := 0; i < 20; i++ {
+				assert.Equal(t, 1, strings.Count(stored, "["+strconv.Itoa(i)+"]"), i)
+			}
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - APPEND command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		appendCmd := conn.Command(appendCommand, testKey, testValue).Expect(int64(len(testValue)))
+
+		length, err := c.Append(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+		assert.True(t, appendCmd.Called)
+		assert.Equal(t, len(testValue), length)
+	})
+
+	t.Run("["+Tiered.String()+"] - local copy is removed", func(t *testing.T) {
+		c, _, local := newTieredTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, "first;"))
+		assert.Equal(t, int64(1), local.EntryCount())
+
+		_, err := c.Append(context.Background(), testKey, "second;")
+		require.NoError(t, err)
+
+		var stored string
+		stored, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "first;second;", stored)
+	})
+}
+
+// TestClient_GetSet will test the method GetSet()
+func TestClient_GetSet(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, _, err = c.GetSet(context.Background(), "   ", testValue)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - previous value is returned", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			old, existed, err := c.GetSet(context.Background(), testKey, "token-1")
+			require.NoError(t, err)
+			assert.False(t, existed)
+			assert.Empty(t, old)
+
+			old, existed, err = c.GetSet(context.Background(), testKey, "token-2")
+			require.NoError(t, err)
+			assert.True(t, existed)
+			assert.Equal(t, "token-1", old)
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "token-2", value)
+		})
+
+		t.Run(testCase.name+" - empty previous value existed", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, _, err = c.GetSet(context.Background(), testKey, "")
+			require.NoError(t, err)
+
+			old, existed, err := c.GetSet(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+			assert.True(t, existed)
+			assert.Empty(t, old)
+		})
+
+		t.Run(testCase.name+" - default ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithDefaultTTL(time.Minute))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, _, err = c.GetSet(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+
+			testCase.FastForward(2 * time.Minute)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - compressed values", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), testCase.opts, WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, _, err = c.GetSet(context.Background(), testKey, "token-1")
+			require.NoError(t, err)
+
+			old, _, err := c.GetSet(context.Background(), testKey, "token-2")
+			require.NoError(t, err)
+			assert.Equal(t, "token-1", old)
+		})
+
+		t.Run(testCase.name+" - concurrent swaps", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// Each value is returned exactly once (no lost updates)
+			const total = 20
+			var mu sync.Mutex
+			seen := make(map[string]int)
+			var wg sync.WaitGroup
+			for i := 0; i < total; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					old, existed, swapErr := c.GetSet(context.Background(), testKey, strconv.Itoa(i))
+					assert.NoError(t, swapErr)
+					if existed {
+						mu.Lock()
+						seen[old]++
+						mu.Unlock()
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			last, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			seen[last]++
+
+			assert.Len(t, seen, total)
+			for value, count := range seen {
+				assert.Equal(t, 1, count, value)
+			}
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - GETSET command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		getSetCmd := conn.Command(getSetCommand, testKey, testValue).Expect("old")
+
+		old, existed, err := c.GetSet(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+		assert.True(t, getSetCmd.Called)
+		assert.True(t, existed)
+		assert.Equal(t, "old", old)
+	})
+
+	t.Run("["+Tiered.String()+"] - local copy is updated", func(t *testing.T) {
+		c, _, _ := newTieredTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, "token-1"))
+		_, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+
+		old, existed, err := c.GetSet(context.Background(), testKey, "token-2")
+		require.NoError(t, err)
+		assert.True(t, existed)
+		assert.Equal(t, "token-1", old)
+
+		var value string
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "token-2", value)
+	})
+}
+
+// TestClient_CompareAndSwap will test the method CompareAndSwap()
+func TestClient_CompareAndSwap(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.CompareAndSwap(context.Background(), "   ", "", testValue, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - match, mismatch and missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// Missing key (not matching, nothing is written)
+			swapped, err := c.CompareAndSwap(context.Background(), testKey, "", "version-1", 0)
+			require.NoError(t, err)
+			assert.False(t, swapped)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			// Match
+			require.NoError(t, c.Set(context.Background(), testKey, "version-1"))
+			swapped, err = c.CompareAndSwap(context.Background(), testKey, "version-1", "version-2", 0)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "version-2", value)
+
+			// Mismatch (nothing is written)
+			swapped, err = c.CompareAndSwap(context.Background(), testKey, "version-1", "version-3", 0)
+			require.NoError(t, err)
+			assert.False(t, swapped)
+
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "version-2", value)
+
+			// Empty value
+			swapped, err = c.CompareAndSwap(context.Background(), testKey, "version-2", "", 0)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+			swapped, err = c.CompareAndSwap(context.Background(), testKey, "", "version-3", 0)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+		})
+
+		t.Run(testCase.name+" - ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, "version-1"))
+			swapped, err := c.CompareAndSwap(context.Background(), testKey, "version-1", "version-2", time.Minute)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+
+			testCase.FastForward(2 * time.Minute)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - compressed values", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), testCase.opts, WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, "version-1"))
+			swapped, err := c.CompareAndSwap(context.Background(), testKey, "version-1", "version-2", 0)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "version-2", value)
+		})
+
+		t.Run(testCase.name+" - concurrent swaps", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// Only one caller wins the race for the same version
+			require.NoError(t, c.Set(context.Background(), testKey, "0"))
+			const total = 20
+			var winners atomic.Int64
+			var wg sync.WaitGroup
+			for i := 0; i < total; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					swapped, swapErr := c.CompareAndSwap(context.Background(), testKey, "0", strconv.Itoa(i+1), 0)
+					assert.NoError(t, swapErr)
+					if swapped {
+						winners.Add(1)
+					}
+				}(i)
+			}
+			wg.Wait()
+			assert.Equal(t, int64(1), winners.Load())
+
+			// Optimistic increments (retry until swapped) never lose an update
+			require.NoError(t, c.Set(context.Background(), testKey, "0"))
+			for i := 0; i < total; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for {
+						current, getErr := c.Get(context.Background(), testKey)
+						if !assert.NoError(t, getErr) {
+							return
+						}
+						n, _ := strconv.Atoi(current)
+						swapped, swapErr := c.CompareAndSwap(context.Background(), testKey, current, strconv.Itoa(n+1), 0)
+						if !assert.NoError(t, swapErr) || swapped {
+							return
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, strconv.Itoa(total), value)
+		})
+	}
+
+	t.Run("["+Tiered.String()+"] - local copy is updated", func(t *testing.T) {
+		c, r, _ := newTieredTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, "version-1"))
+		swapped, err := c.CompareAndSwap(context.Background(), testKey, "version-1", "version-2", 0)
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		var value string
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "version-2", value)
+
+		// Changed in redis (a failed swap removes the stale local copy)
+		require.NoError(t, r.Set(testKey, "version-3"))
+		swapped, err = c.CompareAndSwap(context.Background(), testKey, "version-2", "version-4", 0)
+		require.NoError(t, err)
+		assert.False(t, swapped)
+
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "version-3", value)
+	})
+}
+
+// TestClient_SetModel will test the method SetModel()
+func TestClient_SetModel(t *testing.T) {
+
+	testModel := &genericStruct{
+		StringField: testValue,
+		IntField:    123,
+		BoolField:   true,
+		FloatField:  12.34,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetModel(context.Background(), "", testModel, 0)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - just spaces", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.Set(context.Background(), "   ", testModel)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - valid key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, testModel, 0)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - valid key", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		responseBytes, err := json.Marshal(&testModel)
+		require.NoError(t, err)
+
+		setCmd := conn.Command(cache.SetCommand, testKey, string(responseBytes))
+
+		err = c.SetModel(context.Background(), testKey, testModel, 0)
+		require.NoError(t, err)
+
+		assert.True(t, setCmd.Called)
+	})
+}
+
+// TestClient_SetModelIfChanged will test the method SetModelIfChanged()
+func TestClient_SetModelIfChanged(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.SetModelIfChanged(context.Background(), "", &genericStruct{}, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - only changed models are written", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var changed bool
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+			require.NoError(t, err)
+			assert.True(t, changed)
+
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+			require.NoError(t, err)
+			assert.False(t, changed)
+
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: "other"}, 0)
+			require.NoError(t, err)
+			assert.True(t, changed)
+			assert.Equal(t, int64(2), c.Stats().Sets)
+
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, "other", model.StringField)
+		})
+
+		t.Run(testCase.name+" - maps compare equal", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			model := map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}
+			_, err = c.SetModelIfChanged(context.Background(), testKey, model, 0)
+			require.NoError(t, err)
+
+			var changed bool
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, map[string]int{"d": 4, "c": 3, "b": 2, "a": 1}, 0)
+			require.NoError(t, err)
+			assert.False(t, changed)
+		})
+
+		t.Run(testCase.name+" - unchanged model refreshes the ttl", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{}, time.Minute)
+			require.NoError(t, err)
+
+			testCase.FastForward(45 * time.Second)
+			var changed bool
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{}, time.Minute)
+			require.NoError(t, err)
+			assert.False(t, changed)
+
+			// Past the original expiration
+			testCase.FastForward(45 * time.Second)
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, found)
+		})
+
+		t.Run(testCase.name+" - compressed values", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), testCase.opts, WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+			require.NoError(t, err)
+
+			var changed bool
+			changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+			require.NoError(t, err)
+			assert.False(t, changed)
+		})
+	}
+
+	t.Run("["+Tiered.String()+"] - a stale local copy is not compared", func(t *testing.T) {
+		c, r, _ := newTieredTestClient(t)
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+
+		// Changed in redis (ie: by another node), the local copy is stale
+		require.NoError(t, r.Set(testKey, `{"bool_field":false,"float_field":0,"int_field":0,"string_field":"other"}`))
+
+		changed, err := c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+}
+
+// TestClient_ReplaceModel will test the method ReplaceModel()
+func TestClient_ReplaceModel(t *testing.T) {
+
+	testModel := &genericStruct{
+		IntField:    123,
+		StringField: testValue,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.ReplaceModel(context.Background(), "", testModel, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing key is not written", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var replaced bool
+			replaced, err = c.ReplaceModel(context.Background(), testKey+"-missing", testModel, time.Minute)
+			require.NoError(t, err)
+			assert.False(t, replaced)
+
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey+"-missing", &genericStruct{}), ErrKeyNotFound)
+			assert.Equal(t, int64(0), c.Stats().Sets)
+		})
+
+		t.Run(testCase.name+" - existing key is overwritten", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: "old"}, 0))
+
+			var replaced bool
+			replaced, err = c.ReplaceModel(context.Background(), testKey, testModel, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, replaced)
+
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, testModel, model)
+			assert.Greater(t, testCase.TTL(c, testKey), 50*time.Second)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - SET XX PX command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		responseBytes, err := json.Marshal(testModel)
+		require.NoError(t, err)
+
+		setCmd := conn.Command(
+			cache.SetCommand, testKey, string(responseBytes), setExistsOption, setExpireOption, int64(60000),
+		).Expect("OK")
+
+		var replaced bool
+		replaced, err = c.ReplaceModel(context.Background(), testKey, testModel, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, replaced)
+		assert.True(t, setCmd.Called)
+
+		// A nil reply is a missing key
+		conn.Command(cache.SetCommand, testKey, string(responseBytes), setExistsOption).Expect(nil)
+		replaced, err = c.ReplaceModel(context.Background(), testKey, testModel, 0)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+	})
+
+	t.Run("["+Tiered.String()+"] - missing key removes the local copy", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithTieredCache(nil, &RedisConfig{URL: r.Addr()}, time.Minute))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, testModel, 0))
+		r.Del(testKey) // Invalidated by another process
+
+		var replaced bool
+		replaced, err = c.ReplaceModel(context.Background(), testKey, testModel, 0)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+		require.ErrorIs(t, c.GetModel(context.Background(), testKey, &genericStruct{}), ErrKeyNotFound)
+	})
+}
+
+// TestClient_GetMulti will test the method GetMulti()
+func TestClient_GetMulti(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.GetMulti(context.Background(), testKey, "")
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - just spaces", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			_, err = c.GetMulti(context.Background(), "   ", testKey)
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing keys are absent", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetMulti(context.Background(), map[string]string{
+				testKey + "-1": testValue + "-1",
+				testKey + "-2": testValue + "-2",
+			})
+			require.NoError(t, err)
+
+			var values map[string]string
+			values, err = c.GetMulti(context.Background(), testKey+"-1", " "+testKey+"-2 ", testKey+"-3")
+			require.NoError(t, err)
+			assert.Len(t, values, 2)
+			assert.Equal(t, testValue+"-1", values[testKey+"-1"])
+			assert.Equal(t, testValue+"-2", values[testKey+"-2"])
+			_, found := values[testKey+"-3"]
+			assert.False(t, found)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - single MGET command", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		getCmd := conn.Command(multiGetCommand, testKey+"-1", testKey+"-2").ExpectSlice(
+			[]byte(testValue), nil,
+		)
+
+		values, err := c.GetMulti(context.Background(), testKey+"-1", testKey+"-2")
+		require.NoError(t, err)
+		assert.True(t, getCmd.Called)
+		assert.Equal(t, map[string]string{testKey + "-1": testValue}, values)
+	})
+}
+
+// TestClient_SetMulti will test the method SetMulti()
+func TestClient_SetMulti(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetMulti(context.Background(), map[string]string{"": testValue})
+			require.Error(t, err)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - valid keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetMulti(context.Background(), map[string]string{
+				testKey + "-1": testValue + "-1",
+				testKey + "-2": testValue + "-2",
+			})
+			require.NoError(t, err)
+
+			var val string
+			val, err = c.Get(context.Background(), testKey+"-2")
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-2", val)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - pipelined MSET with dependencies", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.Command(
+			multiSetCommand, testKey+"-1", testValue+"-1", testKey+"-2", testValue+"-2",
+		).Expect("OK")
+		depCmd := conn.Command(
+			cache.AddToSetCommand, cache.DependencyPrefix+"dependency", testKey+"-1", testKey+"-2",
+		).Expect(2)
+
+		err := c.SetMulti(context.Background(), map[string]string{
+			testKey + "-2": testValue + "-2",
+			testKey + "-1": testValue + "-1",
+		}, "dependency")
+		require.NoError(t, err)
+		assert.True(t, setCmd.Called)
+		assert.True(t, depCmd.Called)
+	})
+}
+
+// TestClient_SetModelMulti will test the method SetModelMulti()
+func TestClient_SetModelMulti(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			err = c.SetModelMulti(context.Background(), map[string]interface{}{"": &genericStruct{}}, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - no items", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			require.NoError(t, c.SetModelMulti(context.Background(), nil, 0))
+			assert.Equal(t, int64(0), c.Stats().Sets)
+		})
+
+		t.Run(testCase.name+" - valid models", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModelMulti(context.Background(), map[string]interface{}{
+				testKey + "-1": &genericStruct{StringField: testValue + "-1", IntField: 1},
+				testKey + "-2": &genericStruct{StringField: testValue + "-2", IntField: 2},
+			}, time.Minute, "dependency")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), c.Stats().Sets)
+
+			for i := 1; i <= 2; i++ {
+				model := new(genericStruct)
+				require.NoError(t, c.GetModel(context.Background(), testKey+"-"+strconv.Itoa(i), model))
+				assert.Equal(t, &genericStruct{StringField: testValue + "-" + strconv.Itoa(i), IntField: i}, model)
+			}
+
+			// The models expire after the ttl
+			testCase.FastForward(2 * time.Minute)
+			var found bool
+			found, err = c.Exists(context.Background(), testKey+"-1")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - dependencies are linked", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModelMulti(context.Background(), map[string]interface{}{
+				testKey + "-1": &genericStruct{},
+				testKey + "-2": &genericStruct{},
+			}, 0, "dependency")
+			require.NoError(t, err)
+
+			require.NoError(t, c.DeleteDependency(context.Background(), "dependency"))
+			assert.Equal(t, int64(2), c.Stats().Deletes)
+		})
+
+		t.Run(testCase.name+" - unserializable model writes nothing", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModelMulti(context.Background(), map[string]interface{}{
+				testKey + "-1":   &genericStruct{StringField: testValue},
+				testKey + "-2":   &genericStruct{StringField: testValue},
+				testKey + "-bad": make(chan int),
+			}, 0)
+			require.ErrorIs(t, err, ErrModelMarshal)
+			assert.Contains(t, err.Error(), testKey+"-bad")
+			assert.Equal(t, int64(0), c.Stats().Sets)
+
+			for _, key := range []string{testKey + "-1", testKey + "-2", testKey + "-bad"} {
+				var found bool
+				found, err = c.Exists(context.Background(), key)
+				require.NoError(t, err)
+				assert.False(t, found, key)
+			}
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - single pipeline", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.Command(
+			multiSetCommand, testKey+"-1", `{"bool_field":false,"float_field":0,"int_field":1,"string_field":""}`,
+			testKey+"-2", `{"bool_field":false,"float_field":0,"int_field":2,"string_field":""}`,
+		).Expect("OK")
+		expireCmd := conn.Command(pExpireCommand, testKey+"-1", int64(60000)).Expect(int64(1))
+		conn.Command(pExpireCommand, testKey+"-2", int64(60000)).Expect(int64(1))
+		depCmd := conn.Command(
+			cache.AddToSetCommand, cache.DependencyPrefix+"dependency", testKey+"-1", testKey+"-2",
+		).Expect(2)
+
+		err := c.SetModelMulti(context.Background(), map[string]interface{}{
+			testKey + "-2": &genericStruct{IntField: 2},
+			testKey + "-1": &genericStruct{IntField: 1},
+		}, time.Minute, "dependency")
+		require.NoError(t, err)
+		assert.True(t, setCmd.Called)
+		assert.True(t, expireCmd.Called)
+		assert.True(t, depCmd.Called)
+	})
+}
+
+// TestClient_GetModelMulti will test the method GetModelMulti()
+func TestClient_GetModelMulti(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var models []*genericStruct
+			_, err = c.GetModelMulti(context.Background(), []string{testKey, ""}, &models)
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - invalid output", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			for _, out := range []interface{}{
+				nil, []genericStruct{}, new(genericStruct), map[int]genericStruct{}, map[string]genericStruct(nil),
+			} {
+				_, err = c.GetModelMulti(context.Background(), []string{testKey}, out)
+				require.ErrorIs(t, err, ErrInvalidModelOutput)
+			}
+		})
+
+		t.Run(testCase.name+" - present and absent keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModelMulti(context.Background(), map[string]interface{}{
+				testKey + "-1": &genericStruct{StringField: testValue + "-1", IntField: 1},
+				testKey + "-3": &genericStruct{StringField: testValue + "-3", IntField: 3},
+			}, 0)
+			require.NoError(t, err)
+			keys := []string{testKey + "-1", testKey + "-2", testKey + "-3", testKey + "-4"}
+
+			// Slice of pointers (replaced, in the order of the keys)
+			models := []*genericStruct{{StringField: "existing"}}
+			var found []string
+			found, err = c.GetModelMulti(context.Background(), keys, &models)
+			require.NoError(t, err)
+			assert.Equal(t, []string{testKey + "-1", testKey + "-3"}, found)
+			assert.Equal(t, []*genericStruct{
+				{StringField: testValue + "-1", IntField: 1}, {StringField: testValue + "-3", IntField: 3},
+			}, models)
+
+			// Map of models (keyed by the requested key)
+			var byKey map[string]genericStruct
+			found, err = c.GetModelMulti(context.Background(), keys, &byKey)
+			require.NoError(t, err)
+			assert.Equal(t, []string{testKey + "-1", testKey + "-3"}, found)
+			assert.Equal(t, map[string]genericStruct{
+				testKey + "-1": {StringField: testValue + "-1", IntField: 1},
+				testKey + "-3": {StringField: testValue + "-3", IntField: 3},
+			}, byKey)
+
+			stats := c.Stats()
+			assert.Equal(t, int64(4), stats.Hits)
+			assert.Equal(t, int64(4), stats.Misses)
+		})
+
+		t.Run(testCase.name+" - no keys found", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			byKey := map[string]*genericStruct{}
+			var found []string
+			found, err = c.GetModelMulti(context.Background(), []string{testKey + "-missing"}, byKey)
+			require.NoError(t, err)
+			assert.Empty(t, found)
+			assert.Empty(t, byKey)
+		})
+
+		t.Run(testCase.name+" - value cannot be decoded", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey+"-bad", "not-json"))
+			var models []genericStruct
+			_, err = c.GetModelMulti(context.Background(), []string{testKey + "-bad"}, &models)
+			require.ErrorIs(t, err, ErrModelUnmarshal)
+			assert.Contains(t, err.Error(), testKey+"-bad")
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - single MGET", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		getCmd := conn.Command(multiGetCommand, testKey+"-1", testKey+"-2").ExpectSlice(
+			[]byte(`{"int_field":1}`), nil,
+		)
+
+		var models []genericStruct
+		found, err := c.GetModelMulti(context.Background(), []string{testKey + "-1", testKey + "-2"}, &models)
+		require.NoError(t, err)
+		assert.True(t, getCmd.Called)
+		assert.Equal(t, []string{testKey + "-1"}, found)
+		assert.Equal(t, []genericStruct{{IntField: 1}}, models)
+	})
+
+	t.Run("version mismatch is a missing key", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithModelVersioning())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(ContextWithModelVersion(context.Background(), 1), testKey+"-1", &genericStruct{}, 0))
+		require.NoError(t, c.SetModel(ContextWithModelVersion(context.Background(), 2), testKey+"-2", &genericStruct{}, 0))
+
+		var models []genericStruct
+		found, err := c.GetModelMulti(
+			ContextWithModelVersion(context.Background(), 2), []string{testKey + "-1", testKey + "-2"}, &models,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{testKey + "-2"}, found)
+		assert.Len(t, models, 1)
+	})
+}
+
+// TestClient_MaxKeyLength will test the option WithMaxKeyLength() for the key and lock methods
+func TestClient_MaxKeyLength(t *testing.T) {
+
+	const maxLength = 10
+	atLimit := strings.Repeat("k", maxLength)
+	overLimit := strings.Repeat("k", maxLength+1)
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - keys at the limit", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxKeyLength(maxLength))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), " "+atLimit+" ", testValue)) // Spaces are trimmed
+			require.NoError(t, c.SetTTL(context.Background(), atLimit, testValue, time.Minute))
+			require.NoError(t, c.SetModel(context.Background(), atLimit, &genericStruct{}, 0))
+			require.NoError(t, c.GetModel(context.Background(), atLimit, &genericStruct{}))
+			_, err = c.Get(context.Background(), atLimit)
+			require.NoError(t, err)
+			require.NoError(t, c.Delete(context.Background(), atLimit))
+
+			// The internal lock of the counters (FreeCache) does not count against the limit
+			var n int64
+			n, err = c.Increment(context.Background(), atLimit, 2)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), n)
+			_, err = c.Append(context.Background(), atLimit, "1")
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), atLimit, 30)
+			require.NoError(t, err)
+			_, err = c.ReleaseLock(context.Background(), atLimit, secret)
+			require.NoError(t, err)
+		})
+
+		t.Run(testCase.name+" - keys over the limit", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxKeyLength(maxLength))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.Set(context.Background(), overLimit, testValue)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			assert.Contains(t, err.Error(), "key is 11 bytes, the limit is 10 bytes")
+
+			require.ErrorIs(t, c.SetTTL(context.Background(), overLimit, testValue, time.Minute), ErrKeyTooLong)
+			require.ErrorIs(t, c.SetModel(context.Background(), overLimit, &genericStruct{}, 0), ErrKeyTooLong)
+			require.ErrorIs(t, c.GetModel(context.Background(), overLimit, &genericStruct{}), ErrKeyTooLong)
+			require.ErrorIs(t, c.Delete(context.Background(), overLimit), ErrKeyTooLong)
+			_, err = c.Get(context.Background(), overLimit)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+
+			// Lock methods
+			_, err = c.WriteLock(context.Background(), overLimit, 30)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			_, err = c.WriteLockWithSecret(context.Background(), overLimit, testValue, 30)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			_, _, err = c.TryWriteLock(context.Background(), overLimit, 30)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			_, err = c.WaitWriteLock(context.Background(), overLimit, 30, 1)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			_, err = c.ExtendLock(context.Background(), overLimit, testValue, 30)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+			_, err = c.ReleaseLock(context.Background(), overLimit, testValue)
+			require.ErrorIs(t, err, ErrKeyTooLong)
+		})
+
+		t.Run(testCase.name+" - no limit by default", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), strings.Repeat("k", 1024), testValue))
+		})
+	}
+}
+
+// TestClient_KeyValidator will test validating the keys (WithKeyValidator)
+func TestClient_KeyValidator(t *testing.T) {
+
+	errNamespace := errors.New("key must have a namespace")
+	var validated []string
+	requireNamespace := func(key string) error {
+		validated = append(validated, key)
+		if !strings.Contains(key, ":") {
+			return errNamespace
+		}
+		return nil
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - valid keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyValidator(requireNamespace))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			validated = nil
+			require.NoError(t, c.Set(context.Background(), " app:"+testKey+" ", testValue))
+			assert.Equal(t, []string{"app:" + testKey}, validated) // After trimming the spaces
+
+			var value string
+			value, err = c.Get(context.Background(), "app:"+testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			// The internal lock of the counters (FreeCache) is not validated
+			_, err = c.Increment(context.Background(), "app:"+testKey+"-counter", 1)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), "app:"+testKey, 30)
+			require.NoError(t, err)
+			_, err = c.ReleaseLock(context.Background(), "app:"+testKey, secret)
+			require.NoError(t, err)
+		})
+
+		t.Run(testCase.name+" - invalid keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyValidator(requireNamespace))
+			require.NoError(t, err)
+
+			err = c.Set(context.Background(), testKey, testValue)
+			require.ErrorIs(t, err, ErrInvalidKey)
+			require.ErrorIs(t, err, errNamespace)
+
+			var found bool
+			found, err = c.Exists(context.Background(), "app:"+testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+			_, err = c.Exists(context.Background(), testKey)
+			require.ErrorIs(t, err, errNamespace)
+
+			_, err = c.GetMulti(context.Background(), "app:"+testKey, testKey)
+			require.ErrorIs(t, err, errNamespace)
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.ErrorIs(t, err, errNamespace)
+			_, err = c.ReleaseLock(context.Background(), testKey, testValue)
+			require.ErrorIs(t, err, errNamespace)
+		})
+
+		t.Run(testCase.name+" - empty key is checked first", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyValidator(requireNamespace))
+			require.NoError(t, err)
+
+			validated = nil
+			require.ErrorIs(t, c.Set(context.Background(), "  ", testValue), ErrKeyRequired)
+			assert.Empty(t, validated)
+		})
+	}
+}
+
+// TestClient_DisableKeyTrimming will test using the keys as is (WithDisableKeyTrimming)
+func TestClient_DisableKeyTrimming(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - the spaces are part of the key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithDisableKeyTrimming())
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), " a", testValue+"-1"))
+			require.NoError(t, c.Set(context.Background(), "a", testValue+"-2"))
+			require.NoError(t, c.Set(context.Background(), "  ", testValue+"-3")) // Only spaces is a key
+
+			for key, expected := range map[string]string{" a": testValue + "-1", "a": testValue + "-2", "  ": testValue + "-3"} {
+				value, getErr := c.Get(context.Background(), key)
+				require.NoError(t, getErr)
+				assert.Equal(t, expected, value)
+			}
+
+			values, err := c.GetMulti(context.Background(), " a", "a", "a ")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{" a": testValue + "-1", "a": testValue + "-2"}, values)
+
+			require.NoError(t, c.Delete(context.Background(), " a"))
+			value, err := c.Get(context.Background(), "a")
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-2", value)
+
+			// An empty key is still required
+			require.ErrorIs(t, c.Set(context.Background(), "", testValue), ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - trimmed by default", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), " a", testValue+"-1"))
+			require.NoError(t, c.Set(context.Background(), "a", testValue+"-2"))
+
+			value, err := c.Get(context.Background(), " a")
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-2", value)
+			require.ErrorIs(t, c.Set(context.Background(), "  ", testValue), ErrKeyRequired)
+		})
+	}
+}
+
+// TestClient_ErrorContext will test that the errors include the operation, key and engine (and keep the sentinel errors)
+func TestClient_ErrorContext(t *testing.T) {
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - sentinel errors are wrapped", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.Get(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Equal(t, `cachestore: get key="" engine=`+testCase.engine.String()+`: key is empty and required`, err.Error())
+
+			err = c.Set(context.Background(), "", testValue)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), `cachestore: set key="" engine=`+testCase.engine.String()+`: `)
+
+			err = c.SetTTL(context.Background(), "", testValue, time.Minute)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), `cachestore: set_ttl key=""`)
+
+			err = c.Delete(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), `cachestore: delete key=""`)
+
+			err = c.SetModel(context.Background(), "", &genericStruct{}, 0)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), `cachestore: set_model key=""`)
+
+			err = c.GetModel(context.Background(), testKey, new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Equal(t, `cachestore: get_model key="test-key" engine=`+testCase.engine.String()+`: key not found`, err.Error())
+		})
+
+		t.Run(testCase.name+" - lock errors are wrapped", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.WriteLock(context.Background(), "", 30)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), `cachestore: write_lock key=""`)
+
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			_, err = c.ReleaseLock(context.Background(), testKey, "wrong-secret")
+			require.ErrorIs(t, err, cache.ErrLockMismatch)
+			assert.Contains(t, err.Error(), `cachestore: release_lock key="test-key" engine=`+testCase.engine.String())
+
+			_, err = c.ExtendLock(context.Background(), testKey, "wrong-secret", 30)
+			require.ErrorIs(t, err, cache.ErrLockMismatch)
+			assert.Contains(t, err.Error(), `cachestore: extend_lock key="test-key"`)
+
+			_, err = c.WaitWriteLock(context.Background(), testKey, 30, 0)
+			require.ErrorIs(t, err, ErrTTWCannotBeEmpty)
+			assert.Contains(t, err.Error(), `cachestore: wait_write_lock key="test-key"`)
+		})
+
+		t.Run(testCase.name+" - context errors are wrapped", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err = c.Set(ctx, testKey, testValue)
+			require.ErrorIs(t, err, ErrContextDone)
+			require.ErrorIs(t, err, context.Canceled)
+			assert.Contains(t, err.Error(), `cachestore: set key="test-key"`)
+		})
+
+		t.Run(testCase.name+" - no error is not wrapped", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			// A miss is not an error
+			value, err = c.Get(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			assert.Empty(t, value)
+		})
+	}
+
+	t.Run("the key is not prefixed", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		err = c.GetModel(context.Background(), testKey, new(genericStruct))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		assert.Contains(t, err.Error(), `key="test-key"`)
+	})
+
+	t.Run("errors.As finds the engine error", func(t *testing.T) {
+		c, _ := newFlakyRedisClient(t, 1)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		var opErr *net.OpError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "dial", opErr.Op)
+		assert.Contains(t, err.Error(), `cachestore: set key="test-key" engine=redis: `)
+	})
+}
+
+// TestClient_EngineNotSupported will test the operations that are not supported by the engine (ErrEngineNotSupported)
+func TestClient_EngineNotSupported(t *testing.T) {
+	t.Parallel()
+
+	t.Run("["+FreeCache.String()+"] - pattern operations", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.DeleteByPattern(context.Background(), "user:*")
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+		assert.Contains(t, err.Error(), "operation is not supported by the cachestore engine: "+FreeCache.String())
+
+		err = c.Scan(context.Background(), "user:*", func(string) error { return nil })
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+		assert.Contains(t, err.Error(), "engine: "+FreeCache.String())
+
+		// Same error (deprecated name)
+		require.ErrorIs(t, err, ErrNotSupported)
+	})
+
+	t.Run("["+Redis.String()+"] - FreeCache statistics", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.FreeCacheStats()
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+		assert.Contains(t, err.Error(), Redis.String())
+	})
+
+	t.Run("dependencies wrap the error", func(t *testing.T) {
+		require.ErrorIs(t, ErrDependenciesNotSupported, ErrEngineNotSupported)
+	})
+}
+
+// TestClient_MaxValueSize will test the option WithMaxValueSize() for the write methods
+func TestClient_MaxValueSize(t *testing.T) {
+
+	const maxSize = 64
+	atLimit := strings.Repeat("v", maxSize)
+	overLimit := strings.Repeat("v", maxSize+1)
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - values at the limit", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxValueSize(maxSize))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, atLimit))
+			require.NoError(t, c.SetTTL(context.Background(), testKey, atLimit, time.Minute))
+			require.NoError(t, c.SetBytes(context.Background(), testKey, []byte(atLimit)))
+			require.NoError(t, c.SetMulti(context.Background(), map[string]string{testKey: atLimit}))
+			_, _, err = c.GetSet(context.Background(), testKey, atLimit)
+			require.NoError(t, err)
+
+			// The serialized model is checked (quotes included)
+			require.NoError(t, c.SetModel(context.Background(), testKey, atLimit[2:], 0))
+		})
+
+		t.Run(testCase.name+" - values over the limit", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxValueSize(maxSize))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.Set(context.Background(), testKey, overLimit)
+			require.ErrorIs(t, err, ErrValueTooLarge)
+			assert.Contains(t, err.Error(), "value is 65 bytes, the limit is 64 bytes")
+
+			require.ErrorIs(t, c.SetTTL(context.Background(), testKey, overLimit, time.Minute), ErrValueTooLarge)
+			require.ErrorIs(t, c.SetBytes(context.Background(), testKey, []byte(overLimit)), ErrValueTooLarge)
+			require.ErrorIs(t, c.SetModel(context.Background(), testKey, atLimit, 0), ErrValueTooLarge)
+			require.ErrorIs(t, c.SetModelMulti(context.Background(), map[string]interface{}{testKey: atLimit}, 0), ErrValueTooLarge)
+			require.ErrorIs(t, c.SetMulti(context.Background(), map[string]string{
+				testKey: testValue, testKey + "-2": overLimit,
+			}), ErrValueTooLarge)
+			_, err = c.ReplaceModel(context.Background(), testKey, atLimit, 0)
+			require.ErrorIs(t, err, ErrValueTooLarge)
+			_, err = c.SetModelIfChanged(context.Background(), testKey, atLimit, 0)
+			require.ErrorIs(t, err, ErrValueTooLarge)
+			_, _, err = c.GetSet(context.Background(), testKey, overLimit)
+			require.ErrorIs(t, err, ErrValueTooLarge)
+
+			// Nothing was written
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - the compressed size is checked", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), testCase.opts, WithMaxValueSize(maxSize),
+				WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, strings.Repeat("v", maxSize*4)))
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Len(t, value, maxSize*4)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mocked] - nothing is sent over the limit", func(t *testing.T) {
+		c, conn := newMockRedisClient(t, WithMaxValueSize(maxSize))
+
+		setCmd := conn.Command(cache.SetCommand, testKey, atLimit).Expect("OK")
+		require.NoError(t, c.Set(context.Background(), testKey, atLimit))
+		assert.True(t, setCmd.Called)
+
+		overCmd := conn.Command(cache.SetCommand, testKey, overLimit).Expect("OK")
+		require.ErrorIs(t, c.Set(context.Background(), testKey, overLimit), ErrValueTooLarge)
+		assert.False(t, overCmd.Called)
+	})
+}
+
+// TestClient_KeyPrefix will test using a key prefix (namespace) for all keys
+func TestClient_KeyPrefix(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - clients with different prefixes are isolated", func(t *testing.T) {
+			opts := sharedClientOpts(testCase)
+
+			first, err := NewClient(context.Background(),
+				opts, WithKeyPrefix("first:"),
+			)
+			require.NoError(t, err)
+
+			var second ClientInterface
+			second, err = NewClient(context.Background(),
+				opts, WithKeyPrefix("second:"),
+			)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = first.EmptyCache(context.Background())
+				_ = second.EmptyCache(context.Background())
+			}()
+
+			err = first.Set(context.Background(), testKey, testValue+"-first")
+			require.NoError(t, err)
+
+			err = first.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0)
+			require.NoError(t, err)
+
+			// The other prefix cannot read the keys
+			var val string
+			val, err = second.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "", val)
+
+			err = second.GetModel(context.Background(), testKey+"-model", new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+
+			err = second.Set(context.Background(), testKey, testValue+"-second")
+			require.NoError(t, err)
+
+			val, err = first.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-first", val)
+
+			val, err = second.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-second", val)
+
+			// The stored key has the prefix
+			if testCase.engine == Redis {
+				val, err = cache.Get(context.Background(), first.Redis(), "first:"+testKey)
+				require.NoError(t, err)
+				assert.Equal(t, testValue+"-first", val)
+			}
+		})
+
+		t.Run(testCase.name+" - get multi returns keys without the prefix", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyPrefix("prefix:"))
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetMulti(context.Background(), map[string]string{testKey: testValue})
+			require.NoError(t, err)
+
+			var values map[string]string
+			values, err = c.GetMulti(context.Background(), testKey, testKey+"-missing")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{testKey: testValue}, values)
+		})
+
+		t.Run(testCase.name+" - locks use the prefix", func(t *testing.T) {
+			opts := sharedClientOpts(testCase)
+
+			first, err := NewClient(context.Background(),
+				opts, WithKeyPrefix("first:"),
+			)
+			require.NoError(t, err)
+
+			var second ClientInterface
+			second, err = NewClient(context.Background(),
+				opts, WithKeyPrefix("second:"),
+			)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = first.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			// Same lock key in a different namespace is not locked
+			var otherSecret string
+			otherSecret, err = second.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			var released bool
+			released, err = first.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			released, err = second.ReleaseLock(context.Background(), testKey, otherSecret)
+			require.NoError(t, err)
+			assert.True(t, released)
+		})
+
+		t.Run(testCase.name+" - empty cache only removes the prefixed keys", func(t *testing.T) {
+			opts := sharedClientOpts(testCase)
+
+			plain, err := NewClient(context.Background(), opts)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = plain.EmptyCache(context.Background())
+			}()
+
+			var prefixed ClientInterface
+			prefixed, err = NewClient(context.Background(),
+				opts, WithKeyPrefix("prefix:"),
+			)
+			require.NoError(t, err)
+
+			err = plain.Set(context.Background(), testKey, testValue)
+			require.NoError(t, err)
+
+			for i := 0; i < 5; i++ {
+				err = prefixed.Set(context.Background(), testKey+strconv.Itoa(i), testValue)
+				require.NoError(t, err)
+			}
+
+			err = prefixed.EmptyCache(context.Background())
+			require.NoError(t, err)
+
+			var found bool
+			found, err = prefixed.Exists(context.Background(), testKey+"0")
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			found, err = plain.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, found)
+		})
+	}
+}
+
+// sharedClientOpts will return the options for several clients sharing the same storage
+func sharedClientOpts(testCase cacheTestCase) ClientOps {
+	if testCase.engine == FreeCache {
+		return withClientOps(
+			WithFreeCacheConnection(loadFreeCache(DefaultCacheSize, DefaultGCPercent, testCase.clock)),
+			WithClock(testCase.clock),
+		)
+	}
+	return testCase.opts
+}
+
+// withClientOps will combine several options into one
+func withClientOps(opts ...ClientOps) ClientOps {
+	return func(c *clientOptions) {
+		for _, opt := range opts {
+			opt(c)
+		}
+	}
+}
+
 // getInMemoryTestCases will return all the cache engine test cases for in-memory testing
 func getInMemoryTestCases(t *testing.T) (cases []cacheTestCase) {
+	clock := &testClock{}
 	cases = []cacheTestCase{
 		{
+			clock:  clock,
 			name:   "[" + FreeCache.String() + "] [in-memory]",
 			engine: FreeCache,
-			opts:   WithFreeCache(),
+			opts:   withClientOps(WithFreeCache(), WithClock(clock)),
 			redis:  nil,
 		},
 	}
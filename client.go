@@ -2,11 +2,17 @@ package cachestore
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 	zLogger "github.com/mrz1836/go-logger"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
 )
 
 type (
@@ -18,13 +24,66 @@ type (
 
 	// clientOptions holds all the configuration for the client
 	clientOptions struct {
-		debug           bool                        // For extra logs and additional debug information
-		engine          Engine                      // Cachestore engine (redis or mcache)
-		freeCache       *freecache.Cache            // Driver (client) for local in-memory storage
-		logger          zLogger.GormLoggerInterface // Internal logging
-		newRelicEnabled bool                        // If NewRelic is enabled (parent application)
-		redis           *cache.Client               // Current redis client (read & write)
-		redisConfig     *RedisConfig                // Configuration for a new redis client
+		allowUnsafeFlush     bool                        // Flush without a key prefix removes every key (see: WithAllowUnsafeFlush)
+		clock                Clock                       // Current time for the expirations (system time by default)
+		compression          CompressionType             // Compression for values (none by default)
+		compressionThreshold int                         // Minimum size (bytes) of a value before compressing
+		debug                bool                        // For extra logs and additional debug information
+		defaultTTL           time.Duration               // TTL for values stored without a TTL (no expiration if zero)
+		dependencyWarned     atomic.Int64                // Time of the last warning about the dependencies (unix nano)
+		dependencies         *dependencyIndex            // Index of the keys stored with each dependency (FreeCache)
+		disableKeyTrimming   bool                        // Use the keys as is, without trimming the spaces (see: WithDisableKeyTrimming)
+		engine               Engine                      // Cachestore engine (redis or mcache)
+		expireInterval       time.Duration               // Time between the sweeps of the expired FreeCache entries (disabled if zero)
+		fallback             *freecache.Cache            // Local cache read when redis cannot be reached (see: WithEngineFallback)
+		freeCache            *freecache.Cache            // Driver (client) for local in-memory storage
+		freeCacheGCPercent   int                         // Go GC percent set when creating a new FreeCache
+		freeCacheLimit       int                         // Max size (bytes) of a FreeCache entry (0 if unknown, existing connection)
+		freeCacheOwned       bool                        // The FreeCache was created by the client (cleared on Close)
+		freeCacheSize        int                         // Size (bytes) of a new FreeCache (freecache or the local tier)
+		healthCheck          *healthChecker              // Background ping of the engine (if enabled, see: WithHealthCheck)
+		healthCheckInterval  time.Duration               // Time between the health check probes (disabled if zero)
+		hooks                Hooks                       // Callbacks for the cache operations (hit, miss, set and error)
+		keyHasher            KeyHasher                   // Transforms the keys before the key prefix (if enabled, see: WithKeyHashing)
+		keyPrefix            string                      // Prefix (namespace) for all keys, dependencies and locks
+		keyValidator         func(key string) error      // Validates each key before the operation (if set, see: WithKeyValidator)
+		loaderLockTTL        time.Duration               // Lock TTL to serialize the loaders (disabled if zero)
+		loaders              *singleflight.Group         // Loaders in flight on this node (GetOrSetModel)
+		locks                *lockIndex                  // Index of the locks held (FreeCache, see: ListLocks)
+		localTTL             time.Duration               // Max time a value is kept in the local tier (tiered)
+		lockBackoff          lockBackoff                 // Delay between the attempts of WaitWriteLock
+		lockNamespace        string                      // Prefix of the lock keys (after the key prefix, see: WithLockNamespace)
+		lockSecretBytes      int                         // Random bytes of a generated lock secret (hex is twice the length)
+		logger               zLogger.GormLoggerInterface // Internal logging
+		maxDependencyKeys    int                         // Max keys linked to a dependency (no limit if zero, see: WithMaxDependencyKeys)
+		maxKeyLength         int                         // Max length (bytes) of a key (no limit if zero)
+		maxValueSize         int                         // Max size (bytes) of a stored value (no limit if zero)
+		memcached            *memcache.Client            // Current memcached client (read & write)
+		memcachedConfig      *MemcachedConfig            // Configuration for a new memcached client
+		metrics              *metrics                    // Prometheus collectors (if enabled)
+		modelLoaders         []modelLoader               // Read-through loaders of GetModel by key prefix (see: WithModelLoader)
+		modelVersioning      bool                        // Store and check the version of the models (see: WithModelVersioning)
+		newRelicEnabled      bool                        // If NewRelic is enabled (parent application)
+		onEvicted            func(countDelta int64)      // Called with the FreeCache evictions (see: WithOnEvicted)
+		observabilityContext bool                        // Record the operations into the context (see: WithObservabilityContext)
+		operationTimeout     time.Duration               // Timeout for each operation (no timeout if zero)
+		panicRecovery        bool                        // Return the panics of the operations as errors (see: WithPanicRecovery)
+		reads                *singleflight.Group         // Reads in flight on this node (if enabled, see: WithSingleFlight)
+		redis                *cache.Client               // Current redis client (read & write)
+		redisConfig          *RedisConfig                // Configuration for a new redis client
+		redisDatabase        *int                        // Database selected on each new connection (see: WithRedisDatabase)
+		registerer           prometheus.Registerer       // Prometheus registerer for the metrics (if enabled)
+		replicaNext          atomic.Uint64               // Next read replica (round-robin)
+		replicas             []*cache.Client             // Read replicas (reads only, see: RedisConfig.ReadReplicaURLs)
+		retryAttempts        int                         // Retries of a transient redis error (no retries if zero)
+		retryBackoff         time.Duration               // Delay before the first retry (doubles after each retry)
+		scanCount            int                         // Keys per SCAN iteration (redis)
+		serializer           Serializer                  // Serializer for models (JSON by default)
+		stats                *statsCounters              // Cache statistics (hits, misses, sets and deletes)
+		strictMisses         bool                        // Get returns ErrKeyNotFound on a miss (see: WithStrictMisses)
+		sweeper              *freeCacheSweeper           // Background sweeper of the expired FreeCache entries and evictions (if enabled)
+		ttlJitter            time.Duration               // Max random offset added to the TTLs (disabled if zero, see: WithTTLJitter)
+		valueEncoding        ValueEncoding               // Encoding for values, after the compression (none by default)
 	}
 )
 
@@ -47,6 +106,37 @@ func NewClient(ctx context.Context, opts ...ClientOps) (ClientInterface, error)
 		client.options.logger = zLogger.NewGormLogger(client.IsDebug(), 4)
 	}
 
+	// Validate the compression type
+	if !client.options.compression.IsValid() {
+		return nil, ErrUnsupportedCompression
+	}
+
+	// Validate the value encoding
+	if !client.options.valueEncoding.IsValid() {
+		return nil, ErrUnsupportedValueEncoding
+	}
+
+	// Validate the redis database (see: WithRedisDatabase)
+	if db := client.options.redisDatabase; db != nil && *db < 0 {
+		return nil, fmt.Errorf("%w: database cannot be negative (%d)", ErrInvalidRedisConfig, *db)
+	}
+
+	// Validate the FreeCache size
+	if client.options.freeCacheSize < MinFreeCacheSize {
+		return nil, fmt.Errorf(
+			"%w: %d bytes is below the minimum of %d bytes", ErrInvalidFreeCacheSize,
+			client.options.freeCacheSize, MinFreeCacheSize,
+		)
+	}
+
+	// Register the Prometheus metrics (if enabled)
+	if client.options.registerer != nil {
+		var err error
+		if client.options.metrics, err = newMetrics(client.options.registerer); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrMetricsRegistration, err)
+		}
+	}
+
 	// EMPTY! Engine was NOT set, show warning and use in-memory cache
 	if client.Engine().IsEmpty() {
 		client.options.logger.Warn(ctx, "cachestore engine was not set, using in-memory FreeCache")
@@ -57,23 +147,72 @@ func NewClient(ctx context.Context, opts ...ClientOps) (ClientInterface, error)
 	ctx = client.options.getTxnCtx(ctx)
 
 	// Load cache based on engine
-	if client.Engine() == Redis {
+	if client.Engine().usesRedis() {
 
 		// Only if we don't already have an existing client
 		if client.options.redis == nil {
+			if client.options.redisConfig != nil { // Select the database (see: WithRedisDatabase)
+				client.options.redisConfig.database = client.options.redisDatabase
+			}
 			var err error
 			if client.options.redis, err = loadRedisClient(
 				ctx, client.options.redisConfig, client.options.newRelicEnabled,
 			); err != nil {
 				return nil, err
 			}
+
+			// Connect to the read replicas (if set)
+			if client.options.redisConfig.isReplicated() {
+				if client.options.replicas, err = loadRedisReplicas(
+					ctx, client.options.redisConfig, client.options.newRelicEnabled,
+				); err != nil {
+					client.options.redis.Close()
+					return nil, err
+				}
+			}
+		} else if client.options.redisDatabase != nil { // Select the database of the existing connection
+			pool := redigoPool(client.options.redis)
+			if pool == nil {
+				return nil, fmt.Errorf("%w: the database can only be selected on a *redis.Pool connection", ErrInvalidRedisConfig)
+			}
+			selectDatabase(pool, *client.options.redisDatabase)
 		}
-	} else if client.Engine() == FreeCache {
+	} else if client.Engine() == Memcached {
+
+		// Only if we don't already have an existing client
+		if client.options.memcached == nil {
+			var err error
+			if client.options.memcached, err = loadMemcachedClient(
+				ctx, client.options.memcachedConfig, client.options.newRelicEnabled,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Load the local cache (FreeCache or the local tier)
+	if client.Engine() == FreeCache || client.Engine() == Tiered {
 
 		// Only if we don't already have an existing client
 		if client.options.freeCache == nil {
-			client.options.freeCache = loadFreeCache(DefaultCacheSize, DefaultGCPercent)
+			client.options.freeCache = loadFreeCache(
+				client.options.freeCacheSize, client.options.freeCacheGCPercent, client.options.clock,
+			)
+			client.options.freeCacheLimit = freeCacheEntryLimit(client.options.freeCacheSize)
+			client.options.freeCacheOwned = true
 		}
+
+		// Start sweeping the expired entries and checking the evictions (if enabled)
+		if client.options.expireInterval > 0 || client.options.onEvicted != nil {
+			client.options.sweeper = startFreeCacheSweeper(
+				client.options.freeCache, client.options.expireInterval, client.options.clock, client.options.onEvicted,
+			)
+		}
+	}
+
+	// Start probing the engine (if enabled)
+	if client.options.healthCheckInterval > 0 {
+		client.options.healthCheck = startHealthChecker(client.options.healthCheckInterval, client.Ping)
 	}
 
 	// Return the client
@@ -81,21 +220,42 @@ func NewClient(ctx context.Context, opts ...ClientOps) (ClientInterface, error)
 }
 
 // Close will close the client and any open connections
+//
+// A FreeCache created by the client is cleared, an existing FreeCache (WithFreeCacheConnection or WithTieredCache)
+// is owned by the caller and is kept as is (it can be shared by several clients)
 func (c *Client) Close(ctx context.Context) {
 	if txn := newrelic.FromContext(ctx); txn != nil {
 		defer txn.StartSegment("close_cachestore").End()
 	}
 	if c != nil && c.options != nil {
-		if c.Engine() == Redis {
+		if c.options.sweeper != nil { // Stop sweeping before clearing the cache
+			c.options.sweeper.stop()
+			c.options.sweeper = nil
+		}
+		if c.options.healthCheck != nil { // Stop probing before closing the connections
+			c.options.healthCheck.stop()
+			c.options.healthCheck = nil
+		}
+		if c.Engine().usesRedis() {
 			if c.options.redis != nil {
 				c.options.redis.Close()
 			}
 			c.options.redis = nil
-		} else if c.Engine() == FreeCache {
-			if c.options.freeCache != nil {
+			closeRedisReplicas(c.options.replicas)
+			c.options.replicas = nil
+		} else if c.Engine() == Memcached {
+			if c.options.memcached != nil {
+				_ = c.options.memcached.Close()
+			}
+			c.options.memcached = nil
+		}
+		if c.Engine() == FreeCache || c.Engine() == Tiered {
+			if c.options.freeCache != nil && c.options.freeCacheOwned { // An existing connection is shared (not cleared)
 				c.options.freeCache.Clear()
 			}
 			c.options.freeCache = nil
+			c.options.dependencies.clear()
+			c.options.locks.clear()
 		}
 		c.options.engine = Empty
 	}
@@ -131,19 +291,142 @@ func (c *Client) RedisConfig() *RedisConfig {
 	return c.options.redisConfig
 }
 
+// Memcached will return the Memcached client if found
+func (c *Client) Memcached() *memcache.Client {
+	return c.options.memcached
+}
+
+// MemcachedConfig will return the Memcached config client if found
+func (c *Client) MemcachedConfig() *MemcachedConfig {
+	return c.options.memcachedConfig
+}
+
 // FreeCache will return the FreeCache client if found
 func (c *Client) FreeCache() *freecache.Cache {
 	return c.options.freeCache
 }
 
+// Ping will check that the cache engine is reachable
+//
+// Redis fires a PING command, Memcached checks each server and FreeCache is always available
+func (c *Client) Ping(ctx context.Context) error {
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	if c.Engine().usesRedis() && c.options.redis != nil {
+		return cache.Ping(ctx, c.options.redis)
+	} else if c.Engine() == Memcached && c.options.memcached != nil {
+		return c.options.memcached.Ping()
+	} else if c.Engine() == FreeCache && c.options.freeCache != nil {
+		return nil
+	}
+	return ErrClientClosed
+}
+
+// Flush will remove only the keys of this client (the keys under the key prefix, see: WithKeyPrefix)
+//
+// Without a key prefix every key is removed (FLUSHDB on redis, FlushAll on memcached) only if
+// WithAllowUnsafeFlush is set, otherwise ErrUnsafeFlush is returned and nothing is removed
+// NOTE: memcached cannot list keys, so a key prefix is not supported (ErrEngineNotSupported)
+func (c *Client) Flush(ctx context.Context) error {
+	if len(c.options.keyPrefix) == 0 && !c.options.allowUnsafeFlush {
+		return ErrUnsafeFlush
+	}
+	return c.EmptyCache(ctx)
+}
+
+// Clear will remove every entry of the FreeCache engine and reset the statistics, the client stays usable
+//
+// All the entries are removed (the key prefix is ignored), including the locks and the dependency keys,
+// the statistics (Stats and FreeCacheStats) are reset to zero (see: ResetStats)
+// NOTE: only the FreeCache engine is supported (ErrEngineNotSupported), use EmptyCache for the other engines
+func (c *Client) Clear(ctx context.Context) error {
+
+	// Stop if the context is done
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Only the FreeCache engine
+	if c.Engine() != FreeCache || c.options.freeCache == nil {
+		return c.engineNotSupported()
+	}
+	c.options.freeCache.Clear()
+	c.options.dependencies.clear()
+	c.options.locks.clear()
+	c.ResetStats()
+	return nil
+}
+
+// EmptyCachePattern will empty the keys matching the pattern (glob style: tenant:123:*) and return the total removed
+//
+// Redis uses a cursor based SCAN (never KEYS) with a DEL for each batch, the context is checked before each SCAN
+// The key prefix is added to the pattern (see: DeleteByPattern)
+// NOTE: freecache and memcached cannot match keys by pattern (ErrEngineNotSupported)
+func (c *Client) EmptyCachePattern(ctx context.Context, pattern string) (int, error) {
+	return c.DeleteByPattern(ctx, pattern)
+}
+
 // EmptyCache will empty the cache entirely
 //
-// CAUTION: this will dump all the stored cache
+// If a key prefix is set, only the keys under the prefix are removed (SCAN and DEL on redis)
+// CAUTION: without a key prefix this will dump all the stored cache, for redis that is
+// every key in the database (FLUSHDB), including the keys of other apps sharing the database
+// NOTE: memcached cannot list keys, so a key prefix is not supported (ErrEngineNotSupported)
+// Returns ErrClientClosed after Close
 func (c *Client) EmptyCache(ctx context.Context) error {
-	if c.Engine() == Redis && c.options.redis != nil {
-		return cache.DestroyCache(ctx, c.options.redis)
-	} else if c.options.freeCache != nil {
+
+	// Stop if the client is closed, or the context is done
+	if err := c.checkClosed(); err != nil {
+		return err
+	} else if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Remove the fallback copies (only the keys of this client, see: WithEngineFallback)
+	c.clearFallback()
+
+	// Only remove the keys under the prefix
+	if len(c.options.keyPrefix) > 0 {
+		if c.Engine() == Memcached {
+			return c.engineNotSupported()
+		} else if c.Engine().usesRedis() && c.options.redis != nil {
+			if _, err := deleteByPatternRedis(
+				ctx, c.options.redis, escapePattern(c.options.keyPrefix)+"*", c.options.scanCount, nil,
+			); err != nil {
+				return err
+			}
+		}
+		if c.Engine() != Redis && c.options.freeCache != nil { // FreeCache or the local tier
+			deleteByPrefixFreeCache(c.options.freeCache, c.options.keyPrefix)
+		}
+		return nil
+	}
+
+	if c.Engine().usesRedis() && c.options.redis != nil {
+		var err error
+		if cluster, ok := redisCluster(c.options.redis); ok { // Flush each master node
+			err = flushCluster(cluster)
+		} else {
+			_, err = doRedis(ctx, c.options.redis, flushDBCommand)
+		}
+		if err != nil {
+			return err
+		}
+	} else if c.Engine() == Memcached && c.options.memcached != nil {
+		return c.options.memcached.FlushAll()
+	}
+	if c.Engine() != Redis && c.options.freeCache != nil { // FreeCache or the local tier
 		c.options.freeCache.Clear()
+		c.options.dependencies.clear()
+		c.options.locks.clear()
 	}
 	return nil
 }
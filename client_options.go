@@ -3,11 +3,15 @@ package cachestore
 import (
 	"context"
 	"strings"
+	"time"
 
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 	zLogger "github.com/mrz1836/go-logger"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
 )
 
 // ClientOps allow functional options to be supplied
@@ -21,11 +25,27 @@ func defaultClientOptions() *clientOptions {
 
 	// Set the default options
 	return &clientOptions{
-		debug:           false,
-		engine:          Empty,
-		freeCache:       nil,
-		newRelicEnabled: false,
-		redisConfig:     &RedisConfig{},
+		clock:                realClock{},
+		compression:          CompressionNone,
+		compressionThreshold: DefaultCompressionThreshold,
+		valueEncoding:        ValueEncodingNone,
+		debug:                false,
+		dependencies:         newDependencyIndex(maxDependencyLinks),
+		engine:               Empty,
+		freeCache:            nil,
+		freeCacheGCPercent:   DefaultGCPercent,
+		freeCacheSize:        DefaultCacheSize,
+		loaders:              &singleflight.Group{},
+		locks:                newLockIndex(maxLockIndexKeys),
+		lockBackoff:          lockBackoff{factor: 1, initial: lockRetrySleepTime, maxDelay: lockRetrySleepTime},
+		lockNamespace:        DefaultLockNamespace,
+		lockSecretBytes:      DefaultLockSecretBytes,
+		memcachedConfig:      &MemcachedConfig{},
+		newRelicEnabled:      false,
+		redisConfig:          &RedisConfig{},
+		scanCount:            defaultScanCount,
+		serializer:           &JSONSerializer{},
+		stats:                &statsCounters{},
 	}
 }
 
@@ -41,12 +61,294 @@ func (c *clientOptions) getTxnCtx(ctx context.Context) context.Context {
 }
 
 // WithNewRelic will enable the NewRelic wrapper
+//
+// Get, Set, Delete, SetModel, GetModel and the lock methods record a datastore segment (Cachestore product,
+// the engine is the collection) if the ctx has a txn
 func WithNewRelic() ClientOps {
 	return func(c *clientOptions) {
 		c.newRelicEnabled = true
 	}
 }
 
+// WithPrometheus will enable the Prometheus metrics (operations by result, and the latency of each operation)
+//
+// Clients using the same registerer share the same collectors
+func WithPrometheus(registerer prometheus.Registerer) ClientOps {
+	return func(c *clientOptions) {
+		if registerer != nil {
+			c.registerer = registerer
+		}
+	}
+}
+
+// WithObservabilityContext will record each cache operation (operation, key, result and latency) into the context
+//
+// Only a context with a collector is recorded (see: ContextWithCacheEvents), read them using CacheEventsFromContext
+// The collector is safe for concurrent use (a request that fans out can share the context)
+func WithObservabilityContext() ClientOps {
+	return func(c *clientOptions) {
+		c.observabilityContext = true
+	}
+}
+
+// WithPanicRecovery will return a panic inside an operation as an error (ErrInternalPanic) instead of crashing
+//
+// ie: a custom serializer that panics on a nil model, the recovered value is part of the error (and wrapped if an error)
+// Disabled by default (fail fast), the panics propagate to the caller
+func WithPanicRecovery() ClientOps {
+	return func(c *clientOptions) {
+		c.panicRecovery = true
+	}
+}
+
+// WithModelVersioning will store the version of the models with the value and reject the reads of another version
+//
+// The version is set per call using ContextWithModelVersion, or the CacheVersion() method of the model (see: VersionedModel)
+// A stored version that does not match (including unversioned values) returns ErrModelVersionMismatch, which is a miss
+// (GetOrSetModel and the model loaders load the model again)
+// Disabled by default, the models are stored without a version
+func WithModelVersioning() ClientOps {
+	return func(c *clientOptions) {
+		c.modelVersioning = true
+	}
+}
+
+// WithHooks will set the callbacks for the cache operations (OnHit, OnMiss, OnSet and OnError)
+//
+// Hooks run synchronously on the calling goroutine, offload any heavy work (see: Hooks)
+func WithHooks(hooks Hooks) ClientOps {
+	return func(c *clientOptions) {
+		c.hooks = hooks
+	}
+}
+
+// WithLockBackoff will set the delay between the attempts of WaitWriteLock (exponential backoff with jitter)
+//
+// The delay starts at initial and is multiplied by the factor after each attempt (up to maxDelay)
+// Each delay is randomized between half and the full delay to spread out the contending clients
+// Invalid values are ignored (default: every 10 milliseconds, no backoff)
+func WithLockBackoff(initial, maxDelay time.Duration, factor float64) ClientOps {
+	return func(c *clientOptions) {
+		if initial <= 0 {
+			return
+		}
+		if maxDelay < initial {
+			maxDelay = initial
+		}
+		if factor < 1 {
+			factor = 1
+		}
+		c.lockBackoff = lockBackoff{
+			factor:   factor,
+			initial:  initial,
+			jitter:   true,
+			maxDelay: maxDelay,
+		}
+	}
+}
+
+// WithLockSecretBytes will set the number of random bytes of the secret generated by WriteLock and TryWriteLock
+//
+// The secret is hex encoded (2*n characters), values below MinLockSecretBytes are ignored (default: 32)
+func WithLockSecretBytes(n int) ClientOps {
+	return func(c *clientOptions) {
+		if n >= MinLockSecretBytes {
+			c.lockSecretBytes = n
+		}
+	}
+}
+
+// WithLockNamespace will set the prefix of the lock keys (after the key prefix, default: "lock:")
+//
+// The locks and the data keys never collide (ie: a lock and a key with the same name), ListLocks removes the namespace
+// An empty namespace uses the lock keys as is (the lock keys before the namespace, ie: during a rolling upgrade)
+func WithLockNamespace(namespace string) ClientOps {
+	return func(c *clientOptions) {
+		c.lockNamespace = namespace
+	}
+}
+
+// WithLoaderLock will serialize the loaders of GetOrSet and GetOrSetModel for the same key using a write lock (thundering herd)
+//
+// The ttl is the max time of a loader (the lock expires and other callers stop waiting), rounded down to seconds
+// Values under one second are ignored (default: disabled), memcached does not support locks and is never serialized
+func WithLoaderLock(ttl time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if ttl >= time.Second {
+			c.loaderLockTTL = ttl
+		}
+	}
+}
+
+// WithModelLoader will load the model of a missing key under the prefix on GetModel, store it and return it (read-through)
+//
+// The loader returns the model and the TTL to store it (a zero TTL uses the default TTL, see: WithDefaultTTL)
+// Several prefixes can be registered (the longest matching prefix is used), an empty prefix matches all the keys
+// A loader error is returned and nothing is stored, concurrent misses share a single loader call (see: GetOrSetModel)
+func WithModelLoader(prefix string, loader ModelLoader) ClientOps {
+	return func(c *clientOptions) {
+		if loader == nil {
+			return
+		}
+		for i := range c.modelLoaders {
+			if c.modelLoaders[i].prefix == prefix {
+				c.modelLoaders[i].load = loader
+				return
+			}
+		}
+		c.modelLoaders = append(c.modelLoaders, modelLoader{load: loader, prefix: prefix})
+	}
+}
+
+// WithStrictMisses will return ErrKeyNotFound from Get when the key does not exist (default: empty string and no error)
+//
+// A key that holds an empty string still returns the empty string and no error (a miss and an empty value are different)
+func WithStrictMisses() ClientOps {
+	return func(c *clientOptions) {
+		c.strictMisses = true
+	}
+}
+
+// WithSingleFlight will coalesce the concurrent reads of the same key (Get and GetModel), only one backend round trip is made
+//
+// The other callers wait and share the result (value, miss or error), the statistics, metrics and hooks still count each call
+// Useful for hot keys on redis or memcached (a FreeCache read is already local)
+func WithSingleFlight() ClientOps {
+	return func(c *clientOptions) {
+		c.reads = &singleflight.Group{}
+	}
+}
+
+// WithDefaultTTL will set the TTL for values stored without a TTL (Set, SetBytes, SetMulti)
+//
+// SetTTL and SetModel use the default when called with a zero TTL, an explicit TTL always overrides the default
+// Once set, values cannot be stored without an expiration (use a long TTL instead)
+// Values of zero or less are ignored (default: no expiration)
+func WithDefaultTTL(ttl time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if ttl > 0 {
+			c.defaultTTL = ttl
+		}
+	}
+}
+
+// WithTTLJitter will add a random offset (0 to maxJitter) to the TTLs to spread the expirations (stampede)
+//
+// Applies to SetTTL (and SetInt, SetDuration, SetTime) and SetModel, including the default TTL (see: WithDefaultTTL)
+// The TTL is never reduced, values without an expiration are not changed, values of zero or less are ignored
+// NOTE: the stored expiration includes the jitter (ie: GetModelWithTTL returns the jittered TTL)
+func WithTTLJitter(maxJitter time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if maxJitter > 0 {
+			c.ttlJitter = maxJitter
+		}
+	}
+}
+
+// WithDisableKeyTrimming will use the keys as is, the leading and trailing spaces are part of the key
+//
+// By default the spaces are trimmed (" a" and "a" are the same key), only an empty key returns ErrKeyRequired once disabled
+// NOTE: memcached does not allow spaces in the keys
+func WithDisableKeyTrimming() ClientOps {
+	return func(c *clientOptions) {
+		c.disableKeyTrimming = true
+	}
+}
+
+// WithMaxDependencyKeys will limit the number of keys linked to a dependency, the oldest keys are removed from it
+//
+// FreeCache uses the order of the index, Redis keeps the order in a sorted set next to each dependency set
+// (depend-order:, a script after each write with dependencies, a command per step for a cluster)
+// Redis keys linked before the limit was set have no order and are never removed from the dependency
+// A warning is logged when keys are removed (at most once per minute)
+// CAUTION: a key removed from the dependency is still cached, DeleteDependency does not remove it (it expires using the TTL)
+// Values of zero or less are ignored (default: no limit)
+func WithMaxDependencyKeys(maxKeys int) ClientOps {
+	return func(c *clientOptions) {
+		if maxKeys > 0 {
+			c.maxDependencyKeys = maxKeys
+		}
+	}
+}
+
+// WithMaxKeyLength will set the max length (bytes) of a key, longer keys return ErrKeyTooLong
+//
+// The length is checked after trimming the spaces and the key hashing, before adding the key prefix (keys and lock keys)
+// Values of zero or less are ignored (default: no limit)
+func WithMaxKeyLength(length int) ClientOps {
+	return func(c *clientOptions) {
+		if length > 0 {
+			c.maxKeyLength = length
+		}
+	}
+}
+
+// WithKeyValidator will set a function to validate each key before the operation (ie: require a namespace)
+//
+// The key is validated after trimming the spaces and the empty check, before the key hashing and the key prefix (keys and lock keys)
+// A non-nil error stops the operation, the error is wrapped with ErrInvalidKey
+// A nil function is ignored (default: no extra validation)
+func WithKeyValidator(fn func(key string) error) ClientOps {
+	return func(c *clientOptions) {
+		if fn != nil {
+			c.keyValidator = fn
+		}
+	}
+}
+
+// WithMaxValueSize will set the max size (bytes) of a value, larger values return ErrValueTooLarge (all engines)
+//
+// The size is checked after the serializer and compression (the stored bytes), nothing is written if exceeded
+// Applies to Set, SetTTL, SetBytes, SetMulti, GetSet and the models (SetModel, SetModelMulti, ReplaceModel...)
+// NOTE: Append is not checked (the size is only known after the append in redis)
+// Values of zero or less are ignored (default: no limit, FreeCache still limits an entry to 1/1024 of the cache size)
+func WithMaxValueSize(size int) ClientOps {
+	return func(c *clientOptions) {
+		if size > 0 {
+			c.maxValueSize = size
+		}
+	}
+}
+
+// WithOperationTimeout will set a timeout for each operation (unless the context has an earlier deadline)
+//
+// Applies to the reads, writes, deletes, locks and Ping (not EmptyCache, DeleteByPattern or Scan)
+// WaitWriteLock applies the timeout to each attempt, the wait is set using the TTW
+func WithOperationTimeout(timeout time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if timeout > 0 {
+			c.operationTimeout = timeout
+		}
+	}
+}
+
+// WithRetry will retry the redis commands that fail with a transient error (connection refused, EOF or timeout)
+//
+// Each failed command is retried up to attempts times, waiting the backoff before the first retry (doubled after each)
+// Server errors, a lock mismatch or a missing key are never retried, the retries stop when the context is done
+// Applies to the reads, writes and deletes (not counters, Append, GetSet, locks or the bulk operations that cannot
+// be repeated safely), zero or less attempts is ignored (no retries by default)
+func WithRetry(attempts int, backoff time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if attempts > 0 {
+			c.retryAttempts = attempts
+			c.retryBackoff = max(backoff, 0)
+		}
+	}
+}
+
+// WithClock will set the clock used for the expirations (ie: a fake clock to advance time instantly in tests)
+//
+// Applies to the FreeCache created by the client (locks and TTLs) and the TTW of WaitWriteLock
+// NOTE: redis and memcached expire keys using the server time
+func WithClock(clock Clock) ClientOps {
+	return func(c *clientOptions) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
 // WithDebugging will enable debugging mode
 func WithDebugging() ClientOps {
 	return func(c *clientOptions) {
@@ -55,6 +357,10 @@ func WithDebugging() ClientOps {
 }
 
 // WithRedis will set the redis configuration
+//
+// Reads of a single key (Get, GetBytes, GetModel, GetModelWithTTL and Exists) use the read replicas round-robin
+// (ReadReplicaURLs), writes, locks and the other reads use the primary (URL, sentinel master or cluster)
+// CAUTION: replication is asynchronous, a key just written (or deleted) may not be on a replica yet (stale reads)
 func WithRedis(redisConfig *RedisConfig) ClientOps {
 	return func(c *clientOptions) {
 
@@ -63,10 +369,8 @@ func WithRedis(redisConfig *RedisConfig) ClientOps {
 			return
 		}
 
-		// Add prefix if missing
-		if !strings.Contains(redisConfig.URL, RedisPrefix) {
-			redisConfig.URL = RedisPrefix + redisConfig.URL
-		}
+		// Add the scheme if missing (rediss:// if using TLS)
+		redisConfig.URL = redisURLWithScheme(redisConfig.URL, redisConfig.UseTLS)
 
 		// Set the config and engine
 		c.redisConfig = redisConfig
@@ -91,6 +395,17 @@ func WithRedisConnection(redisClient *cache.Client) ClientOps {
 	}
 }
 
+// WithRedisDatabase will SELECT the database on each new redis connection, without changing the URL
+//
+// Used with WithRedis (preferred over the database of the URL and RedisConfig.Database, including the replicas)
+// or WithRedisConnection (the pool must be a *redis.Pool, it is changed, set it before using the connection)
+// NewClient returns an error for a negative database, a cluster only supports database 0
+func WithRedisDatabase(db int) ClientOps {
+	return func(c *clientOptions) {
+		c.redisDatabase = &db
+	}
+}
+
 // WithFreeCache will set the cache to local memory using FreeCache
 func WithFreeCache() ClientOps {
 	return func(c *clientOptions) {
@@ -98,7 +413,74 @@ func WithFreeCache() ClientOps {
 	}
 }
 
+// WithFreeCacheSize will set the size (bytes) of the FreeCache created by the client (freecache or the local tier)
+//
+// The full size is allocated up front, NewClient returns an error if below MinFreeCacheSize (512KB)
+// The max size of a single entry (key and value) is about 1/1024 of the cache size (larger values return ErrValueTooLarge)
+// Creating the cache also sets the Go GC percent (process wide), see: WithFreeCacheGCPercent
+// This size is not used with an existing connection (WithFreeCacheConnection)
+func WithFreeCacheSize(sizeBytes int) ClientOps {
+	return func(c *clientOptions) {
+		c.freeCacheSize = sizeBytes
+	}
+}
+
+// WithFreeCacheGCPercent will set the Go GC percent (process wide) set when the client creates a FreeCache
+//
+// FreeCache keeps the entries off the Go heap, a lower percent runs the GC more often (default: DefaultGCPercent)
+// Not used with an existing connection (WithFreeCacheConnection), zero or less is ignored
+func WithFreeCacheGCPercent(percent int) ClientOps {
+	return func(c *clientOptions) {
+		if percent > 0 {
+			c.freeCacheGCPercent = percent
+		}
+	}
+}
+
+// WithFreeCacheExpireInterval will remove the expired FreeCache entries in the background every interval
+//
+// FreeCache only removes an expired entry when the key is read or the space is reused (the entry counts against
+// the capacity until then), the sweeper runs in a goroutine that is stopped by Close (freecache or the local tier)
+// Each sweep iterates all the entries (copying the values), use an interval of minutes for large caches
+// Entries with a TTL shorter than the interval can be missed, the interval is rounded to a whole second (min: 1s)
+// The entries removed are counted in the FreeCacheStats (ExpiredCount and MissCount), zero or less is ignored
+func WithFreeCacheExpireInterval(interval time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if interval > 0 {
+			c.expireInterval = max(interval.Round(time.Second), time.Second)
+		}
+	}
+}
+
+// WithOnEvicted will call the function with the number of FreeCache entries evicted (memory pressure) since the last call
+//
+// FreeCache has no eviction callback, the sweeper compares the evictions every sweep (see: WithFreeCacheExpireInterval)
+// or every DefaultEvictionCheckInterval, the goroutine is stopped by Close (freecache or the local tier)
+// The function runs on the sweeper goroutine (offload any heavy work), nil is ignored (default: disabled)
+func WithOnEvicted(onEvicted func(countDelta int64)) ClientOps {
+	return func(c *clientOptions) {
+		if onEvicted != nil {
+			c.onEvicted = onEvicted
+		}
+	}
+}
+
+// WithHealthCheck will ping the engine in the background every interval, IsHealthy returns the result of the last probe
+//
+// The goroutine is stopped by Close, a probe is limited to the interval (and WithOperationTimeout)
+// Operations are still attempted when the engine is not healthy, zero or less is ignored (default: disabled)
+func WithHealthCheck(interval time.Duration) ClientOps {
+	return func(c *clientOptions) {
+		if interval > 0 {
+			c.healthCheckInterval = interval
+		}
+	}
+}
+
 // WithFreeCacheConnection will set the cache to use an existing FreeCache connection
+//
+// The cache is owned by the caller and can be shared by several clients, Close does not clear it
+// NOTE: EmptyCache and Flush still remove the keys of every client without a key prefix (see: WithKeyPrefix)
 func WithFreeCacheConnection(client *freecache.Cache) ClientOps {
 	return func(c *clientOptions) {
 		if client != nil {
@@ -108,6 +490,109 @@ func WithFreeCacheConnection(client *freecache.Cache) ClientOps {
 	}
 }
 
+// WithEngineFallback will keep a copy of the values in a local FreeCache, read when Redis cannot be reached
+//
+// Every write updates the local copy (the sets even if Redis fails), every delete removes it (ie: DeleteByPattern,
+// DeleteDependency and EmptyCache), counters, Append and a failed CompareAndSwap or ReplaceModel remove it
+// Get and GetModel read the local copy only on a connection error (refused, reset, timeout...), never on a logical
+// error (ie: ErrKeyRequired), a key missing from the local copy is a miss
+// A nil local cache is ignored, the local cache is not cleared by Close
+// CAUTION: the local copies are stale (the writes of the other nodes are not copied) and
+// only used while Redis is unavailable, prefer stale values over errors only if the data allows it
+func WithEngineFallback(local *freecache.Cache) ClientOps {
+	return func(c *clientOptions) {
+		if local != nil {
+			c.fallback = local
+		}
+	}
+}
+
+// WithTieredCache will set the cache to use a local FreeCache (L1) in front of Redis (L2)
+//
+// Reads check the local cache first and fall back to Redis (populating the local cache),
+// writes go to both and deletes remove the key from both
+// If local is nil, a new FreeCache is created, an existing local cache is not cleared by Close
+// CAUTION: local copies are not invalidated on other nodes and can be stale for up to localTTL
+func WithTieredCache(local *freecache.Cache, redisConfig *RedisConfig, localTTL time.Duration) ClientOps {
+	return func(c *clientOptions) {
+
+		// Don't panic if nil is passed
+		if redisConfig == nil {
+			return
+		}
+
+		// Set the redis config, then switch the engine to tiered
+		WithRedis(redisConfig)(c)
+		c.engine = Tiered
+		c.freeCache = local
+
+		// Local copies always expire
+		c.localTTL = localTTL
+		if c.localTTL <= 0 {
+			c.localTTL = DefaultTieredLocalTTL
+		}
+	}
+}
+
+// MemcachedOption allow functional options to be supplied
+// that overwrite the default memcached configuration
+type MemcachedOption func(c *MemcachedConfig)
+
+// WithMemcached will set the memcached servers and configuration
+func WithMemcached(servers []string, opts ...MemcachedOption) ClientOps {
+	return func(c *clientOptions) {
+
+		// Don't panic if no servers are passed
+		if len(servers) == 0 {
+			return
+		}
+
+		// Create the config with defaults
+		config := &MemcachedConfig{
+			MaxIdleConnections: DefaultMemcachedMaxIdleConnections,
+			Servers:            servers,
+			Timeout:            DefaultMemcachedTimeout,
+		}
+		for _, opt := range opts {
+			opt(config)
+		}
+
+		// Set the config and engine
+		c.memcachedConfig = config
+		c.engine = Memcached
+		c.memcached = nil // If you load via config, remove the connection
+	}
+}
+
+// WithMemcachedConnection will set an existing memcached connection (read & write)
+func WithMemcachedConnection(memcachedClient *memcache.Client) ClientOps {
+	return func(c *clientOptions) {
+		if memcachedClient != nil {
+			c.memcached = memcachedClient
+			c.engine = Memcached
+			c.memcachedConfig = nil // If you load an existing connection, config is not needed
+		}
+	}
+}
+
+// WithMemcachedTimeout will set the socket read/write timeout (memcached)
+func WithMemcachedTimeout(timeout time.Duration) MemcachedOption {
+	return func(c *MemcachedConfig) {
+		if timeout > 0 {
+			c.Timeout = timeout
+		}
+	}
+}
+
+// WithMemcachedMaxIdleConnections will set the max idle connections per server (memcached)
+func WithMemcachedMaxIdleConnections(maxIdle int) MemcachedOption {
+	return func(c *MemcachedConfig) {
+		if maxIdle > 0 {
+			c.MaxIdleConnections = maxIdle
+		}
+	}
+}
+
 // WithLogger will set the custom logger interface
 func WithLogger(customLogger zLogger.GormLoggerInterface) ClientOps {
 	return func(c *clientOptions) {
@@ -116,3 +601,105 @@ func WithLogger(customLogger zLogger.GormLoggerInterface) ClientOps {
 		}
 	}
 }
+
+// WithJSONEncoderOptions will set the options of the default JSON serializer (SetModel and GetModel)
+//
+// Replaces a custom serializer (see: WithSerializer), the defaults match encoding/json
+// NOTE: values stored with and without DisableHTMLEscape decode the same, but compare differently (SetModelIfChanged)
+func WithJSONEncoderOptions(options JSONEncoderOptions) ClientOps {
+	return func(c *clientOptions) {
+		c.serializer = &JSONSerializer{JSONEncoderOptions: options}
+	}
+}
+
+// WithMsgpack will use msgpack to encode the models (SetModel and GetModel), see: MsgpackSerializer
+//
+// CAUTION: the values stored using JSON cannot be decoded (ErrNotMsgpack), and the json tags are not used
+func WithMsgpack() ClientOps {
+	return func(c *clientOptions) {
+		c.serializer = &MsgpackSerializer{}
+	}
+}
+
+// WithSerializer will set a custom serializer for models (SetModel and GetModel)
+//
+// Default is JSON (encoding/json)
+func WithSerializer(serializer Serializer) ClientOps {
+	return func(c *clientOptions) {
+		if serializer != nil {
+			c.serializer = serializer
+		}
+	}
+}
+
+// WithCompression will compress values (Set, SetTTL, SetMulti and SetModel) using the given algorithm
+//
+// Values smaller than the compression threshold are stored uncompressed (binary values, SetBytes or a []byte, keep a header byte)
+// Reads will detect a compressed value using the header byte (uncompressed values are still supported)
+func WithCompression(algo CompressionType) ClientOps {
+	return func(c *clientOptions) {
+		c.compression = algo
+	}
+}
+
+// WithCompressionThreshold will set the minimum size (in bytes) of a value before it is compressed
+func WithCompressionThreshold(minBytes int) ClientOps {
+	return func(c *clientOptions) {
+		if minBytes >= 0 {
+			c.compressionThreshold = minBytes
+		}
+	}
+}
+
+// WithValueEncoding will encode the stored values (after the compression) using the given encoding
+//
+// Base64 keeps binary values (nulls, high-bit bytes) safe for callers of the string methods (Set and Get)
+// Reads detect an encoded value using the marker prefix (values stored before the encoding are read as is)
+func WithValueEncoding(encoding ValueEncoding) ClientOps {
+	return func(c *clientOptions) {
+		c.valueEncoding = encoding
+	}
+}
+
+// WithKeyPrefix will set a prefix (namespace) that is added to all keys, dependencies and locks
+//
+// EmptyCache and Flush will only remove the keys under the prefix
+func WithKeyPrefix(prefix string) ClientOps {
+	return func(c *clientOptions) {
+		c.keyPrefix = strings.TrimSpace(prefix)
+	}
+}
+
+// WithKeyHashing will transform every key (and lock key) using the hasher before it is stored (ie: KeyHasherSHA256)
+//
+// Long composite keys are stored with a fixed length, the key prefix is added after the hashing (not hashed)
+// The max key length is checked on the hashed key (see: WithMaxKeyLength), a nil hasher is ignored
+// CAUTION: the stored keys cannot be read by a human, a pattern (DeleteByPattern and Scan) only matches the
+// hashed keys, and Scan and ListLocks return the hashed keys
+func WithKeyHashing(hasher KeyHasher) ClientOps {
+	return func(c *clientOptions) {
+		if hasher != nil {
+			c.keyHasher = hasher
+		}
+	}
+}
+
+// WithAllowUnsafeFlush will allow Flush to remove every key when there is no key prefix (see: WithKeyPrefix)
+//
+// CAUTION: on a shared redis database or memcached server this removes the keys of the other apps
+func WithAllowUnsafeFlush() ClientOps {
+	return func(c *clientOptions) {
+		c.allowUnsafeFlush = true
+	}
+}
+
+// WithScanCount will set the number of keys per SCAN iteration (redis), used by DeleteByPattern and Scan
+//
+// A larger count uses fewer round trips, but blocks the server longer per iteration (default: 100)
+func WithScanCount(count int) ClientOps {
+	return func(c *clientOptions) {
+		if count > 0 {
+			c.scanCount = count
+		}
+	}
+}
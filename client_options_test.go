@@ -5,10 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 	zLogger "github.com/mrz1836/go-logger"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,6 +100,38 @@ func TestWithRedis(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("redis url prefix", func(t *testing.T) {
+		var tests = []struct {
+			name     string
+			url      string
+			useTLS   bool
+			expected string
+		}{
+			{"empty", "", false, ""},
+			{"prefixed", testLocalConnectionURL, false, testLocalConnectionURL},
+			{"unprefixed", "localhost:" + DefaultRedisPort, false, testLocalConnectionURL},
+			{"unprefixed with tls", "host:6379", true, RedisTLSPrefix + "host:6379"},
+			{"tls prefixed with tls", "rediss://host:6379", true, "rediss://host:6379"},
+			{"tls prefixed without tls", "rediss://host:6379", false, "rediss://host:6379"},
+			{"mismatched scheme is switched to tls", "redis://host:6379", true, "rediss://host:6379"},
+			{"contains the prefix", "localhost:6379/?next=redis://host", false, "redis://localhost:6379/?next=redis://host"},
+			{"credentials contain the prefix", "user:redis://@host:6379", false, "redis://user:redis://@host:6379"},
+			{"other scheme is kept", "http://host:6379", false, "http://host:6379"},
+			{"spaces are removed", "  localhost:" + DefaultRedisPort + " ", false, testLocalConnectionURL},
+		}
+		for _, test := range tests {
+			options := defaultClientOptions()
+			WithRedis(&RedisConfig{URL: test.url, UseTLS: test.useTLS})(options)
+			assert.Equal(t, test.expected, options.redisConfig.URL, test.name)
+		}
+	})
+
+	t.Run("apply garbage url, error before connecting", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: "bad url:%%"}))
+		assert.Nil(t, c)
+		require.ErrorIs(t, err, ErrInvalidRedisURL)
+	})
+
 	t.Run("apply basic local config", func(t *testing.T) {
 
 		if testing.Short() {
@@ -192,6 +226,27 @@ func TestWithRedisConnection(t *testing.T) {
 	})
 }
 
+// TestWithRedisDatabase will test the method WithRedisDatabase()
+func TestWithRedisDatabase(t *testing.T) {
+	t.Run("get opts", func(t *testing.T) {
+		opt := WithRedisDatabase(1)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("apply database", func(t *testing.T) {
+		options := &clientOptions{}
+		WithRedisDatabase(1)(options)
+		require.NotNil(t, options.redisDatabase)
+		assert.Equal(t, 1, *options.redisDatabase)
+	})
+
+	t.Run("not set by default", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		assert.Nil(t, c.(*Client).options.redisDatabase)
+	})
+}
+
 // TestWithFreeCache will test the method WithFreeCache()
 func TestWithFreeCache(t *testing.T) {
 	t.Run("get opts", func(t *testing.T) {
@@ -211,6 +266,28 @@ func TestWithFreeCache(t *testing.T) {
 	})
 }
 
+// TestWithHealthCheck will test the method WithHealthCheck()
+func TestWithHealthCheck(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithHealthCheck(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithHealthCheck(0)(options)
+		assert.Equal(t, time.Duration(0), options.healthCheckInterval)
+
+		WithHealthCheck(-time.Second)(options)
+		assert.Equal(t, time.Duration(0), options.healthCheckInterval)
+
+		WithHealthCheck(30 * time.Second)(options)
+		assert.Equal(t, 30*time.Second, options.healthCheckInterval)
+	})
+}
+
 // TestWithFreeCacheConnection will test the method WithFreeCacheConnection()
 func TestWithFreeCacheConnection(t *testing.T) {
 	t.Run("get opts", func(t *testing.T) {
@@ -220,7 +297,7 @@ func TestWithFreeCacheConnection(t *testing.T) {
 
 	t.Run("use an existing connection", func(t *testing.T) {
 
-		freeClient := loadFreeCache(DefaultCacheSize, DefaultGCPercent)
+		freeClient := loadFreeCache(DefaultCacheSize, DefaultGCPercent, realClock{})
 
 		opts := []ClientOps{WithDebugging(), WithFreeCacheConnection(freeClient)}
 		c, err := NewClient(context.Background(), opts...)
@@ -256,3 +333,915 @@ func TestWithLogger(t *testing.T) {
 		assert.Equal(t, customClient, options.logger)
 	})
 }
+
+// TestWithSerializer will test the method WithSerializer()
+func TestWithSerializer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithSerializer(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithSerializer(nil)
+		opt(options)
+		assert.IsType(t, &JSONSerializer{}, options.serializer)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithSerializer(&gobSerializer{})
+		opt(options)
+		assert.IsType(t, &gobSerializer{}, options.serializer)
+	})
+}
+
+// TestWithJSONEncoderOptions will test the method WithJSONEncoderOptions()
+func TestWithJSONEncoderOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithJSONEncoderOptions(JSONEncoderOptions{})
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("default is escaping html", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, &JSONSerializer{}, options.serializer)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithSerializer(&gobSerializer{})(options)
+		WithJSONEncoderOptions(JSONEncoderOptions{DisableHTMLEscape: true, UseNumber: true})(options)
+		assert.Equal(t, &JSONSerializer{
+			JSONEncoderOptions: JSONEncoderOptions{DisableHTMLEscape: true, UseNumber: true},
+		}, options.serializer)
+	})
+}
+
+// TestWithMsgpack will test the method WithMsgpack()
+func TestWithMsgpack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithMsgpack()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithMsgpack()(options)
+		assert.IsType(t, &MsgpackSerializer{}, options.serializer)
+	})
+}
+
+// TestWithCompression will test the method WithCompression()
+func TestWithCompression(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithCompression(CompressionGzip)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, CompressionNone, options.compression)
+		opt := WithCompression(CompressionSnappy)
+		opt(options)
+		assert.Equal(t, CompressionSnappy, options.compression)
+	})
+}
+
+// TestWithCompressionThreshold will test the method WithCompressionThreshold()
+func TestWithCompressionThreshold(t *testing.T) {
+	t.Parallel()
+
+	t.Run("test applying negative value", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithCompressionThreshold(-1)
+		opt(options)
+		assert.Equal(t, DefaultCompressionThreshold, options.compressionThreshold)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithCompressionThreshold(10)
+		opt(options)
+		assert.Equal(t, 10, options.compressionThreshold)
+	})
+}
+
+// TestWithKeyPrefix will test the method WithKeyPrefix()
+func TestWithKeyPrefix(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithKeyPrefix("")
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithKeyPrefix(" app: ")
+		opt(options)
+		assert.Equal(t, "app:", options.keyPrefix)
+	})
+}
+
+// TestWithAllowUnsafeFlush will test the method WithAllowUnsafeFlush()
+func TestWithAllowUnsafeFlush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithAllowUnsafeFlush()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.allowUnsafeFlush)
+		WithAllowUnsafeFlush()(options)
+		assert.True(t, options.allowUnsafeFlush)
+	})
+}
+
+// TestWithStrictMisses will test the method WithStrictMisses()
+func TestWithStrictMisses(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithStrictMisses()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.strictMisses)
+		WithStrictMisses()(options)
+		assert.True(t, options.strictMisses)
+	})
+}
+
+// TestWithSingleFlight will test the method WithSingleFlight()
+func TestWithSingleFlight(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithSingleFlight()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Nil(t, options.reads)
+		WithSingleFlight()(options)
+		assert.NotNil(t, options.reads)
+	})
+}
+
+// TestWithObservabilityContext will test the method WithObservabilityContext()
+func TestWithObservabilityContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithObservabilityContext()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.observabilityContext)
+		WithObservabilityContext()(options)
+		assert.True(t, options.observabilityContext)
+	})
+}
+
+// TestWithPanicRecovery will test the method WithPanicRecovery()
+func TestWithPanicRecovery(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithPanicRecovery()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.panicRecovery)
+		WithPanicRecovery()(options)
+		assert.True(t, options.panicRecovery)
+	})
+}
+
+// TestWithModelVersioning will test the method WithModelVersioning()
+func TestWithModelVersioning(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithModelVersioning()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.modelVersioning)
+		WithModelVersioning()(options)
+		assert.True(t, options.modelVersioning)
+	})
+}
+
+// TestWithOnEvicted will test the method WithOnEvicted()
+func TestWithOnEvicted(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithOnEvicted(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithOnEvicted(nil)(options)
+		assert.Nil(t, options.onEvicted)
+		WithOnEvicted(func(int64) {})(options)
+		assert.NotNil(t, options.onEvicted)
+	})
+}
+
+// TestWithValueEncoding will test the method WithValueEncoding()
+func TestWithValueEncoding(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithValueEncoding(ValueEncodingBase64)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, ValueEncodingNone, options.valueEncoding)
+		WithValueEncoding(ValueEncodingBase64)(options)
+		assert.Equal(t, ValueEncodingBase64, options.valueEncoding)
+	})
+}
+
+// TestWithLockNamespace will test the method WithLockNamespace()
+func TestWithLockNamespace(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithLockNamespace("")
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, DefaultLockNamespace, options.lockNamespace)
+		WithLockNamespace("locks/")(options)
+		assert.Equal(t, "locks/", options.lockNamespace)
+		WithLockNamespace("")(options)
+		assert.Empty(t, options.lockNamespace)
+	})
+}
+
+// TestWithEngineFallback will test the method WithEngineFallback()
+func TestWithEngineFallback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithEngineFallback(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithEngineFallback(nil)(options)
+		assert.Nil(t, options.fallback)
+
+		local := freecache.NewCache(MinFreeCacheSize)
+		WithEngineFallback(local)(options)
+		assert.Equal(t, local, options.fallback)
+	})
+}
+
+// TestWithKeyHashing will test the method WithKeyHashing()
+func TestWithKeyHashing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithKeyHashing(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Nil(t, options.keyHasher)
+		WithKeyHashing(nil)(options)
+		assert.Nil(t, options.keyHasher)
+		WithKeyHashing(KeyHasherSHA256)(options)
+		require.NotNil(t, options.keyHasher)
+		assert.Equal(t, KeyHasherSHA256(testKey), options.keyHasher(testKey))
+	})
+}
+
+// TestWithModelLoader will test the method WithModelLoader()
+func TestWithModelLoader(t *testing.T) {
+	t.Parallel()
+
+	loader := func(context.Context, string) (interface{}, time.Duration, error) {
+		return nil, 0, nil
+	}
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithModelLoader("", nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil loader", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithModelLoader("config:", nil)(options)
+		assert.Empty(t, options.modelLoaders)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithModelLoader("config:", loader)(options)
+		WithModelLoader("user:", loader)(options)
+		WithModelLoader("config:", loader)(options)
+		require.Len(t, options.modelLoaders, 2)
+		assert.Equal(t, "config:", options.modelLoaders[0].prefix)
+		assert.Equal(t, "user:", options.modelLoaders[1].prefix)
+	})
+}
+
+// TestWithScanCount will test the method WithScanCount()
+func TestWithScanCount(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithScanCount(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying invalid count", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithScanCount(0)
+		opt(options)
+		assert.Equal(t, defaultScanCount, options.scanCount)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithScanCount(500)
+		opt(options)
+		assert.Equal(t, 500, options.scanCount)
+	})
+}
+
+// TestWithLockBackoff will test the method WithLockBackoff()
+func TestWithLockBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithLockBackoff(0, 0, 0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying invalid initial delay", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithLockBackoff(0, time.Second, 2)
+		opt(options)
+		assert.Equal(t, defaultClientOptions().lockBackoff, options.lockBackoff)
+	})
+
+	t.Run("test applying invalid max and factor", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithLockBackoff(100*time.Millisecond, time.Millisecond, 0.5)
+		opt(options)
+		assert.Equal(t, lockBackoff{
+			factor:   1,
+			initial:  100 * time.Millisecond,
+			jitter:   true,
+			maxDelay: 100 * time.Millisecond,
+		}, options.lockBackoff)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithLockBackoff(5*time.Millisecond, time.Second, 1.5)
+		opt(options)
+		assert.Equal(t, lockBackoff{
+			factor:   1.5,
+			initial:  5 * time.Millisecond,
+			jitter:   true,
+			maxDelay: time.Second,
+		}, options.lockBackoff)
+	})
+}
+
+// TestWithDefaultTTL will test the method WithDefaultTTL()
+func TestWithDefaultTTL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithDefaultTTL(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithDefaultTTL(0)(options)
+		WithDefaultTTL(-time.Minute)(options)
+		assert.Equal(t, time.Duration(0), options.defaultTTL)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithDefaultTTL(time.Hour)
+		opt(options)
+		assert.Equal(t, time.Hour, options.defaultTTL)
+	})
+}
+
+// TestWithOperationTimeout will test the method WithOperationTimeout()
+func TestWithOperationTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithOperationTimeout(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithOperationTimeout(0)(options)
+		WithOperationTimeout(-time.Second)(options)
+		assert.Equal(t, time.Duration(0), options.operationTimeout)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithOperationTimeout(2 * time.Second)(options)
+		assert.Equal(t, 2*time.Second, options.operationTimeout)
+	})
+}
+
+// TestWithFreeCacheGCPercent will test the method WithFreeCacheGCPercent()
+func TestWithFreeCacheGCPercent(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithFreeCacheGCPercent(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithFreeCacheGCPercent(0)(options)
+		WithFreeCacheGCPercent(-1)(options)
+		assert.Equal(t, DefaultGCPercent, options.freeCacheGCPercent)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithFreeCacheGCPercent(50)(options)
+		assert.Equal(t, 50, options.freeCacheGCPercent)
+	})
+}
+
+// TestWithFreeCacheExpireInterval will test the method WithFreeCacheExpireInterval()
+func TestWithFreeCacheExpireInterval(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithFreeCacheExpireInterval(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithFreeCacheExpireInterval(0)(options)
+		WithFreeCacheExpireInterval(-time.Second)(options)
+		assert.Equal(t, time.Duration(0), options.expireInterval)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithFreeCacheExpireInterval(time.Minute)(options)
+		assert.Equal(t, time.Minute, options.expireInterval)
+	})
+
+	t.Run("rounded to a whole second", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithFreeCacheExpireInterval(1400 * time.Millisecond)(options)
+		assert.Equal(t, time.Second, options.expireInterval)
+
+		WithFreeCacheExpireInterval(time.Millisecond)(options)
+		assert.Equal(t, time.Second, options.expireInterval)
+	})
+}
+
+// TestWithRetry will test the method WithRetry()
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithRetry(0, 0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative attempts", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithRetry(0, time.Second)(options)
+		WithRetry(-1, time.Second)(options)
+		assert.Equal(t, 0, options.retryAttempts)
+		assert.Equal(t, time.Duration(0), options.retryBackoff)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithRetry(3, 100*time.Millisecond)(options)
+		assert.Equal(t, 3, options.retryAttempts)
+		assert.Equal(t, 100*time.Millisecond, options.retryBackoff)
+	})
+
+	t.Run("negative backoff is no delay", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithRetry(3, -time.Second)(options)
+		assert.Equal(t, time.Duration(0), options.retryBackoff)
+	})
+}
+
+// TestWithClock will test the method WithClock()
+func TestWithClock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithClock(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithClock(nil)(options)
+		assert.Equal(t, realClock{}, options.clock)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		clock := &testClock{}
+		options := defaultClientOptions()
+		WithClock(clock)(options)
+		assert.Equal(t, clock, options.clock)
+	})
+}
+
+// TestWithTTLJitter will test the method WithTTLJitter()
+func TestWithTTLJitter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithTTLJitter(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, time.Duration(0), options.ttlJitter)
+		WithTTLJitter(-time.Second)(options)
+		assert.Equal(t, time.Duration(0), options.ttlJitter)
+		WithTTLJitter(time.Minute)(options)
+		assert.Equal(t, time.Minute, options.ttlJitter)
+	})
+}
+
+// TestWithDisableKeyTrimming will test the method WithDisableKeyTrimming()
+func TestWithDisableKeyTrimming(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithDisableKeyTrimming()
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.False(t, options.disableKeyTrimming)
+		WithDisableKeyTrimming()(options)
+		assert.True(t, options.disableKeyTrimming)
+	})
+}
+
+// TestWithMaxDependencyKeys will test the method WithMaxDependencyKeys()
+func TestWithMaxDependencyKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithMaxDependencyKeys(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, 0, options.maxDependencyKeys)
+		WithMaxDependencyKeys(-1)(options)
+		assert.Equal(t, 0, options.maxDependencyKeys)
+		WithMaxDependencyKeys(1000)(options)
+		assert.Equal(t, 1000, options.maxDependencyKeys)
+	})
+}
+
+// TestWithMaxKeyLength will test the method WithMaxKeyLength()
+func TestWithMaxKeyLength(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithMaxKeyLength(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithMaxKeyLength(0)(options)
+		WithMaxKeyLength(-1)(options)
+		assert.Equal(t, 0, options.maxKeyLength)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMaxKeyLength(250)
+		opt(options)
+		assert.Equal(t, 250, options.maxKeyLength)
+	})
+}
+
+// TestWithKeyValidator will test the method WithKeyValidator()
+func TestWithKeyValidator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithKeyValidator(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithKeyValidator(nil)(options)
+		assert.Nil(t, options.keyValidator)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithKeyValidator(func(string) error { return nil })(options)
+		assert.NotNil(t, options.keyValidator)
+	})
+}
+
+// TestWithMaxValueSize will test the method WithMaxValueSize()
+func TestWithMaxValueSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithMaxValueSize(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying zero or negative", func(t *testing.T) {
+		options := defaultClientOptions()
+		WithMaxValueSize(0)(options)
+		WithMaxValueSize(-1)(options)
+		assert.Equal(t, 0, options.maxValueSize)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMaxValueSize(1024)
+		opt(options)
+		assert.Equal(t, 1024, options.maxValueSize)
+	})
+}
+
+// TestWithLockSecretBytes will test the method WithLockSecretBytes()
+func TestWithLockSecretBytes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithLockSecretBytes(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, DefaultLockSecretBytes, options.lockSecretBytes)
+
+		WithLockSecretBytes(MinLockSecretBytes - 1)(options)
+		assert.Equal(t, DefaultLockSecretBytes, options.lockSecretBytes)
+
+		WithLockSecretBytes(16)(options)
+		assert.Equal(t, 16, options.lockSecretBytes)
+	})
+}
+
+// TestWithLoaderLock will test the method WithLoaderLock()
+func TestWithLoaderLock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithLoaderLock(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying less than a second", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithLoaderLock(500 * time.Millisecond)
+		opt(options)
+		assert.Equal(t, time.Duration(0), options.loaderLockTTL)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithLoaderLock(10 * time.Second)
+		opt(options)
+		assert.Equal(t, 10*time.Second, options.loaderLockTTL)
+	})
+}
+
+// TestWithFreeCacheSize will test the method WithFreeCacheSize()
+func TestWithFreeCacheSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithFreeCacheSize(0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test default size", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Equal(t, DefaultCacheSize, options.freeCacheSize)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithFreeCacheSize(10 * 1024 * 1024)
+		opt(options)
+		assert.Equal(t, 10*1024*1024, options.freeCacheSize)
+	})
+}
+
+// TestWithHooks will test the method WithHooks()
+func TestWithHooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithHooks(Hooks{})
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithHooks(Hooks{OnMiss: func(string) {}})
+		opt(options)
+		assert.NotNil(t, options.hooks.OnMiss)
+		assert.Nil(t, options.hooks.OnHit)
+	})
+}
+
+// TestWithPrometheus will test the method WithPrometheus()
+func TestWithPrometheus(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithPrometheus(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithPrometheus(nil)
+		opt(options)
+		assert.Nil(t, options.registerer)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		registry := prometheus.NewRegistry()
+		opt := WithPrometheus(registry)
+		opt(options)
+		assert.Equal(t, registry, options.registerer)
+	})
+}
+
+// TestWithMemcached will test the method WithMemcached()
+func TestWithMemcached(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithMemcached(nil)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil servers", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMemcached(nil)
+		opt(options)
+		assert.Equal(t, Empty, options.engine)
+	})
+
+	t.Run("test applying option with defaults", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMemcached([]string{"localhost:11211"})
+		opt(options)
+		assert.Equal(t, Memcached, options.engine)
+		assert.Equal(t, []string{"localhost:11211"}, options.memcachedConfig.Servers)
+		assert.Equal(t, DefaultMemcachedTimeout, options.memcachedConfig.Timeout)
+		assert.Equal(t, DefaultMemcachedMaxIdleConnections, options.memcachedConfig.MaxIdleConnections)
+	})
+
+	t.Run("test applying memcached options", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMemcached(
+			[]string{"localhost:11211"},
+			WithMemcachedTimeout(2*time.Second),
+			WithMemcachedMaxIdleConnections(10),
+		)
+		opt(options)
+		assert.Equal(t, 2*time.Second, options.memcachedConfig.Timeout)
+		assert.Equal(t, 10, options.memcachedConfig.MaxIdleConnections)
+	})
+
+	t.Run("invalid memcached options are ignored", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMemcached(
+			[]string{"localhost:11211"},
+			WithMemcachedTimeout(0),
+			WithMemcachedMaxIdleConnections(-1),
+		)
+		opt(options)
+		assert.Equal(t, DefaultMemcachedTimeout, options.memcachedConfig.Timeout)
+		assert.Equal(t, DefaultMemcachedMaxIdleConnections, options.memcachedConfig.MaxIdleConnections)
+	})
+}
+
+// TestWithMemcachedConnection will test the method WithMemcachedConnection()
+func TestWithMemcachedConnection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("test applying nil", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithMemcachedConnection(nil)
+		opt(options)
+		assert.Nil(t, options.memcached)
+		assert.Equal(t, Empty, options.engine)
+	})
+
+	t.Run("test applying existing connection", func(t *testing.T) {
+		options := defaultClientOptions()
+		client := memcache.New("localhost:11211")
+		opt := WithMemcachedConnection(client)
+		opt(options)
+		assert.Equal(t, client, options.memcached)
+		assert.Equal(t, Memcached, options.engine)
+		assert.Nil(t, options.memcachedConfig)
+	})
+}
+
+// TestWithTieredCache will test the method WithTieredCache()
+func TestWithTieredCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("check type", func(t *testing.T) {
+		opt := WithTieredCache(nil, nil, 0)
+		assert.IsType(t, *new(ClientOps), opt)
+	})
+
+	t.Run("test applying nil redis config", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithTieredCache(freecache.NewCache(DefaultCacheSize), nil, time.Minute)
+		opt(options)
+		assert.Equal(t, Empty, options.engine)
+		assert.Nil(t, options.freeCache)
+	})
+
+	t.Run("test applying option", func(t *testing.T) {
+		options := defaultClientOptions()
+		local := freecache.NewCache(DefaultCacheSize)
+		opt := WithTieredCache(local, &RedisConfig{URL: "localhost:6379"}, time.Minute)
+		opt(options)
+		assert.Equal(t, Tiered, options.engine)
+		assert.Equal(t, local, options.freeCache)
+		assert.Equal(t, testLocalConnectionURL, options.redisConfig.URL)
+		assert.Equal(t, time.Minute, options.localTTL)
+	})
+
+	t.Run("default local ttl", func(t *testing.T) {
+		options := defaultClientOptions()
+		opt := WithTieredCache(nil, &RedisConfig{URL: testLocalConnectionURL}, 0)
+		opt(options)
+		assert.Equal(t, Tiered, options.engine)
+		assert.Nil(t, options.freeCache)
+		assert.Equal(t, DefaultTieredLocalTTL, options.localTTL)
+	})
+}
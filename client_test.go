@@ -2,8 +2,14 @@ package cachestore
 
 import (
 	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/alicebob/miniredis/v2/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +56,27 @@ func TestNewClient(t *testing.T) {
 		assert.Equal(t, FreeCache, c.Engine())
 	})
 
+	t.Run("["+FreeCache.String()+"] - size below the minimum", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithFreeCacheSize(MinFreeCacheSize-1))
+		assert.Nil(t, c)
+		require.ErrorIs(t, err, ErrInvalidFreeCacheSize)
+	})
+
+	t.Run("["+FreeCache.String()+"] - custom size", func(t *testing.T) {
+		largeValue := strings.Repeat("x", 2048)
+
+		// The max entry size is a fraction of the cache size (1/1024)
+		c, err := NewClient(context.Background(), WithFreeCache(), WithFreeCacheSize(MinFreeCacheSize))
+		require.NoError(t, err)
+		require.NotNil(t, c.FreeCache())
+		require.ErrorIs(t, c.Set(context.Background(), testKey, largeValue), ErrValueTooLarge)
+
+		// The default size is used without the option
+		c, err = NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		require.NoError(t, c.Set(context.Background(), testKey, largeValue))
+	})
+
 	t.Run("["+Redis.String()+"] - redis connection is nil", func(t *testing.T) {
 		c, err := NewClient(context.Background(),
 			WithRedisConnection(nil),
@@ -129,6 +156,92 @@ func TestClient_Close(t *testing.T) {
 		assert.Nil(t, c.FreeCache())
 	})
 
+	t.Run("["+FreeCache.String()+"] - a shared connection is not cleared", func(t *testing.T) {
+		shared := loadFreeCache(DefaultCacheSize, DefaultGCPercent, realClock{})
+
+		first, err := NewClient(context.Background(), WithFreeCacheConnection(shared))
+		require.NoError(t, err)
+		var second ClientInterface
+		second, err = NewClient(context.Background(), WithFreeCacheConnection(shared))
+		require.NoError(t, err)
+		defer second.Close(context.Background())
+
+		require.NoError(t, first.Set(context.Background(), testKey, testValue))
+		require.NoError(t, second.Set(context.Background(), testKey+"-second", testValue))
+
+		first.Close(context.Background())
+		assert.Equal(t, Empty, first.Engine())
+		assert.Nil(t, first.FreeCache())
+
+		// The other client still reads the keys
+		var value string
+		value, err = second.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+
+		value, err = second.Get(context.Background(), testKey+"-second")
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+		assert.Equal(t, int64(2), shared.EntryCount())
+	})
+
+	t.Run("["+FreeCache.String()+"] - a created connection is cleared", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		created := c.FreeCache()
+		c.Close(context.Background())
+
+		assert.Equal(t, int64(0), created.EntryCount())
+	})
+
+	for _, testCase := range getInMemoryTestCases(t) {
+		t.Run(testCase.name+" - the methods return ErrClientClosed after Close", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			c.Close(context.Background())
+			c.Close(context.Background()) // Idempotent
+			require.Equal(t, Empty, c.Engine())
+
+			require.ErrorIs(t, c.EmptyCache(context.Background()), ErrClientClosed)
+			require.ErrorIs(t, c.EmptyCache(context.Background()), ErrClientClosed) // Same error every time
+			require.ErrorIs(t, c.Set(context.Background(), testKey, testValue), ErrClientClosed)
+			require.ErrorIs(t, c.SetTTL(context.Background(), testKey, testValue, time.Minute), ErrClientClosed)
+			require.ErrorIs(t, c.SetModel(context.Background(), testKey, &genericStruct{}, 0), ErrClientClosed)
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey, &genericStruct{}), ErrClientClosed)
+			require.ErrorIs(t, c.Delete(context.Background(), testKey), ErrClientClosed)
+			require.ErrorIs(t, c.DeleteDependency(context.Background(), "dependency"), ErrClientClosed)
+			_, err = c.Get(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.GetMulti(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.Exists(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrClientClosed)
+
+			// Lock methods
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.WriteLockWithSecret(context.Background(), testKey, testValue, 30)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, _, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.WaitWriteLock(context.Background(), testKey, 30, 1)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.WriteLockMany(context.Background(), []string{testKey}, 30)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.ExtendLock(context.Background(), testKey, testValue, 30)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.ReleaseLock(context.Background(), testKey, testValue)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.ForceReleaseLock(context.Background(), testKey)
+			require.ErrorIs(t, err, ErrClientClosed)
+			_, err = c.ListLocks(context.Background())
+			require.ErrorIs(t, err, ErrClientClosed)
+		})
+	}
+
 	t.Run("["+Redis.String()+"] - load mocked connection and close", func(t *testing.T) {
 		c, _ := newMockRedisClient(t)
 		c.Close(context.Background())
@@ -164,6 +277,364 @@ func TestClient_Close(t *testing.T) {
 	})
 }
 
+// TestClient_Ping will test the method Ping()
+func TestClient_Ping(t *testing.T) {
+
+	t.Run("["+FreeCache.String()+"] - always available", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Ping(context.Background()))
+	})
+
+	t.Run("["+Redis.String()+"] - healthy server", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Ping(context.Background()))
+	})
+
+	t.Run("["+Redis.String()+"] - stopped server", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		r.Close()
+		require.Error(t, c.Ping(context.Background()))
+	})
+
+	t.Run("["+Tiered.String()+"] - stopped server", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithTieredCache(nil, &RedisConfig{URL: r.Addr()}, time.Minute))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Ping(context.Background()))
+		r.Close()
+		require.Error(t, c.Ping(context.Background()))
+	})
+
+	t.Run("["+Memcached.String()+"] - healthy server", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Ping(context.Background()))
+	})
+
+	t.Run("closed client", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		c.Close(context.Background())
+		require.ErrorIs(t, c.Ping(context.Background()), ErrClientClosed)
+	})
+
+	t.Run("context is done", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, c.Ping(ctx), context.Canceled)
+	})
+}
+
+// TestClient_Clear will test the method Clear()
+func TestClient_Clear(t *testing.T) {
+
+	t.Run("["+FreeCache.String()+"] - removes the entries and resets the statistics", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+strconv.Itoa(i), testValue))
+		}
+		_, err = c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey+"0")
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+
+		require.NoError(t, c.Clear(context.Background()))
+		assert.Equal(t, Stats{}, c.Stats())
+
+		// The entries are gone (including the lock)
+		var found bool
+		found, err = c.Exists(context.Background(), testKey+"0")
+		require.NoError(t, err)
+		assert.False(t, found)
+		_, err = c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		// The client is still usable
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		var value string
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+		assert.Equal(t, int64(1), c.Stats().Sets)
+	})
+
+	t.Run("["+Redis.String()+"] - not supported", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.ErrorIs(t, c.Clear(context.Background()), ErrEngineNotSupported)
+		assert.True(t, r.Exists(testKey))
+	})
+
+	t.Run("context is done", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, c.Clear(ctx), ErrContextDone)
+	})
+}
+
+// TestClient_EmptyCache will test the method EmptyCache()
+func TestClient_EmptyCache(t *testing.T) {
+
+	t.Run("["+Redis.String()+"] - namespaced clients only remove their own keys", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		first, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("first:"))
+		require.NoError(t, err)
+		defer first.Close(context.Background())
+
+		var second ClientInterface
+		second, err = NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("second:"))
+		require.NoError(t, err)
+		defer second.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, first.Set(context.Background(), testKey+strconv.Itoa(i), testValue))
+			require.NoError(t, second.Set(context.Background(), testKey+strconv.Itoa(i), testValue))
+		}
+		require.NoError(t, r.Set("other-app:"+testKey, testValue))
+
+		// Empty the first namespace
+		require.NoError(t, first.EmptyCache(context.Background()))
+		assert.Len(t, r.Keys(), 6)
+
+		var found bool
+		found, err = first.Exists(context.Background(), testKey+"0")
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		found, err = second.Exists(context.Background(), testKey+"0")
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		// Empty the second namespace
+		require.NoError(t, second.EmptyCache(context.Background()))
+		assert.Equal(t, []string{"other-app:" + testKey}, r.Keys())
+	})
+
+	t.Run("["+Redis.String()+"] - no namespace only flushes the database", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr() + "/1"}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, r.Set(testKey, testValue)) // Database 0
+		assert.Equal(t, []string{testKey}, r.DB(1).Keys())
+
+		require.NoError(t, c.EmptyCache(context.Background()))
+		assert.Empty(t, r.DB(1).Keys())
+		assert.Equal(t, []string{testKey}, r.Keys())
+	})
+
+	t.Run("["+FreeCache.String()+"] - no namespace removes all the keys", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, c.EmptyCache(context.Background()))
+		assert.Equal(t, int64(0), c.FreeCache().EntryCount())
+	})
+}
+
+// TestClient_EmptyCachePattern will test the method EmptyCachePattern()
+func TestClient_EmptyCachePattern(t *testing.T) {
+
+	t.Run("["+FreeCache.String()+"] - not supported", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.EmptyCachePattern(context.Background(), "tenant:1:*")
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("["+Redis.String()+"] - empty pattern", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.EmptyCachePattern(context.Background(), " ")
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("["+Redis.String()+"] - only the matching keys are removed", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		// Record the commands (SCAN is used, never KEYS)
+		var mu sync.Mutex
+		var commands []string
+		r.Server().SetPreHook(func(_ *server.Peer, cmd string, _ ...string) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			commands = append(commands, cmd)
+			return false
+		})
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(3))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, c.Set(context.Background(), "tenant:1:"+strconv.Itoa(i), testValue))
+		}
+		require.NoError(t, c.Set(context.Background(), "tenant:10:0", testValue))
+		require.NoError(t, c.Set(context.Background(), "tenant:2:0", testValue))
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		var total int
+		total, err = c.EmptyCachePattern(context.Background(), "tenant:1:*")
+		require.NoError(t, err)
+		assert.Equal(t, 10, total)
+		assert.ElementsMatch(t, []string{testKey, "tenant:10:0", "tenant:2:0"}, r.Keys())
+
+		mu.Lock()
+		assert.Contains(t, commands, scanCommand)
+		assert.NotContains(t, commands, "KEYS")
+		mu.Unlock()
+
+		// Nothing left to remove
+		total, err = c.EmptyCachePattern(context.Background(), "tenant:1:*")
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("["+Redis.String()+"] - stops between the scans when the context is done", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithScanCount(2))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 10; i++ {
+			require.NoError(t, r.Set("tenant:1:"+strconv.Itoa(i), testValue))
+		}
+
+		// Cancel the context during the first SCAN
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var scans atomic.Int64
+		r.Server().SetPreHook(func(_ *server.Peer, cmd string, _ ...string) bool {
+			if cmd == scanCommand && scans.Add(1) == 1 {
+				cancel()
+			}
+			return false
+		})
+
+		_, err = c.EmptyCachePattern(ctx, "tenant:1:*")
+		require.ErrorIs(t, err, ErrContextDone)
+		assert.Equal(t, int64(1), scans.Load())
+		assert.Len(t, r.Keys(), 10)
+	})
+}
+
+// TestClient_Flush will test the method Flush()// TestClient_Flush will test the method Flush()
+func TestClient_Flush(t *testing.T) {
+
+	t.Run("["+Redis.String()+"] - no namespace is guarded", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, r.Set("other-app:"+testKey, testValue))
+
+		require.ErrorIs(t, c.Flush(context.Background()), ErrUnsafeFlush)
+		assert.Len(t, r.Keys(), 2)
+	})
+
+	t.Run("["+Redis.String()+"] - no namespace with the opt-in flushes the database", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithAllowUnsafeFlush())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, r.Set("other-app:"+testKey, testValue))
+
+		require.NoError(t, c.Flush(context.Background()))
+		assert.Empty(t, r.Keys())
+	})
+
+	t.Run("["+Redis.String()+"] - namespace only removes its own keys", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, r.Set("other-app:"+testKey, testValue))
+
+		require.NoError(t, c.Flush(context.Background()))
+		assert.Equal(t, []string{"other-app:" + testKey}, r.Keys())
+	})
+
+	t.Run("["+FreeCache.String()+"] - no namespace is guarded", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.ErrorIs(t, c.Flush(context.Background()), ErrUnsafeFlush)
+		assert.Equal(t, int64(1), c.FreeCache().EntryCount())
+	})
+
+	t.Run("["+FreeCache.String()+"] - namespace and opt-in", func(t *testing.T) {
+		freeClient := loadFreeCache(DefaultCacheSize, DefaultGCPercent, realClock{})
+
+		c, err := NewClient(context.Background(), WithFreeCacheConnection(freeClient), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, freeClient.Set([]byte(testKey), []byte(testValue), 0))
+
+		require.NoError(t, c.Flush(context.Background()))
+		assert.Equal(t, int64(1), freeClient.EntryCount())
+
+		var unsafe ClientInterface
+		unsafe, err = NewClient(context.Background(), WithFreeCacheConnection(freeClient), WithAllowUnsafeFlush())
+		require.NoError(t, err)
+		require.NoError(t, unsafe.Flush(context.Background()))
+		assert.Equal(t, int64(0), freeClient.EntryCount())
+	})
+}
+
 // TestClient_Debug will test the method Debug()
 func TestClient_Debug(t *testing.T) {
 	t.Parallel()
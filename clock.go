@@ -0,0 +1,28 @@
+package cachestore
+
+import (
+	"time"
+)
+
+// Clock is the source of the current time used for the expirations (see: WithClock)
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default clock (system time)
+type realClock struct{}
+
+// Now will return the current system time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// freeCacheTimer is the FreeCache timer (unix seconds) using the clock
+type freeCacheTimer struct {
+	clock Clock
+}
+
+// Now will return the current time of the clock in unix seconds
+func (t freeCacheTimer) Now() uint32 {
+	return uint32(t.clock.Now().Unix()) //nolint:gosec // unix seconds fit until 2106 (same as FreeCache)
+}
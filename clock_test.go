@@ -0,0 +1,87 @@
+package cachestore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock is a fake clock that follows the system time and can be advanced instantly
+type testClock struct {
+	offset time.Duration
+	sync.Mutex
+}
+
+// Now will return the system time plus the time advanced
+func (c *testClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return time.Now().Add(c.offset)
+}
+
+// Advance will move the clock forward
+func (c *testClock) Advance(duration time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.offset += duration
+}
+
+// Test_freeCacheTimer will test the FreeCache timer using a clock
+func Test_freeCacheTimer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("real clock", func(t *testing.T) {
+		timer := freeCacheTimer{clock: realClock{}}
+		assert.InDelta(t, time.Now().Unix(), int64(timer.Now()), 1)
+	})
+
+	t.Run("advanced clock", func(t *testing.T) {
+		clock := &testClock{}
+		clock.Advance(time.Hour)
+		timer := freeCacheTimer{clock: clock}
+		assert.InDelta(t, time.Now().Add(time.Hour).Unix(), int64(timer.Now()), 1)
+	})
+}
+
+// TestClient_Clock will test that the expirations use the clock (FreeCache)
+func TestClient_Clock(t *testing.T) {
+
+	t.Run("keys expire when the clock is advanced", func(t *testing.T) {
+		clock := &testClock{}
+		c, err := NewClient(context.Background(), WithFreeCache(), WithClock(clock))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetTTL(context.Background(), testKey, testValue, time.Minute))
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+
+		clock.Advance(2 * time.Minute)
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("counters keep the remaining ttl", func(t *testing.T) {
+		clock := &testClock{}
+		c, err := NewClient(context.Background(), WithFreeCache(), WithClock(clock))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetTTL(context.Background(), testKey, "1", time.Hour))
+		clock.Advance(30 * time.Minute)
+
+		_, err = c.Increment(context.Background(), testKey, 1)
+		require.NoError(t, err)
+
+		ttl, _ := c.FreeCache().TTL([]byte(testKey))
+		assert.InDelta(t, (30 * time.Minute).Seconds(), float64(ttl), 2)
+	})
+}
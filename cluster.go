@@ -0,0 +1,326 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/mna/redisc"
+	"github.com/mrz1836/go-cache"
+	"github.com/mrz1836/go-cache/nrredis"
+)
+
+// Redis cluster settings
+const (
+	clusterMaxRedirects  = 5                      // Max attempts when following MOVED and ASK redirections
+	clusterTryAgainDelay = 100 * time.Millisecond // Delay before retrying a TRYAGAIN error (resharding)
+)
+
+// clusterPool is the pool of connections to a redis cluster (routed by the key slot)
+type clusterPool struct {
+	nrredis.Pool                 // Connections (wrapped by NewRelic if enabled)
+	cluster      *redisc.Cluster // Cluster (slot mapping and a pool for each node)
+}
+
+// clusterConnections will get the connections from the cluster (implements nrredis.Pool)
+type clusterConnections struct {
+	cluster *redisc.Cluster
+}
+
+// ActiveCount will return the number of connections of all the nodes
+func (p *clusterConnections) ActiveCount() int {
+	return p.Stats().ActiveCount
+}
+
+// Close will close the pools of all the nodes
+func (p *clusterConnections) Close() error {
+	return p.cluster.Close()
+}
+
+// Get will return a connection that is bound to the node of the first key used
+func (p *clusterConnections) Get() redis.Conn {
+	return newClusterConn(p.cluster.Get())
+}
+
+// GetContext will return a connection that is bound to the node of the first key used
+func (p *clusterConnections) GetContext(ctx context.Context) (redis.Conn, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return p.Get(), nil
+}
+
+// IdleCount will return the number of idle connections of all the nodes
+func (p *clusterConnections) IdleCount() int {
+	return p.Stats().IdleCount
+}
+
+// Stats will return the combined statistics of the pools of all the nodes
+func (p *clusterConnections) Stats() (stats redis.PoolStats) {
+	for _, nodeStats := range p.cluster.Stats() {
+		stats.ActiveCount += nodeStats.ActiveCount
+		stats.IdleCount += nodeStats.IdleCount
+		stats.WaitCount += nodeStats.WaitCount
+		stats.WaitDuration += nodeStats.WaitDuration
+	}
+	return
+}
+
+// clusterConn is a cluster connection that follows the MOVED and ASK redirections
+//
+// Commands that are sent (pipelines) are not redirected, all keys must belong to the same slot
+type clusterConn struct {
+	redis.Conn            // Connection (bound to the node of the first key)
+	retry      redis.Conn // Connection that follows the redirections
+}
+
+// newClusterConn will create a new cluster connection
+func newClusterConn(conn redis.Conn) redis.Conn {
+	retry, err := redisc.RetryConn(conn, clusterMaxRedirects, clusterTryAgainDelay)
+	if err != nil {
+		return conn
+	}
+	return &clusterConn{Conn: conn, retry: retry}
+}
+
+// Do will fire the command and follow any redirections
+func (c *clusterConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	if len(commandName) == 0 { // Flush the pipeline and receive all the replies
+		return c.Conn.Do(commandName, args...)
+	}
+	return c.retry.Do(commandName, args...)
+}
+
+// isCluster will return true if the config is using a Redis Cluster
+func (r *RedisConfig) isCluster() bool {
+	return len(r.ClusterAddresses) > 0
+}
+
+// validateCluster will validate the Redis Cluster configuration
+func (r *RedisConfig) validateCluster() error {
+	if r.isCluster() && r.isSentinel() {
+		return fmt.Errorf("%w: cluster addresses cannot be used with sentinel", ErrInvalidRedisConfig)
+	} else if r.isCluster() && (r.Database > 0 || (r.database != nil && *r.database > 0)) {
+		return fmt.Errorf("%w: a cluster only supports database 0", ErrInvalidRedisConfig)
+	}
+	return nil
+}
+
+// connectCluster will create a client for the redis cluster
+//
+// The startup nodes are used to discover the slot mapping, which is refreshed
+// automatically when a node replies with a MOVED redirection
+// NOTE: a cluster only supports database 0 and the dependency scripts are not registered
+func connectCluster(config *RedisConfig, newRelicEnabled bool) (*cache.Client, error) {
+
+	// Get the dial options (credentials from the URL)
+	options, err := clusterDialOptions(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the cluster (a pool for each node)
+	cluster := &redisc.Cluster{
+		StartupNodes: config.ClusterAddresses,
+		DialOptions:  options,
+		CreatePool: func(address string, options ...redis.DialOption) (*redis.Pool, error) {
+			return &redis.Pool{
+				Dial: func() (redis.Conn, error) {
+					return redis.Dial("tcp", address, options...)
+				},
+				IdleTimeout:     config.MaxIdleTimeout,
+				MaxActive:       config.MaxActiveConnections,
+				MaxConnLifetime: config.MaxConnectionLifetime,
+				MaxIdle:         config.MaxIdleConnections,
+				TestOnBorrow: func(c redis.Conn, t time.Time) error {
+					if time.Since(t) < time.Minute {
+						return nil
+					}
+					_, doErr := c.Do(cache.PingCommand)
+					return doErr
+				},
+			}, nil
+		},
+	}
+
+	// Load the slot mapping
+	if err = cluster.Refresh(); err != nil {
+		_ = cluster.Close()
+		return nil, err
+	}
+
+	// Wrap if NewRelic is enabled
+	pool := &clusterPool{
+		Pool:    &clusterConnections{cluster: cluster},
+		cluster: cluster,
+	}
+	if newRelicEnabled {
+		pool.Pool = nrredis.Wrap(
+			pool.Pool,
+			nrredis.WithHost(strings.Join(config.ClusterAddresses, ",")),
+		)
+	}
+	return &cache.Client{Pool: pool}, nil
+}
+
+// clusterDialOptions will return the dial options for each node (TLS, timeouts, username and password)
+//
+// The Password field is preferred over a password in the URL, the Username field is the ACL user (AUTH username password)
+func clusterDialOptions(config *RedisConfig) ([]redis.DialOption, error) {
+	options := config.dialOptions()
+	username, password := config.Username, config.Password
+	if len(password) == 0 && len(config.URL) > 0 {
+		u, err := url.Parse(config.URL)
+		if err != nil {
+			return nil, err
+		} else if u.User != nil {
+			password, _ = u.User.Password()
+		}
+	}
+	if len(password) == 0 {
+		return options, nil
+	} else if len(username) > 0 { // AUTH username password (redis 6+ ACL)
+		options = append(options, redis.DialUsername(username))
+	}
+	return append(options, redis.DialPassword(password)), nil
+}
+
+// checkRedirect will replace a MOVED or ASK redirection with ErrClusterModeRequired (single node client only)
+//
+// The replies of a pipeline (flushed using an empty command) are checked for a redirection as well
+func checkRedirect(commandName string, reply interface{}, err error) (interface{}, error) {
+	if err != nil {
+		if redisc.ParseRedir(err) != nil {
+			return nil, fmt.Errorf("%w: %w", ErrClusterModeRequired, err)
+		}
+		return reply, err
+	} else if replies, ok := reply.([]interface{}); ok && len(commandName) == 0 {
+		for _, r := range replies {
+			if replyErr, isErr := r.(redis.Error); isErr && redisc.ParseRedir(replyErr) != nil {
+				return nil, fmt.Errorf("%w: %w", ErrClusterModeRequired, replyErr)
+			}
+		}
+	}
+	return reply, nil
+}
+
+// redisCluster will return the cluster if the client is connected to a redis cluster
+func redisCluster(client *cache.Client) (*redisc.Cluster, bool) {
+	if client == nil {
+		return nil, false
+	}
+	if pool, ok := client.Pool.(*clusterPool); ok {
+		return pool.cluster, true
+	}
+	return nil, false
+}
+
+// isRedisCluster will return true if the client is connected to a redis cluster
+func isRedisCluster(client *cache.Client) bool {
+	_, ok := redisCluster(client)
+	return ok
+}
+
+// groupKeysBySlot will group the keys by slot for a redis cluster (multi-key commands)
+//
+// A single group with all the keys is returned if the client is not a cluster
+func groupKeysBySlot(client *cache.Client, keys []string) [][]string {
+	if !isRedisCluster(client) {
+		return [][]string{keys}
+	}
+	return redisc.SplitBySlot(keys...)
+}
+
+// flushCluster will flush the keys of all the master nodes
+func flushCluster(cluster *redisc.Cluster) error {
+	return cluster.EachNode(false, func(_ string, conn redis.Conn) error {
+		_, err := conn.Do(flushDBCommand)
+		return err
+	})
+}
+
+// linkDependenciesCluster will link the key to each dependency (a command per dependency)
+//
+// The dependencies are stored on other nodes, so they cannot be linked in the same transaction
+func linkDependenciesCluster(ctx context.Context, client *cache.Client, key string, dependencies ...string) error {
+	for _, dependency := range dependencies {
+		if _, err := doRedis(ctx, client, cache.AddToSetCommand, cache.DependencyPrefix+dependency, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trimDependencyCluster will record the order of the keys and unlink the oldest keys over maxKeys (a command per step)
+//
+// The dependency set and the order are on different slots, so the steps of trimDependencyScript cannot be in a script
+func trimDependencyCluster(ctx context.Context, client *cache.Client, dependency string, maxKeys int, linked int64,
+	keys ...string) (trimmed int, err error) {
+	set, order := cache.DependencyPrefix+dependency, dependencyOrderPrefix+dependency
+
+	// Record the order of the new keys (after the newest link)
+	var newest []interface{}
+	if newest, err = redis.Values(doRedis(ctx, client, sortedSetRangeCommand, order, -1, -1, "WITHSCORES")); err != nil {
+		return trimmed, err
+	} else if len(newest) == 2 {
+		var score int64
+		if score, err = redis.Int64(newest[1], nil); err != nil {
+			return trimmed, err
+		} else if score >= linked {
+			linked = score + 1
+		}
+	}
+	for _, key := range keys {
+		if _, err = doRedis(ctx, client, sortedSetAddCommand, order, "NX", linked, key); err != nil {
+			return trimmed, err
+		}
+	}
+
+	// Unlink the oldest keys over the max
+	var over int
+	if over, err = redis.Int(doRedis(ctx, client, setCardinalityCommand, set)); err != nil {
+		return trimmed, err
+	}
+	for over -= maxKeys; over > 0; {
+		var oldest []string
+		if oldest, err = redis.Strings(doRedis(ctx, client, sortedSetRangeCommand, order, 0, over-1)); err != nil ||
+			len(oldest) == 0 {
+			return trimmed, err
+		}
+		for _, key := range oldest {
+			if _, err = doRedis(ctx, client, sortedSetRemCommand, order, key); err != nil {
+				return trimmed, err
+			}
+			var removed int
+			if removed, err = redis.Int(doRedis(ctx, client, cache.RemoveMemberCommand, set, key)); err != nil {
+				return trimmed, err
+			}
+			trimmed += removed
+			over -= removed
+		}
+	}
+
+	// Remove the order of keys no longer in the set (once the order is twice the max)
+	var count int
+	if count, err = redis.Int(doRedis(ctx, client, sortedSetCountCommand, order)); err != nil || count <= 2*maxKeys {
+		return trimmed, err
+	}
+	var ordered []string
+	if ordered, err = redis.Strings(doRedis(ctx, client, sortedSetRangeCommand, order, 0, -1)); err != nil {
+		return trimmed, err
+	}
+	for _, key := range ordered {
+		var member bool
+		if member, err = redis.Bool(doRedis(ctx, client, cache.IsMemberCommand, set, key)); err != nil {
+			return trimmed, err
+		} else if !member {
+			if _, err = doRedis(ctx, client, sortedSetRemCommand, order, key); err != nil {
+				return trimmed, err
+			}
+		}
+	}
+	return trimmed, nil
+}
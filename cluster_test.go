@@ -0,0 +1,554 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/gomodule/redigo/redis"
+	"github.com/mna/redisc"
+	"github.com/mrz1836/go-cache"
+	"github.com/rafaeljusto/redigomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClusterNode is an in-memory redis node that serves a range of slots
+type testClusterNode struct {
+	first  int
+	last   int
+	server *miniredis.Miniredis
+}
+
+// loadRedisInMemoryCluster will load an in-memory redis cluster with two master nodes
+//
+// Each node replies with a MOVED redirection for keys of slots it does not serve
+func loadRedisInMemoryCluster(t *testing.T) []*testClusterNode {
+	nodes := []*testClusterNode{
+		{first: 0, last: redisc.HashSlots/2 - 1, server: miniredis.RunT(t)},
+		{first: redisc.HashSlots / 2, last: redisc.HashSlots - 1, server: miniredis.RunT(t)},
+	}
+	for _, node := range nodes {
+		current := node
+		current.server.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+			if cmd == "CLUSTER" && len(args) > 0 && strings.EqualFold(args[0], "SLOTS") {
+				writeTestClusterSlots(c, nodes)
+				return true
+			}
+
+			// Check that all keys belong to the same slot and this node serves it
+			slot := -1
+			for _, key := range testClusterCommandKeys(cmd, args) {
+				keySlot := redisc.Slot(key)
+				if slot != -1 && keySlot != slot {
+					c.WriteError("CROSSSLOT Keys in request don't hash to the same slot")
+					return true
+				}
+				slot = keySlot
+			}
+			if slot == -1 || (slot >= current.first && slot <= current.last) {
+				return false
+			}
+			for _, owner := range nodes {
+				if slot >= owner.first && slot <= owner.last {
+					c.WriteError(fmt.Sprintf("MOVED %d %s", slot, owner.server.Addr()))
+				}
+			}
+			return true
+		})
+	}
+	return nodes
+}
+
+// writeTestClusterSlots will write the reply of the CLUSTER SLOTS command
+func writeTestClusterSlots(c *server.Peer, nodes []*testClusterNode) {
+	c.WriteLen(len(nodes))
+	for _, node := range nodes {
+		c.WriteLen(3)
+		c.WriteInt(node.first)
+		c.WriteInt(node.last)
+		c.WriteLen(2)
+		c.WriteBulk(node.server.Host())
+		port, _ := strconv.Atoi(node.server.Port())
+		c.WriteInt(port)
+	}
+}
+
+// testClusterCommandKeys will return the keys used by the command
+func testClusterCommandKeys(cmd string, args []string) []string {
+	switch cmd {
+	case "DEL", "EXISTS", "MGET":
+		return args
+	case "MSET":
+		keys := make([]string, 0, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			keys = append(keys, args[i])
+		}
+		return keys
+	case "EVAL", "EVALSHA":
+		if len(args) < 2 {
+			return nil
+		}
+		numKeys, _ := strconv.Atoi(args[1])
+		if len(args) < 2+numKeys {
+			return nil
+		}
+		return args[2 : 2+numKeys]
+	case "DECRBY", "GET", "INCRBY", "PEXPIRE", "SADD", "SCARD", "SET", "SETEX", "SISMEMBER", "SMEMBERS", "SREM", "TTL",
+		"ZADD", "ZCARD", "ZRANGE", "ZREM":
+		if len(args) > 0 {
+			return args[:1]
+		}
+	}
+	return nil
+}
+
+// testClusterKeys will return keys that are spread over all the nodes of the cluster
+func testClusterKeys(t *testing.T, nodes []*testClusterNode, prefix string) []string {
+	var keys []string
+	served := make(map[*testClusterNode]bool)
+	for i := 0; len(served) < len(nodes) || len(keys) < 6; i++ {
+		key := prefix + strconv.Itoa(i)
+		for _, node := range nodes {
+			if slot := redisc.Slot(key); slot >= node.first && slot <= node.last {
+				served[node] = true
+			}
+		}
+		keys = append(keys, key)
+	}
+	require.NotEmpty(t, keys)
+	return keys
+}
+
+// owner will return the node that serves the key
+func testClusterOwner(nodes []*testClusterNode, key string) *miniredis.Miniredis {
+	slot := redisc.Slot(key)
+	for _, node := range nodes {
+		if slot >= node.first && slot <= node.last {
+			return node.server
+		}
+	}
+	return nil
+}
+
+// newClusterTestClient will return a new client connected to the in-memory cluster
+func newClusterTestClient(t *testing.T, nodes []*testClusterNode, opts ...ClientOps) ClientInterface {
+	opts = append([]ClientOps{WithRedis(&RedisConfig{
+		ClusterAddresses:   []string{nodes[0].server.Addr()},
+		MaxIdleConnections: 5,
+	})}, opts...)
+	c, err := NewClient(context.Background(), opts...)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c
+}
+
+// TestRedisConfig_validateCluster will test the method validateCluster()
+func TestRedisConfig_validateCluster(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no cluster", func(t *testing.T) {
+		config := &RedisConfig{URL: testLocalConnectionURL}
+		assert.False(t, config.isCluster())
+		require.NoError(t, config.validateCluster())
+	})
+
+	t.Run("valid cluster", func(t *testing.T) {
+		config := &RedisConfig{ClusterAddresses: []string{"localhost:7000"}}
+		assert.True(t, config.isCluster())
+		require.NoError(t, config.validateCluster())
+	})
+
+	t.Run("cluster and sentinel", func(t *testing.T) {
+		config := &RedisConfig{
+			ClusterAddresses:  []string{"localhost:7000"},
+			MasterName:        testMasterName,
+			SentinelAddresses: []string{"localhost:26379"},
+		}
+		require.ErrorIs(t, config.validateCluster(), ErrInvalidRedisConfig)
+	})
+
+	t.Run("cluster with a database", func(t *testing.T) {
+		config := &RedisConfig{ClusterAddresses: []string{"localhost:7000"}, Database: 1}
+		require.ErrorIs(t, config.validateCluster(), ErrInvalidRedisConfig)
+	})
+}
+
+// Test_clusterDialOptions will test the method clusterDialOptions()
+func Test_clusterDialOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no url", func(t *testing.T) {
+		options, err := clusterDialOptions(&RedisConfig{})
+		require.NoError(t, err)
+		assert.Len(t, options, 1)
+	})
+
+	t.Run("url with a password", func(t *testing.T) {
+		options, err := clusterDialOptions(&RedisConfig{URL: "redis://:secret@localhost:7000"})
+		require.NoError(t, err)
+		assert.Len(t, options, 2)
+	})
+
+	t.Run("password field is preferred", func(t *testing.T) {
+		options, err := clusterDialOptions(&RedisConfig{Password: "secret", URL: "redis://bad url:%%"})
+		require.NoError(t, err)
+		assert.Len(t, options, 2)
+	})
+
+	t.Run("username and password", func(t *testing.T) {
+		options, err := clusterDialOptions(&RedisConfig{Password: "secret", Username: "svc"})
+		require.NoError(t, err)
+		assert.Len(t, options, 3)
+
+		options, err = clusterDialOptions(&RedisConfig{URL: "redis://:secret@localhost:7000", Username: "svc"})
+		require.NoError(t, err)
+		assert.Len(t, options, 3)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		_, err := clusterDialOptions(&RedisConfig{URL: "redis://bad url:%%"})
+		require.Error(t, err)
+	})
+}
+
+// Test_groupKeysBySlot will test the method groupKeysBySlot()
+func Test_groupKeysBySlot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not a cluster", func(t *testing.T) {
+		keys := []string{"key1", "key2", "key3"}
+		assert.Equal(t, [][]string{keys}, groupKeysBySlot(&cache.Client{}, keys))
+		assert.Equal(t, [][]string{keys}, groupKeysBySlot(nil, keys))
+	})
+
+	t.Run("cluster groups by slot", func(t *testing.T) {
+		client := &cache.Client{Pool: &clusterPool{cluster: &redisc.Cluster{}}}
+		groups := groupKeysBySlot(client, []string{"{user1}:a", "{user2}:a", "{user1}:b"})
+		assert.Len(t, groups, 2)
+		for _, group := range groups {
+			slot := redisc.Slot(group[0])
+			for _, key := range group {
+				assert.Equal(t, slot, redisc.Slot(key))
+			}
+		}
+	})
+}
+
+// TestClient_Cluster will test using a Redis Cluster
+func TestClient_Cluster(t *testing.T) {
+
+	t.Run("no nodes available", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+			ClusterAddresses: []string{"127.0.0.1:1"},
+		}))
+		require.Error(t, err)
+		require.Nil(t, c)
+	})
+
+	t.Run("cluster config", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes, WithNewRelic())
+		assert.Equal(t, Redis, c.Engine())
+		assert.Equal(t, []string{nodes[0].server.Addr()}, c.RedisConfig().ClusterAddresses)
+		assert.True(t, isRedisCluster(c.Redis()))
+	})
+
+	t.Run("set, get and delete are routed by slot", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "key-")
+		for _, key := range keys {
+			err := c.Set(context.Background(), key, testValue+key, testDependantKey)
+			require.NoError(t, err)
+
+			val, err := testClusterOwner(nodes, key).Get(key)
+			require.NoError(t, err)
+			assert.Equal(t, testValue+key, val)
+		}
+
+		for _, key := range keys {
+			val, err := c.Get(context.Background(), key)
+			require.NoError(t, err)
+			assert.Equal(t, testValue+key, val)
+
+			err = c.Delete(context.Background(), key)
+			require.NoError(t, err)
+			assert.False(t, testClusterOwner(nodes, key).Exists(key))
+		}
+
+		// All keys are linked to the dependency
+		members, err := testClusterOwner(nodes, cache.DependencyPrefix+testDependantKey).Members(
+			cache.DependencyPrefix + testDependantKey,
+		)
+		require.NoError(t, err)
+		assert.Len(t, members, len(keys))
+	})
+
+	t.Run("set ttl, models, counters, touch and locks", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		for _, key := range testClusterKeys(t, nodes, "key-") {
+			err := c.SetTTL(context.Background(), key, testValue, testIdleTimeout, testDependantKey)
+			require.NoError(t, err)
+
+			err = c.Touch(context.Background(), key, 0)
+			require.NoError(t, err)
+
+			model := &genericStruct{StringField: key}
+			err = c.SetModel(context.Background(), key+"-model", model, 0)
+			require.NoError(t, err)
+
+			result := new(genericStruct)
+			err = c.GetModel(context.Background(), key+"-model", result)
+			require.NoError(t, err)
+			assert.Equal(t, model, result)
+
+			var value int64
+			value, err = c.Increment(context.Background(), key+"-counter", 5)
+			require.NoError(t, err)
+			assert.Equal(t, int64(5), value)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), key+"-lock", 30)
+			require.NoError(t, err)
+
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), key+"-lock", secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+		}
+	})
+
+	t.Run("multi-key operations are handled per slot", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "multi-")
+		items := make(map[string]string, len(keys))
+		for _, key := range keys {
+			items[key] = testValue + key
+		}
+
+		err := c.SetMulti(context.Background(), items, testDependantKey)
+		require.NoError(t, err)
+
+		for _, key := range keys {
+			assert.True(t, testClusterOwner(nodes, key).Exists(key))
+		}
+
+		var values map[string]string
+		values, err = c.GetMulti(context.Background(), append(keys, "missing-key")...)
+		require.NoError(t, err)
+		assert.Equal(t, items, values)
+
+		err = c.DeleteMany(context.Background(), keys...)
+		require.NoError(t, err)
+		for _, key := range keys {
+			assert.False(t, testClusterOwner(nodes, key).Exists(key))
+		}
+	})
+
+	t.Run("the dependency sets are bounded", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes, WithMaxDependencyKeys(3))
+
+		// A dependency with the set and the order on different nodes
+		var dependency string
+		for i := 0; len(dependency) == 0; i++ {
+			candidate := testDependantKey + "-" + strconv.Itoa(i)
+			if testClusterOwner(nodes, cache.DependencyPrefix+candidate) !=
+				testClusterOwner(nodes, dependencyOrderPrefix+candidate) {
+				dependency = candidate
+			}
+		}
+
+		keys := testClusterKeys(t, nodes, "bounded-")
+		for _, key := range keys {
+			require.NoError(t, c.Set(context.Background(), key, testValue, dependency))
+		}
+		members, err := testClusterOwner(nodes, cache.DependencyPrefix+dependency).Members(cache.DependencyPrefix + dependency)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, keys[len(keys)-3:], members)
+
+		require.NoError(t, c.DeleteDependency(context.Background(), dependency))
+		assert.False(t, testClusterOwner(nodes, dependencyOrderPrefix+dependency).Exists(dependencyOrderPrefix+dependency))
+		for i, key := range keys {
+			assert.Equal(t, i < len(keys)-3, testClusterOwner(nodes, key).Exists(key), key)
+		}
+	})
+
+	t.Run("empty cache flushes all the master nodes", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "key-")
+		for _, key := range keys {
+			require.NoError(t, c.Set(context.Background(), key, testValue))
+		}
+
+		err := c.EmptyCache(context.Background())
+		require.NoError(t, err)
+
+		for _, node := range nodes {
+			assert.Empty(t, node.server.Keys())
+		}
+	})
+
+	t.Run("delete by pattern scans all the master nodes", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "pattern-")
+		for _, key := range keys {
+			require.NoError(t, c.Set(context.Background(), key, testValue))
+		}
+		require.NoError(t, c.Set(context.Background(), "other-key", testValue))
+
+		total, err := c.DeleteByPattern(context.Background(), "pattern-*")
+		require.NoError(t, err)
+		assert.Equal(t, len(keys), total)
+
+		var found bool
+		found, err = c.Exists(context.Background(), "other-key")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("empty cache with a key prefix scans all the master nodes", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes, WithKeyPrefix("prefix:"))
+		plain := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "key-")
+		for _, key := range keys {
+			require.NoError(t, c.Set(context.Background(), key, testValue))
+			require.NoError(t, plain.Set(context.Background(), key, testValue))
+		}
+
+		err := c.EmptyCache(context.Background())
+		require.NoError(t, err)
+
+		for _, key := range keys {
+			assert.False(t, testClusterOwner(nodes, "prefix:"+key).Exists("prefix:"+key))
+			assert.True(t, testClusterOwner(nodes, key).Exists(key))
+		}
+	})
+}
+
+// mockContextConn is a mocked redis connection that supports a context (required by the context pool)
+type mockContextConn struct {
+	*redigomock.Conn
+}
+
+// DoContext will fire the mocked command (the context is not used)
+func (m mockContextConn) DoContext(_ context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	return m.Do(commandName, args...)
+}
+
+// ReceiveContext will receive the mocked reply (the context is not used)
+func (m mockContextConn) ReceiveContext(_ context.Context) (interface{}, error) {
+	return m.Receive()
+}
+
+// Test_checkRedirect will test the method checkRedirect()
+func Test_checkRedirect(t *testing.T) {
+	t.Parallel()
+
+	t.Run("moved and ask redirections", func(t *testing.T) {
+		for _, redirect := range []redis.Error{"MOVED 3999 127.0.0.1:6381", "ASK 3999 127.0.0.1:6381"} {
+			reply, err := checkRedirect(cache.GetCommand, nil, redirect)
+			require.ErrorIs(t, err, ErrClusterModeRequired)
+			assert.Nil(t, reply)
+
+			// The redirection is kept
+			var redisErr redis.Error
+			require.ErrorAs(t, err, &redisErr)
+			assert.Equal(t, redirect, redisErr)
+		}
+	})
+
+	t.Run("other errors and replies are returned as is", func(t *testing.T) {
+		reply, err := checkRedirect(cache.GetCommand, nil, redis.Error("WRONGTYPE Operation against a key"))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrClusterModeRequired)
+		assert.Nil(t, reply)
+
+		reply, err = checkRedirect(cache.GetCommand, testValue, nil)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, reply)
+
+		reply, err = checkRedirect(multiGetCommand, []interface{}{redis.Error("MOVED 3999 127.0.0.1:6381")}, nil)
+		require.NoError(t, err)
+		assert.Len(t, reply, 1)
+	})
+
+	t.Run("pipeline replies", func(t *testing.T) {
+		_, err := checkRedirect("", []interface{}{testValue, redis.Error("MOVED 3999 127.0.0.1:6381")}, nil)
+		require.ErrorIs(t, err, ErrClusterModeRequired)
+
+		reply, err := checkRedirect("", []interface{}{testValue, int64(1)}, nil)
+		require.NoError(t, err)
+		assert.Len(t, reply, 2)
+	})
+}
+
+// TestClient_ClusterModeRequired will test the error of a single node client connected to a cluster node
+func TestClient_ClusterModeRequired(t *testing.T) {
+
+	t.Run("[mock] - moved redirection", func(t *testing.T) {
+		conn := redigomock.NewConn()
+		c, err := NewClient(context.Background(), WithRedisConnection(&cache.Client{
+			Pool: &contextPool{Pool: &redis.Pool{
+				Dial: func() (redis.Conn, error) { return mockContextConn{Conn: conn}, nil },
+			}},
+		}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		moved := redis.Error("MOVED 3999 127.0.0.1:6381")
+		conn.Command(cache.GetCommand, testKey).ExpectError(moved)
+		conn.Command(cache.SetCommand, testKey, testValue).ExpectError(moved)
+		conn.Command(cache.GetCommand, testKey+"-pipeline").Expect(redis.Error("ASK 3999 127.0.0.1:6381"))
+		conn.Command(pTTLCommand, testKey+"-pipeline").Expect(int64(-1))
+
+		_, err = c.Get(context.Background(), testKey)
+		require.ErrorIs(t, err, ErrClusterModeRequired)
+		assert.Contains(t, err.Error(), "ClusterAddresses")
+
+		err = c.Set(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, ErrClusterModeRequired)
+
+		_, err = c.GetModelWithTTL(context.Background(), testKey+"-pipeline", new(genericStruct))
+		require.ErrorIs(t, err, ErrClusterModeRequired)
+	})
+
+	t.Run("url of a cluster node", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: nodes[0].server.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		// Keys served by the node work, the other keys are redirected
+		var served, moved int
+		for _, key := range testClusterKeys(t, nodes, "key-") {
+			if err = c.Set(context.Background(), key, testValue); err == nil {
+				served++
+				continue
+			}
+			require.ErrorIs(t, err, ErrClusterModeRequired)
+			moved++
+		}
+		assert.Positive(t, served)
+		assert.Positive(t, moved)
+	})
+}
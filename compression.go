@@ -0,0 +1,156 @@
+package cachestore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// CompressionType is the algorithm used to compress values
+type CompressionType string
+
+// Supported compression types
+const (
+	CompressionNone   CompressionType = "none"   // No compression (default)
+	CompressionGzip   CompressionType = "gzip"   // Gzip (compress/gzip)
+	CompressionSnappy CompressionType = "snappy" // Snappy (github.com/golang/snappy)
+)
+
+const (
+
+	// DefaultCompressionThreshold is the minimum size (in bytes) of a value before it is compressed
+	DefaultCompressionThreshold = 1024
+
+	// Header bytes that prefix a compressed value
+	// These are never valid in UTF-8 text (or JSON), so uncompressed values are detected
+	compressionHeaderGzip   byte = 0xc0
+	compressionHeaderSnappy byte = 0xc1
+
+	// Header byte of a binary value stored uncompressed (below the threshold, see: compressBytes)
+	// Never valid in UTF-8 text (0xf5-0xff) and not a marker of the models (binary, version and msgpack)
+	compressionHeaderNone byte = 0xf5
+)
+
+// String is the string version of the compression type
+func (t CompressionType) String() string {
+	return string(t)
+}
+
+// IsValid will return true if the compression type is supported
+func (t CompressionType) IsValid() bool {
+	return t == CompressionNone || t == CompressionGzip || t == CompressionSnappy
+}
+
+// compressValue will compress the data (if enabled and above the threshold) and prefix the header byte
+//
+// The result is encoded using the value encoding (if enabled, see: WithValueEncoding)
+func (c *Client) compressValue(data []byte) ([]byte, error) {
+	if c.options.compression == CompressionNone || len(data) < c.options.compressionThreshold {
+		return c.encodeBinary(data), nil
+	}
+
+	// Snappy
+	if c.options.compression == CompressionSnappy {
+		return c.encodeBinary(append([]byte{compressionHeaderSnappy}, snappy.Encode(nil, data)...)), nil
+	}
+
+	// Gzip
+	var buf bytes.Buffer
+	buf.WriteByte(compressionHeaderGzip)
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return c.encodeBinary(buf.Bytes()), nil
+}
+
+// compressBytes will compress a binary value (see: compressValue), a value below the threshold is prefixed with compressionHeaderNone
+//
+// A binary value can start with a compression header byte, the header is always written when the compression is enabled
+func (c *Client) compressBytes(data []byte) ([]byte, error) {
+	if c.options.compression == CompressionNone || len(data) >= c.options.compressionThreshold {
+		return c.compressValue(data)
+	}
+	return c.encodeBinary(append([]byte{compressionHeaderNone}, data...)), nil
+}
+
+// decompressValue will decompress the data if it starts with a compression header
+//
+// Values without a header (written before compression was enabled) are returned as-is, compressionHeaderNone is removed
+// The value encoding is decoded first (if enabled, see: WithValueEncoding)
+func (c *Client) decompressValue(data []byte) ([]byte, error) {
+	data, err := c.decodeBinary(data)
+	if err != nil {
+		return nil, err
+	} else if c.options.compression == CompressionNone || len(data) == 0 {
+		return data, nil
+	}
+
+	switch data[0] {
+	case compressionHeaderNone:
+		return data[1:], nil
+	case compressionHeaderSnappy:
+		decoded, err := snappy.Decode(nil, data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed decompressing value (snappy): %w", err)
+		}
+		return decoded, nil
+	case compressionHeaderGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("failed decompressing value (gzip): %w", err)
+		}
+		defer func() {
+			_ = reader.Close()
+		}()
+		var decoded []byte
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed decompressing value (gzip): %w", err)
+		}
+		return decoded, nil
+	}
+	return data, nil
+}
+
+// encodeValue will compress and encode a string or []byte value (if enabled), all other values are returned as-is
+func (c *Client) encodeValue(value interface{}) (interface{}, error) {
+	if c.options.compression == CompressionNone && c.options.valueEncoding == ValueEncodingNone {
+		return value, nil
+	}
+	switch v := value.(type) {
+	case string:
+		return c.compressValue([]byte(v))
+	case []byte:
+		return c.compressBytes(v)
+	}
+	return value, nil
+}
+
+// decodeString will decode and decompress a string value (if enabled)
+func (c *Client) decodeString(value string) (string, error) {
+	if c.options.compression == CompressionNone && c.options.valueEncoding == ValueEncodingNone {
+		return value, nil
+	}
+	data, err := c.decompressValue([]byte(value))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// valueToBytes will convert a value into bytes (for engines that only store bytes)
+func valueToBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return []byte(fmt.Sprint(value))
+}
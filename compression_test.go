@@ -0,0 +1,177 @@
+package cachestore
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCompressionType_IsValid will test the method IsValid()
+func TestCompressionType_IsValid(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid types", func(t *testing.T) {
+		assert.True(t, CompressionNone.IsValid())
+		assert.True(t, CompressionGzip.IsValid())
+		assert.True(t, CompressionSnappy.IsValid())
+		assert.Equal(t, "gzip", CompressionGzip.String())
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		assert.False(t, CompressionType("zip").IsValid())
+		assert.False(t, CompressionType("").IsValid())
+	})
+
+	t.Run("invalid type returns an error from NewClient", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithCompression("zip"))
+		require.Nil(t, c)
+		require.ErrorIs(t, err, ErrUnsupportedCompression)
+	})
+}
+
+// TestClient_Compression will test storing values using compression
+func TestClient_Compression(t *testing.T) {
+
+	largeValue := strings.Repeat(testValue, 500)
+	testModel := &genericStruct{
+		StringField: largeValue,
+		IntField:    123,
+		BoolField:   true,
+		FloatField:  12.34,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		for _, algo := range []CompressionType{CompressionGzip, CompressionSnappy} {
+			t.Run(testCase.name+" ["+algo.String()+"] - large value is compressed", func(t *testing.T) {
+				c, err := NewClient(context.Background(), testCase.opts, WithCompression(algo))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				err = c.Set(context.Background(), testKey, largeValue)
+				require.NoError(t, err)
+
+				// Read the raw value using a client without compression
+				var plain ClientInterface
+				plain, err = NewClient(context.Background(), testCase.opts, WithFreeCacheConnection(c.FreeCache()))
+				require.NoError(t, err)
+
+				var raw string
+				raw, err = plain.Get(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Less(t, len(raw), len(largeValue))
+				assert.Contains(t, []byte{compressionHeaderGzip, compressionHeaderSnappy}, raw[0])
+
+				var val string
+				val, err = c.Get(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Equal(t, largeValue, val)
+			})
+
+			t.Run(testCase.name+" ["+algo.String()+"] - small value is not compressed", func(t *testing.T) {
+				c, err := NewClient(context.Background(), testCase.opts, WithCompression(algo))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				err = c.SetTTL(context.Background(), testKey, testValue, time.Minute)
+				require.NoError(t, err)
+
+				var plain ClientInterface
+				plain, err = NewClient(context.Background(), testCase.opts, WithFreeCacheConnection(c.FreeCache()))
+				require.NoError(t, err)
+
+				var raw string
+				raw, err = plain.Get(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Equal(t, testValue, raw)
+			})
+
+			t.Run(testCase.name+" ["+algo.String()+"] - non-ascii text round trip", func(t *testing.T) {
+				c, err := NewClient(context.Background(), testCase.opts, WithCompression(algo))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				// The first bytes are UTF-8 lead bytes (ie: 0xc5 of "Ł"), small values are stored without a header
+				for _, value := range []string{"Łódź", "Ärger", "ñandú", "Ωmega", "日本語", "😀 emoji", strings.Repeat("Łódź", 500)} {
+					require.NoError(t, c.Set(context.Background(), testKey, value))
+
+					var val string
+					val, err = c.Get(context.Background(), testKey)
+					require.NoError(t, err)
+					assert.Equal(t, value, val)
+
+					require.NoError(t, c.SetBytes(context.Background(), testKey, []byte(value)))
+					var b []byte
+					b, err = c.GetBytes(context.Background(), testKey)
+					require.NoError(t, err)
+					assert.Equal(t, []byte(value), b)
+				}
+			})
+
+			t.Run(testCase.name+" ["+algo.String()+"] - read an uncompressed value", func(t *testing.T) {
+				c, err := NewClient(context.Background(), testCase.opts, WithCompression(algo))
+				require.NotNil(t, c)
+				require.NoError(t, err)
+
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				var plain ClientInterface
+				plain, err = NewClient(context.Background(), testCase.opts, WithFreeCacheConnection(c.FreeCache()))
+				require.NoError(t, err)
+
+				err = plain.Set(context.Background(), testKey, largeValue)
+				require.NoError(t, err)
+				err = plain.SetModel(context.Background(), testKey+"-model", testModel, 0)
+				require.NoError(t, err)
+
+				var val string
+				val, err = c.Get(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Equal(t, largeValue, val)
+
+				model := new(genericStruct)
+				err = c.GetModel(context.Background(), testKey+"-model", model)
+				require.NoError(t, err)
+				assert.Equal(t, testModel, model)
+			})
+
+			t.Run(testCase.name+" ["+algo.String()+"] - model round trip", func(t *testing.T) {
+				c, err := NewClient(context.Background(), testCase.opts,
+					WithCompression(algo), WithCompressionThreshold(0),
+				)
+				require.NotNil(t, c)
+				require.NoError(t, err)
+
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				err = c.SetModel(context.Background(), testKey, testModel, time.Minute)
+				require.NoError(t, err)
+
+				model := new(genericStruct)
+				err = c.GetModel(context.Background(), testKey, model)
+				require.NoError(t, err)
+				assert.Equal(t, testModel, model)
+			})
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/mrz1836/go-cache/nrredis"
+)
+
+// checkContext will return an error if the context is canceled or the deadline is exceeded
+//
+// The error wraps both ErrContextDone and the context error (context.Canceled or context.DeadlineExceeded)
+// A nil context is never done
+func checkContext(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrContextDone, err)
+	}
+	return nil
+}
+
+// sleepContext will wait for the duration, or stop early if the context is done (returns the checkContext error)
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	if ctx == nil {
+		time.Sleep(duration)
+		return nil
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return checkContext(ctx)
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withTimeout will return a child context that is done after the operation timeout (see: WithOperationTimeout)
+//
+// The context is returned as is if no timeout is set, or if it already has an earlier deadline
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := c.options.operationTimeout
+	if timeout <= 0 {
+		return ctx, func() {}
+	} else if ctx == nil {
+		ctx = context.Background()
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withoutCancel will return a context that is never done (keeping the values, ie: NewRelic txn)
+func withoutCancel(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return context.WithoutCancel(ctx)
+}
+
+// contextPool is a redis pool that binds the context to each connection
+//
+// Commands on a connection stop when the context is done (instead of running to completion)
+//
+// An exhausted pool waits for a free connection up to the wait timeout (see: RedisConfig.PoolWaitTimeout)
+type contextPool struct {
+	nrredis.Pool
+	waitTimeout time.Duration // Max wait for a free connection (the pool does not wait if zero)
+}
+
+// GetContext will return a connection that uses the context for each command
+//
+// Returns ErrPoolExhausted if no connection was released before the wait timeout (the wait also limits a new dial)
+func (p *contextPool) GetContext(ctx context.Context) (redis.Conn, error) {
+	waitCtx := ctx
+	if p.waitTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, p.waitTimeout)
+		defer cancel()
+	}
+	conn, err := p.Pool.GetContext(waitCtx)
+	if err != nil {
+		if ctxErr := checkContext(ctx); ctxErr != nil {
+			return nil, ctxErr
+		} else if errors.Is(err, redis.ErrPoolExhausted) || (p.waitTimeout > 0 && errors.Is(err, context.DeadlineExceeded)) {
+			return nil, fmt.Errorf("%w: %w", ErrPoolExhausted, err)
+		}
+		return nil, err
+	}
+	return &contextConn{Conn: conn, ctx: ctx}, nil
+}
+
+// contextConn is a redis connection that stops a command when the context is done
+type contextConn struct {
+	redis.Conn
+	ctx context.Context //nolint:containedctx // the connection is only used for a single operation
+}
+
+// Do will fire the command using the context (if supported by the connection)
+//
+// Connections that do not support a context (NewRelic wrapped) run the command to completion
+// A MOVED or ASK redirection returns ErrClusterModeRequired (the pool is never used for a cluster)
+func (c *contextConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	reply, err := c.do(commandName, args...)
+	return checkRedirect(commandName, reply, err)
+}
+
+// do will fire the command using the context (if supported by the connection)
+func (c *contextConn) do(commandName string, args ...interface{}) (interface{}, error) {
+	conn, ok := c.Conn.(redis.ConnWithContext)
+	if !ok || c.ctx == nil {
+		return c.Conn.Do(commandName, args...)
+	}
+	reply, err := conn.DoContext(c.ctx, commandName, args...)
+	if err != nil {
+		if ctxErr := checkContext(c.ctx); ctxErr != nil {
+			return nil, ctxErr
+		}
+
+		// The read timeout is set to the deadline, it can fire before the context is done
+		if deadline, found := c.ctx.Deadline(); found && !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%w: %w", ErrContextDone, context.DeadlineExceeded)
+		}
+	}
+	return reply, err
+}
@@ -0,0 +1,298 @@
+package cachestore
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/mrz1836/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_checkContext will test the method checkContext()
+func Test_checkContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil context", func(t *testing.T) {
+		require.NoError(t, checkContext(nil)) //nolint:staticcheck // testing a nil context
+	})
+
+	t.Run("valid context", func(t *testing.T) {
+		require.NoError(t, checkContext(context.Background()))
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := checkContext(ctx)
+		require.ErrorIs(t, err, ErrContextDone)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+		err := checkContext(ctx)
+		require.ErrorIs(t, err, ErrContextDone)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+// Test_sleepContext will test the method sleepContext()
+func Test_sleepContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil context", func(t *testing.T) {
+		require.NoError(t, sleepContext(nil, time.Millisecond)) //nolint:staticcheck // testing a nil context
+	})
+
+	t.Run("full duration", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, sleepContext(context.Background(), 20*time.Millisecond))
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	})
+
+	t.Run("canceled context stops waiting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		start := time.Now()
+		err := sleepContext(ctx, time.Minute)
+		require.ErrorIs(t, err, ErrContextDone)
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// TestClient_CanceledContext will test that the operations stop when the context is canceled
+func TestClient_CanceledContext(t *testing.T) {
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - canceled context", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			// Set a value and a lock before canceling
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey+"-lock", 30)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			require.ErrorIs(t, c.Set(ctx, testKey, testValue), context.Canceled)
+			require.ErrorIs(t, c.SetTTL(ctx, testKey, testValue, time.Minute), context.Canceled)
+			require.ErrorIs(t, c.SetModel(ctx, testKey, &genericStruct{}, 0), context.Canceled)
+			require.ErrorIs(t, c.GetModel(ctx, testKey, &genericStruct{}), context.Canceled)
+			require.ErrorIs(t, c.Delete(ctx, testKey), context.Canceled)
+
+			_, err = c.Get(ctx, testKey)
+			require.ErrorIs(t, err, context.Canceled)
+
+			_, err = c.WriteLock(ctx, testKey+"-new-lock", 30)
+			require.ErrorIs(t, err, context.Canceled)
+
+			_, err = c.WriteLockWithSecret(ctx, testKey+"-new-lock", testValue, 30)
+			require.ErrorIs(t, err, context.Canceled)
+
+			_, err = c.WaitWriteLock(ctx, testKey+"-new-lock", 30, 5)
+			require.ErrorIs(t, err, context.Canceled)
+
+			_, err = c.ReleaseLock(ctx, testKey+"-lock", secret)
+			require.ErrorIs(t, err, context.Canceled)
+
+			// Nothing was removed
+			var val string
+			val, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, val)
+		})
+	}
+}
+
+// TestClient_withTimeout will test the method withTimeout()
+func TestClient_withTimeout(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no timeout", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		ctx, cancel := c.withTimeout(context.Background())
+		defer cancel()
+
+		_, found := ctx.Deadline()
+		assert.False(t, found)
+	})
+
+	t.Run("timeout is set", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		WithOperationTimeout(time.Minute)(c.options)
+
+		ctx, cancel := c.withTimeout(context.Background())
+		defer cancel()
+
+		deadline, found := ctx.Deadline()
+		require.True(t, found)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	})
+
+	t.Run("nil context", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		WithOperationTimeout(time.Minute)(c.options)
+
+		ctx, cancel := c.withTimeout(nil) //nolint:staticcheck // testing a nil context
+		defer cancel()
+
+		_, found := ctx.Deadline()
+		assert.True(t, found)
+	})
+
+	t.Run("earlier deadline is kept", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		WithOperationTimeout(time.Minute)(c.options)
+
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Second)
+		defer parentCancel()
+
+		ctx, cancel := c.withTimeout(parent)
+		defer cancel()
+		assert.Equal(t, parent, ctx)
+	})
+
+	t.Run("later deadline is shortened", func(t *testing.T) {
+		c := &Client{options: defaultClientOptions()}
+		WithOperationTimeout(time.Second)(c.options)
+
+		parent, parentCancel := context.WithTimeout(context.Background(), time.Hour)
+		defer parentCancel()
+
+		ctx, cancel := c.withTimeout(parent)
+		defer cancel()
+
+		deadline, found := ctx.Deadline()
+		require.True(t, found)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 500*time.Millisecond)
+	})
+}
+
+// TestClient_OperationTimeout will test that the operations stop after the timeout when redis stalls
+func TestClient_OperationTimeout(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+
+	var stalled atomic.Bool
+	r := loadRedisInMemoryClient(t)
+	r.Server().SetPreHook(func(_ *server.Peer, _ string, _ ...string) bool {
+		if stalled.Load() {
+			time.Sleep(time.Second)
+		}
+		return false
+	})
+
+	c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithOperationTimeout(timeout))
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	defer c.Close(context.Background())
+
+	require.NoError(t, c.Set(context.Background(), testKey, testValue))
+	stalled.Store(true)
+	defer stalled.Store(false)
+
+	var operations = []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{"get", func(ctx context.Context) error {
+			_, getErr := c.Get(ctx, testKey)
+			return getErr
+		}},
+		{"set", func(ctx context.Context) error {
+			return c.Set(ctx, testKey, testValue)
+		}},
+		{"set ttl", func(ctx context.Context) error {
+			return c.SetTTL(ctx, testKey, testValue, time.Minute)
+		}},
+		{"delete", func(ctx context.Context) error {
+			return c.Delete(ctx, testKey)
+		}},
+		{"set model", func(ctx context.Context) error {
+			return c.SetModel(ctx, testKey, &genericStruct{}, 0)
+		}},
+		{"get model", func(ctx context.Context) error {
+			return c.GetModel(ctx, testKey, new(genericStruct))
+		}},
+		{"write lock", func(ctx context.Context) error {
+			_, lockErr := c.WriteLock(ctx, testKey+"-lock", 30)
+			return lockErr
+		}},
+		{"release lock", func(ctx context.Context) error {
+			_, lockErr := c.ReleaseLock(ctx, testKey+"-lock", testValue)
+			return lockErr
+		}},
+	}
+	for _, operation := range operations {
+		t.Run(operation.name+" - stops after the timeout", func(t *testing.T) {
+			start := time.Now()
+			require.Error(t, operation.run(context.Background()))
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+		})
+	}
+
+	t.Run("get - returns the context error", func(t *testing.T) {
+		_, err = c.Get(context.Background(), testKey)
+		require.ErrorIs(t, err, ErrContextDone)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("get - a later deadline uses the timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		start := time.Now()
+		_, err = c.Get(ctx, testKey)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("get - a sooner deadline is respected", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err = c.Get(ctx, testKey)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), timeout)
+	})
+}
+
+// TestClient_ContextDeadline will test that an in-flight redis command stops when the deadline is exceeded
+func TestClient_ContextDeadline(t *testing.T) {
+	r := loadRedisInMemoryClient(t)
+	r.Server().SetPreHook(func(_ *server.Peer, cmd string, _ ...string) bool {
+		if cmd == cache.GetCommand { // Slow response
+			time.Sleep(500 * time.Millisecond)
+		}
+		return false
+	})
+
+	c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	defer c.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = c.Get(ctx, testKey)
+	require.ErrorIs(t, err, ErrContextDone)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+
+	// The client still works after the deadline
+	require.NoError(t, c.Set(context.Background(), testKey, testValue))
+}
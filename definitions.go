@@ -1,33 +1,106 @@
 package cachestore
 
 import (
+	"crypto/tls"
 	"time"
 )
 
 const (
+	// DefaultEvictionCheckInterval is the time between the checks of the FreeCache evictions (see: WithOnEvicted)
+	DefaultEvictionCheckInterval = 10 * time.Second
+
+	// DefaultMemcachedMaxIdleConnections is the default max idle connections (per server)
+	DefaultMemcachedMaxIdleConnections = 2
+
+	// DefaultMemcachedTimeout is the default socket read/write timeout (memcached)
+	DefaultMemcachedTimeout = 500 * time.Millisecond
+
+	// DefaultRedisMaxDialBackoff is the default max delay between the dials after a failed dial (see: RedisConfig.DialBackoff)
+	DefaultRedisMaxDialBackoff = 10 * time.Second
+
 	// DefaultRedisMaxIdleTimeout is the default max timeout on an idle connection
 	DefaultRedisMaxIdleTimeout = 240 * time.Second
 
 	// DefaultRedisPort is the default Redis port
 	DefaultRedisPort = "6379"
 
+	// DefaultTieredLocalTTL is the default max time a value is kept in the local tier (tiered)
+	DefaultTieredLocalTTL = 30 * time.Second
+
+	// counterLockPrefix is the prefix for the lock used when incrementing counters or appending (FreeCache)
+	counterLockPrefix = "counter-lock:"
+
+	// counterLockTTL is the TTL (in seconds) of the counter lock
+	counterLockTTL = 10
+
+	// counterLockTTW is the time to wait (in seconds) to acquire the counter lock
+	counterLockTTW = 5
+
+	// writeLockManyTTW is the time to wait (in seconds) for each lock of WriteLockMany
+	writeLockManyTTW = 5
+
+	// dependencyOrderPrefix is the prefix of the order of the keys linked to a dependency (redis, see: WithMaxDependencyKeys)
+	//
+	// Not under the dependency prefix of go-cache (depend:), so the order is never read as a dependency set
+	dependencyOrderPrefix = "depend-order:"
+
+	// dependencyWarningInterval is the min time between the warnings about the keys unlinked from the dependencies
+	dependencyWarningInterval = time.Minute
+
+	// defaultScanCount is the default COUNT used for each SCAN iteration (redis)
+	defaultScanCount = 100
+
 	// Empty time duration for comparison
 	emptyTimeDuration = "0s"
 
+	// maxLockIndexKeys is the max number of locks in the lock index before pruning (FreeCache)
+	maxLockIndexKeys = 10000
+
+	// maxDependencyLinks is the max number of key->dependency links in the dependency index (FreeCache)
+	maxDependencyLinks = 100000
+
+	// memcachedMaxRelativeTTL is the max TTL memcached accepts as relative (larger is a unix timestamp)
+	memcachedMaxRelativeTTL = 30 * 24 * time.Hour
+
 	// lockRetrySleepTime is in milliseconds
 	lockRetrySleepTime = 10 * time.Millisecond
 
 	// RedisPrefix is the prefix for URL based connections
 	RedisPrefix = "redis://"
+
+	// RedisTLSPrefix is the prefix for URL based connections using TLS
+	RedisTLSPrefix = "rediss://"
 )
 
 // RedisConfig is the configuration for the cache client (redis)
 type RedisConfig struct {
+	ClusterAddresses      []string      `json:"cluster_addresses" mapstructure:"cluster_addresses"`             // localhost:7000 (cluster only)
+	ConnectTimeout        time.Duration `json:"connect_timeout" mapstructure:"connect_timeout"`                 // 0 (no timeout)
+	Database              int           `json:"database" mapstructure:"database"`                               // 0 (uses the database from the URL if not set)
+	DialBackoff           time.Duration `json:"dial_backoff" mapstructure:"dial_backoff"`                       // 0 (dial immediately), delay after a failed dial (doubles, with jitter)
 	DependencyMode        bool          `json:"dependency_mode" mapstructure:"dependency_mode"`                 // false for digital ocean (not supported)
+	MasterName            string        `json:"master_name" mapstructure:"master_name"`                         // mymaster (sentinel only)
 	MaxActiveConnections  int           `json:"max_active_connections" mapstructure:"max_active_connections"`   // 0
 	MaxConnectionLifetime time.Duration `json:"max_connection_lifetime" mapstructure:"max_connection_lifetime"` // 0
+	MaxDialBackoff        time.Duration `json:"max_dial_backoff" mapstructure:"max_dial_backoff"`               // 10 * time.Second (DialBackoff only)
 	MaxIdleConnections    int           `json:"max_idle_connections" mapstructure:"max_idle_connections"`       // 10
 	MaxIdleTimeout        time.Duration `json:"max_idle_timeout" mapstructure:"max_idle_timeout"`               // 240 * time.Second
+	Password              string        `json:"password" mapstructure:"password"`                               // Preferred over a password in the URL
+	PoolWaitTimeout       time.Duration `json:"pool_wait_timeout" mapstructure:"pool_wait_timeout"`             // 0 (fails immediately), max wait for a free connection when MaxActiveConnections are in use (not a cluster)
+	ReadReplicaURLs       []string      `json:"read_replica_urls" mapstructure:"read_replica_urls"`             // redis://replica:6379 (reads only, round-robin)
+	ReadTimeout           time.Duration `json:"read_timeout" mapstructure:"read_timeout"`                       // 0 (no timeout)
+	SentinelAddresses     []string      `json:"sentinel_addresses" mapstructure:"sentinel_addresses"`           // localhost:26379 (sentinel only)
+	TLSConfig             *tls.Config   `json:"-" mapstructure:"-"`                                             // Custom CA, certificates or skip verify (UseTLS only)
 	URL                   string        `json:"url" mapstructure:"url"`                                         // redis://localhost:6379
 	UseTLS                bool          `json:"use_tls" mapstructure:"use_tls"`                                 // true for digital ocean (required)
+	Username              string        `json:"username" mapstructure:"username"`                               // ACL user (redis 6+), requires a password (Password or the URL)
+	WriteTimeout          time.Duration `json:"write_timeout" mapstructure:"write_timeout"`                     // 0 (no timeout)
+	database              *int          // Database selected on each new connection (see: WithRedisDatabase)
+}
+
+// MemcachedConfig is the configuration for the cache client (memcached)
+type MemcachedConfig struct {
+	MaxIdleConnections int           `json:"max_idle_connections" mapstructure:"max_idle_connections"` // 2
+	Servers            []string      `json:"servers" mapstructure:"servers"`                           // localhost:11211
+	Timeout            time.Duration `json:"timeout" mapstructure:"timeout"`                           // 500 * time.Millisecond
 }
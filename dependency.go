@@ -0,0 +1,313 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/mrz1836/go-cache"
+)
+
+// DeleteDependency will remove all the keys that were stored with any of the dependencies (and the dependencies)
+//
+// Redis uses the dependency script if registered (DependencyMode), otherwise the keys are read from the dependency set
+// Redis also removes the order of the keys of the dependency (see: WithMaxDependencyKeys)
+// FreeCache uses an index of the dependencies kept by the client (bounded, see: maxDependencyLinks)
+// FreeCache only removes the keys still linked if the dependencies are limited (see: WithMaxDependencyKeys)
+// NOTE: memcached does not support dependency keys (ErrDependenciesNotSupported)
+func (c *Client) DeleteDependency(ctx context.Context, dependencies ...string) (err error) {
+
+	// Update the statistics and metrics, run the hooks
+	start := time.Now()
+	var total int
+	defer func() {
+		c.options.stats.deleted(total, err)
+		c.observe(ctx, operationDeleteDependency, "", start, writeResult(err))
+		c.onError(operationDeleteDependency, "", err)
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Require an open client and each dependency (stored as is, with the key prefix)
+	if err = c.checkClosed(); err != nil {
+		return err
+	}
+	for _, dependency := range dependencies {
+		if len(strings.TrimSpace(dependency)) == 0 {
+			return ErrKeyRequired
+		}
+	}
+	if len(dependencies) == 0 {
+		return nil
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Redis (remove the local and fallback copies of the keys)
+	if c.Engine().usesRedis() {
+		var removed func(keys []string)
+		if c.isTiered() || c.hasFallback() {
+			removed = func(keys []string) {
+				c.deleteLocal(keys...)
+				c.deleteFallback(keys...)
+			}
+		}
+		for _, dependency := range dependencies {
+			var deleted int
+			deleted, err = deleteDependencyRedis(ctx, c.options.redis, c.options.keyPrefix+dependency, removed)
+			total += deleted
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Memcached
+	if c.Engine() == Memcached {
+		return fmt.Errorf("%w: %s", ErrDependenciesNotSupported, c.Engine())
+	}
+
+	// FreeCache (remove the keys linked in the index)
+	for _, dependency := range dependencies {
+		for _, key := range c.options.dependencies.take(dependency) {
+			if c.options.freeCache.Del([]byte(key)) {
+				total++
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteModel will remove the key and remove it from the dependencies it was stored with (SetModel)
+//
+// Redis scans the dependency sets under the key prefix (SCAN) and removes the key from each set (SREM),
+// the cost grows with the number of dependencies, an empty set is removed by redis
+// FreeCache removes the key from the index of the dependencies kept by the client
+// NOTE: memcached does not support dependency keys (same as Delete)
+func (c *Client) DeleteModel(ctx context.Context, key string) (err error) {
+
+	// Remove the key (both tiers)
+	if err = c.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	// Add the operation, key and engine to the error
+	defer func(key string) {
+		err = c.wrapError(operationDeleteModel, key, err)
+	}(key)
+
+	// Sanitize the key, require it and add the prefix
+	if key, err = c.buildKey(key); err != nil {
+		return err
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Redis (remove the key from the dependency sets)
+	if c.Engine().usesRedis() {
+		return unlinkDependenciesRedis(
+			ctx, c.options.redis, cache.DependencyPrefix+escapePattern(c.options.keyPrefix)+"*", key, c.options.scanCount,
+		)
+	} else if c.Engine() == Memcached {
+		return nil
+	}
+
+	// FreeCache (remove the key from the index)
+	c.options.dependencies.unlink(key)
+	return nil
+}
+
+// linkDependencies will link the key to the dependencies in the index (FreeCache)
+//
+// The oldest keys of a dependency over the max are unlinked (see: WithMaxDependencyKeys), a warning is logged
+func (c *Client) linkDependencies(ctx context.Context, key string, dependencies []string) {
+	if trimmed := c.options.dependencies.link(
+		c.options.freeCache, key, dependencies, c.options.maxDependencyKeys,
+	); trimmed > 0 {
+		c.warnDependencies(ctx, fmt.Sprintf(
+			"cachestore: unlinked the %d oldest keys of the dependencies of %q (max %d keys per dependency)",
+			trimmed, key, c.options.maxDependencyKeys,
+		))
+	}
+}
+
+// trimDependencies will unlink the oldest keys of the dependency sets over the max (redis, see: WithMaxDependencyKeys)
+//
+// The keys were just linked to the dependencies, a warning is logged when keys are unlinked or on a failure
+func (c *Client) trimDependencies(ctx context.Context, dependencies []string, keys ...string) {
+	if c.options.maxDependencyKeys <= 0 {
+		return
+	}
+	for _, dependency := range c.prefixKeys(dependencies) {
+		trimmed, err := trimDependencyRedis(ctx, c.options.redis, dependency, c.options.maxDependencyKeys, keys...)
+		if err != nil {
+			c.warnDependencies(ctx, fmt.Sprintf(
+				"cachestore: failed unlinking the oldest keys of the dependency %q: %s", dependency, err,
+			))
+		} else if trimmed > 0 {
+			c.warnDependencies(ctx, fmt.Sprintf(
+				"cachestore: unlinked the %d oldest keys of the dependency %q (max %d keys per dependency)",
+				trimmed, dependency, c.options.maxDependencyKeys,
+			))
+		}
+	}
+}
+
+// warnDependencies will log the warning about the dependencies (at most once per dependencyWarningInterval)
+func (c *Client) warnDependencies(ctx context.Context, message string) {
+	now := time.Now().UnixNano()
+	last := c.options.dependencyWarned.Load()
+	if now-last < int64(dependencyWarningInterval) || !c.options.dependencyWarned.CompareAndSwap(last, now) {
+		return
+	}
+	c.options.logger.Warn(ctx, message)
+}
+
+// dependencyIndex links the dependencies to the keys stored with them (FreeCache)
+//
+// Links of keys that are no longer cached (expired, evicted or removed) are pruned when the index is full
+type dependencyIndex struct {
+	links    map[string]map[string]uint64 // Dependency -> keys (and the order they were linked)
+	maxLinks int                          // Max number of links before pruning
+	next     uint64                       // Order of the next link
+	size     int                          // Current number of links
+	sync.Mutex
+}
+
+// newDependencyIndex will create an empty index that holds up to maxLinks links
+func newDependencyIndex(maxLinks int) *dependencyIndex {
+	return &dependencyIndex{
+		links:    make(map[string]map[string]uint64),
+		maxLinks: maxLinks,
+	}
+}
+
+// link will link the key to each dependency (the index is pruned when full)
+//
+// A dependency over maxKeys (if set) unlinks its oldest keys, returns the number of keys unlinked
+func (d *dependencyIndex) link(freeCacheClient *freecache.Cache, key string, dependencies []string, maxKeys int) (trimmed int) {
+	if len(dependencies) == 0 {
+		return 0
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	for _, dependency := range dependencies {
+		keys, ok := d.links[dependency]
+		if !ok {
+			keys = make(map[string]uint64)
+			d.links[dependency] = keys
+		}
+		if _, ok = keys[key]; !ok {
+			keys[key] = d.next
+			d.next++
+			d.size++
+		}
+		if maxKeys > 0 {
+			trimmed += d.trimOldest(keys, maxKeys)
+		}
+	}
+	if d.size > d.maxLinks {
+		d.prune(freeCacheClient)
+	}
+	return trimmed
+}
+
+// trimOldest will unlink the oldest keys of a dependency until maxKeys are left (returns the number of keys unlinked)
+func (d *dependencyIndex) trimOldest(keys map[string]uint64, maxKeys int) (trimmed int) {
+	for len(keys) > maxKeys {
+		var oldest string
+		order := uint64(math.MaxUint64)
+		for key, linked := range keys {
+			if linked < order {
+				oldest, order = key, linked
+			}
+		}
+		delete(keys, oldest)
+		d.size--
+		trimmed++
+	}
+	return trimmed
+}
+
+// prune will remove the links of keys that are no longer cached
+//
+// If the index is still over 3/4 full, whole dependencies are invalidated (their keys are removed from the cache)
+// so removing a dependency never misses a key that is still cached
+func (d *dependencyIndex) prune(freeCacheClient *freecache.Cache) {
+	for dependency, keys := range d.links {
+		for key := range keys {
+			if _, err := freeCacheClient.TTL([]byte(key)); err != nil {
+				delete(keys, key)
+				d.size--
+			}
+		}
+		if len(keys) == 0 {
+			delete(d.links, dependency)
+		}
+	}
+
+	// Invalidate dependencies to leave room for new links
+	for dependency, keys := range d.links {
+		if d.size <= d.maxLinks*3/4 {
+			return
+		}
+		for key := range keys {
+			_ = freeCacheClient.Del([]byte(key))
+		}
+		d.size -= len(keys)
+		delete(d.links, dependency)
+	}
+}
+
+// unlink will remove the key from all the dependencies (a dependency without keys is removed)
+func (d *dependencyIndex) unlink(key string) {
+	d.Lock()
+	defer d.Unlock()
+	for dependency, keys := range d.links {
+		if _, ok := keys[key]; !ok {
+			continue
+		}
+		delete(keys, key)
+		d.size--
+		if len(keys) == 0 {
+			delete(d.links, dependency)
+		}
+	}
+}
+
+// take will remove the dependency from the index and return the keys linked to it
+func (d *dependencyIndex) take(dependency string) []string {
+	d.Lock()
+	defer d.Unlock()
+	keys := make([]string, 0, len(d.links[dependency]))
+	for key := range d.links[dependency] {
+		keys = append(keys, key)
+	}
+	d.size -= len(keys)
+	delete(d.links, dependency)
+	return keys
+}
+
+// clear will remove all the links
+func (d *dependencyIndex) clear() {
+	d.Lock()
+	defer d.Unlock()
+	d.links = make(map[string]map[string]uint64)
+	d.size = 0
+}
@@ -0,0 +1,502 @@
+package cachestore
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/mrz1836/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_DeleteDependency will test the method DeleteDependency()
+func TestClient_DeleteDependency(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	testCases = append(testCases, cacheTestCase{
+		name:   "[" + Redis.String() + "] [in-memory] [dependency mode]",
+		engine: Redis,
+		opts: WithRedis(&RedisConfig{
+			DependencyMode: true,
+			URL:            loadRedisInMemoryClient(t).Addr(),
+		}),
+	})
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - removes all the keys with the dependency", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey+"-1", testValue, "user"))
+			require.NoError(t, c.SetTTL(context.Background(), testKey+"-2", testValue, time.Minute, "user", "other"))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-3", &genericStruct{}, 0, "user"))
+			require.NoError(t, c.SetMulti(context.Background(), map[string]string{
+				testKey + "-4": testValue,
+				testKey + "-5": testValue,
+			}, "user"))
+			require.NoError(t, c.Set(context.Background(), testKey+"-other", testValue, "other"))
+			require.NoError(t, c.Set(context.Background(), testKey+"-none", testValue))
+
+			require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+			assert.Equal(t, int64(5), c.Stats().Deletes)
+
+			for i := 1; i <= 5; i++ {
+				found, existsErr := c.Exists(context.Background(), testKey+"-"+strconv.Itoa(i))
+				require.NoError(t, existsErr)
+				assert.False(t, found, i)
+			}
+			for _, key := range []string{testKey + "-other", testKey + "-none"} {
+				found, existsErr := c.Exists(context.Background(), key)
+				require.NoError(t, existsErr)
+				assert.True(t, found, key)
+			}
+
+			// Removing it again does nothing
+			require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+			assert.Equal(t, int64(5), c.Stats().Deletes)
+		})
+
+		t.Run(testCase.name+" - removes the keys of several dependencies", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey+"-user", testValue, "user"))
+			require.NoError(t, c.Set(context.Background(), testKey+"-account", testValue, "account"))
+			require.NoError(t, c.Set(context.Background(), testKey+"-both", testValue, "user", "account"))
+			require.NoError(t, c.Set(context.Background(), testKey+"-other", testValue, "other"))
+
+			require.NoError(t, c.DeleteDependency(context.Background(), "user", "account", "missing"))
+			assert.Equal(t, int64(3), c.Stats().Deletes)
+
+			for _, key := range []string{testKey + "-user", testKey + "-account", testKey + "-both"} {
+				found, existsErr := c.Exists(context.Background(), key)
+				require.NoError(t, existsErr)
+				assert.False(t, found, key)
+			}
+
+			found, existsErr := c.Exists(context.Background(), testKey+"-other")
+			require.NoError(t, existsErr)
+			assert.True(t, found)
+		})
+
+		t.Run(testCase.name+" - no dependencies", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.DeleteDependency(context.Background()))
+		})
+
+		t.Run(testCase.name+" - dependency is required", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.ErrorIs(t, c.DeleteDependency(context.Background(), ""), ErrKeyRequired)
+			require.ErrorIs(t, c.DeleteDependency(context.Background(), "   "), ErrKeyRequired)
+			require.ErrorIs(t, c.DeleteDependency(context.Background(), "user", ""), ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - missing dependency", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.DeleteDependency(context.Background(), "missing"))
+			assert.Equal(t, int64(0), c.Stats().Deletes)
+		})
+
+		t.Run(testCase.name+" - dependencies use the key prefix", func(t *testing.T) {
+			opts := sharedClientOpts(testCase)
+
+			first, err := NewClient(context.Background(), opts, WithKeyPrefix("first:"))
+			require.NoError(t, err)
+
+			var second ClientInterface
+			second, err = NewClient(context.Background(), opts, WithKeyPrefix("second:"))
+			require.NoError(t, err)
+
+			defer func() {
+				_ = first.EmptyCache(context.Background())
+				_ = second.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, first.Set(context.Background(), testKey, testValue, "user"))
+			require.NoError(t, second.Set(context.Background(), testKey, testValue, "user"))
+			require.NoError(t, first.DeleteDependency(context.Background(), "user"))
+
+			var found bool
+			found, err = first.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			found, err = second.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, found)
+		})
+
+		t.Run(testCase.name+" - context is done", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			require.ErrorIs(t, c.DeleteDependency(ctx, "user"), context.Canceled)
+		})
+	}
+
+	t.Run("["+FreeCache.String()+"] - replaced keys are linked", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		var replaced bool
+		replaced, err = c.ReplaceModel(context.Background(), testKey, &genericStruct{}, 0, "user")
+		require.NoError(t, err)
+		require.True(t, replaced)
+
+		// Nothing is linked if the key does not exist
+		replaced, err = c.ReplaceModel(context.Background(), testKey+"-missing", &genericStruct{}, 0, "user")
+		require.NoError(t, err)
+		require.False(t, replaced)
+
+		require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+		assert.Equal(t, int64(1), c.Stats().Deletes)
+	})
+
+	t.Run("["+Tiered.String()+"] - local copies are removed", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey+"-1", testValue, "user"))
+		require.NoError(t, c.Set(context.Background(), testKey+"-2", testValue, "user"))
+		assert.Equal(t, int64(2), local.EntryCount())
+
+		require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+		assert.Equal(t, int64(0), local.EntryCount())
+		assert.Empty(t, r.Keys())
+	})
+}
+
+// TestClient_DeleteModel will test the method DeleteModel()
+func TestClient_DeleteModel(t *testing.T) {
+
+	for _, dependencyMode := range []bool{false, true} {
+		t.Run("["+Redis.String()+"] [dependency mode: "+strconv.FormatBool(dependencyMode)+"] - removed from the dependency sets", func(t *testing.T) {
+			r := loadRedisInMemoryClient(t)
+			c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+				DependencyMode: dependencyMode,
+				URL:            r.Addr(),
+			}), WithScanCount(1))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{}, 0, "user", "account"))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-other", &genericStruct{}, 0, "user"))
+
+			require.NoError(t, c.DeleteModel(context.Background(), testKey))
+			assert.False(t, r.Exists(testKey))
+
+			// The other key is still linked, the empty set is removed
+			members, err := r.Members(cache.DependencyPrefix + "user")
+			require.NoError(t, err)
+			assert.Equal(t, []string{testKey + "-other"}, members)
+			assert.False(t, r.Exists(cache.DependencyPrefix+"account"))
+
+			// Missing key
+			require.NoError(t, c.DeleteModel(context.Background(), testKey))
+		})
+	}
+
+	t.Run("["+Redis.String()+"] - only the dependencies under the key prefix", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{}, 0, "user"))
+		_, err = r.SetAdd(cache.DependencyPrefix+"other:user", "app:"+testKey)
+		require.NoError(t, err)
+
+		require.NoError(t, c.DeleteModel(context.Background(), testKey))
+		assert.False(t, r.Exists(cache.DependencyPrefix+"app:user"))
+		assert.True(t, r.Exists(cache.DependencyPrefix+"other:user"))
+	})
+
+	t.Run("["+FreeCache.String()+"] - removed from the dependency index", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{}, 0, "user", "account"))
+		require.NoError(t, c.SetModel(context.Background(), testKey+"-other", &genericStruct{}, 0, "user"))
+
+		require.NoError(t, c.DeleteModel(context.Background(), testKey))
+		index := c.(*Client).options.dependencies
+		assert.Equal(t, map[string]map[string]uint64{"user": {testKey + "-other": 2}}, index.links)
+		assert.Equal(t, 1, index.size)
+
+		err = c.GetModel(context.Background(), testKey, new(genericStruct))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		// Removing the dependency only removes the other key
+		require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+		assert.Equal(t, int64(2), c.Stats().Deletes)
+	})
+
+	t.Run("key is required", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.ErrorIs(t, c.DeleteModel(context.Background(), "  "), ErrKeyRequired)
+	})
+}
+
+// TestClient_MaxDependencyKeys will test limiting the keys linked to a dependency (WithMaxDependencyKeys)
+func TestClient_MaxDependencyKeys(t *testing.T) {
+
+	const maxKeys = 5
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - the keys that survive the limit", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMaxDependencyKeys(maxKeys), WithKeyPrefix("app:"))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			keys := make([]string, 0, 3*maxKeys)
+			for i := 0; i < 3*maxKeys; i++ {
+				key := testKey + "-" + strconv.Itoa(i)
+				keys = append(keys, key)
+				if i%2 == 0 {
+					require.NoError(t, c.Set(context.Background(), key, testValue, "user"))
+				} else {
+					require.NoError(t, c.SetModel(context.Background(), key, &genericStruct{}, 0, "user"))
+				}
+			}
+
+			// The oldest keys are unlinked (redis keeps the order next to the set)
+			if testCase.engine == Redis {
+				members, membersErr := testCase.redis.Members(cache.DependencyPrefix + "app:user")
+				require.NoError(t, membersErr)
+				assert.Len(t, members, maxKeys)
+			} else {
+				assert.Equal(t, maxKeys, c.(*Client).options.dependencies.size)
+			}
+
+			// Only the keys still linked are removed, the others expire using the TTL
+			require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+			for i, key := range keys {
+				found, existsErr := c.Exists(context.Background(), key)
+				require.NoError(t, existsErr)
+				assert.Equal(t, i < 2*maxKeys, found, key)
+			}
+		})
+	}
+
+	t.Run("["+FreeCache.String()+"] - the oldest keys are unlinked", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithMaxDependencyKeys(2))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 4; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(i), testValue, "user"))
+		}
+		require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+
+		for i, expected := range []bool{true, true, false, false} {
+			found, existsErr := c.Exists(context.Background(), testKey+"-"+strconv.Itoa(i))
+			require.NoError(t, existsErr)
+			assert.Equal(t, expected, found, i)
+		}
+	})
+
+	t.Run("["+Redis.String()+"] - the set is bounded", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithMaxDependencyKeys(maxKeys))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		// Single keys and multiple keys, the newest keys are kept
+		for i := 0; i < 3*maxKeys; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(i), testValue, "user"))
+		}
+		require.NoError(t, c.SetMulti(context.Background(), map[string]string{
+			testKey + "-a": testValue,
+			testKey + "-b": testValue,
+		}, "user"))
+		members, err := r.Members(cache.DependencyPrefix + "user")
+		require.NoError(t, err)
+		sort.Strings(members)
+		assert.Equal(t, []string{
+			testKey + "-12", testKey + "-13", testKey + "-14", testKey + "-a", testKey + "-b",
+		}, members)
+
+		// The order of the keys no longer linked is removed once it's twice the max
+		for i := 0; i < 3*maxKeys; i++ {
+			key := testKey + "-model-" + strconv.Itoa(i)
+			require.NoError(t, c.Set(context.Background(), key, testValue, "user"))
+			require.NoError(t, c.DeleteModel(context.Background(), key))
+		}
+		order, err := r.ZMembers(dependencyOrderPrefix + "user")
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(order), 2*maxKeys)
+		members, err = r.Members(cache.DependencyPrefix + "user")
+		require.NoError(t, err)
+		assert.Equal(t, []string{testKey + "-13", testKey + "-14", testKey + "-a", testKey + "-b"}, members)
+
+		// The order is removed with the dependency
+		require.NoError(t, c.DeleteDependency(context.Background(), "user"))
+		assert.False(t, r.Exists(dependencyOrderPrefix+"user"))
+		assert.False(t, r.Exists(cache.DependencyPrefix+"user"))
+		found, err := c.Exists(context.Background(), testKey+"-0")
+		require.NoError(t, err)
+		assert.True(t, found)
+	})
+
+	t.Run("the warnings are limited", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithMaxDependencyKeys(1))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		client := c.(*Client)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(i), testValue, "user"))
+		}
+		warned := client.options.dependencyWarned.Load()
+		assert.Positive(t, warned)
+		client.warnDependencies(context.Background(), "cachestore: test warning")
+		assert.Equal(t, warned, client.options.dependencyWarned.Load())
+	})
+
+	t.Run("["+Redis.String()+"] - no limit by default", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 3*maxKeys; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(i), testValue, "user"))
+		}
+		members, err := r.Members(cache.DependencyPrefix + "user")
+		require.NoError(t, err)
+		assert.Len(t, members, 3*maxKeys)
+	})
+}
+
+// Test_dependencyIndex will test the dependency index (FreeCache)
+func Test_dependencyIndex(t *testing.T) {
+	t.Parallel()
+
+	t.Run("link and take", func(t *testing.T) {
+		client := freecache.NewCache(MinFreeCacheSize)
+		index := newDependencyIndex(10)
+
+		index.link(client, "key-1", []string{"user", "other"}, 0)
+		index.link(client, "key-2", []string{"user"}, 0)
+		index.link(client, "key-2", []string{"user"}, 0) // Already linked
+		index.link(client, "key-3", nil, 0)
+		assert.Equal(t, 3, index.size)
+
+		keys := index.take("user")
+		sort.Strings(keys)
+		assert.Equal(t, []string{"key-1", "key-2"}, keys)
+		assert.Equal(t, 1, index.size)
+		assert.Empty(t, index.take("user"))
+
+		index.clear()
+		assert.Equal(t, 0, index.size)
+		assert.Empty(t, index.take("other"))
+	})
+
+	t.Run("unlink", func(t *testing.T) {
+		client := freecache.NewCache(MinFreeCacheSize)
+		index := newDependencyIndex(10)
+
+		index.link(client, "key-1", []string{"user", "other"}, 0)
+		index.link(client, "key-2", []string{"user"}, 0)
+		index.unlink("key-1")
+		index.unlink("key-missing")
+		assert.Equal(t, 1, index.size)
+		assert.Empty(t, index.take("other"))
+		assert.Equal(t, []string{"key-2"}, index.take("user"))
+	})
+
+	t.Run("the oldest keys over the max are unlinked", func(t *testing.T) {
+		client := freecache.NewCache(MinFreeCacheSize)
+		index := newDependencyIndex(100)
+
+		var trimmed int
+		for i := 0; i < 5; i++ {
+			trimmed += index.link(client, "key-"+strconv.Itoa(i), []string{"user", "other-" + strconv.Itoa(i)}, 3)
+		}
+		index.link(client, "key-2", []string{"user"}, 3) // Already linked (not newer)
+		assert.Equal(t, 2, trimmed)
+		assert.Equal(t, 8, index.size)
+
+		keys := index.take("user")
+		sort.Strings(keys)
+		assert.Equal(t, []string{"key-2", "key-3", "key-4"}, keys)
+		assert.Equal(t, []string{"key-0"}, index.take("other-0"))
+	})
+
+	t.Run("keys that are no longer cached are pruned", func(t *testing.T) {
+		client := freecache.NewCache(MinFreeCacheSize)
+		index := newDependencyIndex(4)
+
+		for i := 0; i < 4; i++ {
+			key := "key-" + strconv.Itoa(i)
+			require.NoError(t, client.Set([]byte(key), []byte(testValue), 0))
+			index.link(client, key, []string{"user"}, 0)
+		}
+		client.Del([]byte("key-0"))
+		client.Del([]byte("key-1"))
+
+		// Over the limit, the removed keys are pruned
+		require.NoError(t, client.Set([]byte("key-4"), []byte(testValue), 0))
+		index.link(client, "key-4", []string{"user"}, 0)
+		assert.Equal(t, 3, index.size)
+
+		keys := index.take("user")
+		sort.Strings(keys)
+		assert.Equal(t, []string{"key-2", "key-3", "key-4"}, keys)
+	})
+
+	t.Run("dependencies are invalidated when full", func(t *testing.T) {
+		client := freecache.NewCache(MinFreeCacheSize)
+		index := newDependencyIndex(4)
+
+		for i := 0; i < 5; i++ {
+			key := "key-" + strconv.Itoa(i)
+			require.NoError(t, client.Set([]byte(key), []byte(testValue), 0))
+			index.link(client, key, []string{"dependency-" + strconv.Itoa(i)}, 0)
+		}
+		assert.LessOrEqual(t, index.size, 3)
+
+		// Keys are still linked, or were removed from the cache
+		for i := 0; i < 5; i++ {
+			key := "key-" + strconv.Itoa(i)
+			keys := index.take("dependency-" + strconv.Itoa(i))
+			if _, err := client.Get([]byte(key)); err == nil {
+				assert.Equal(t, []string{key}, keys)
+			}
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package cachestore
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// Redis dial settings (same as the redigo dialer)
+const (
+	dialKeepAlive = 5 * time.Minute  // Keep alive period of the connections
+	dialTimeout   = 30 * time.Second // Connect timeout if RedisConfig.ConnectTimeout is not set
+)
+
+// dialBackoff will wait before dialing again after a failed dial (see: RedisConfig.DialBackoff)
+//
+// The delay doubles after each failed dial (up to the max) and is randomized, so the
+// clients do not reconnect at the same time when redis restarts (thundering herd)
+type dialBackoff struct {
+	backoff  lockBackoff                                                       // Delay after a failed dial
+	dial     func(ctx context.Context, network, addr string) (net.Conn, error) // Dials the connection
+	failures atomic.Int64                                                      // Consecutive failed dials
+	wait     func(ctx context.Context, duration time.Duration) error           // Waits for the delay
+}
+
+// newDialBackoff will create the dial backoff for a pool
+func (r *RedisConfig) newDialBackoff() *dialBackoff {
+	maxDelay := r.MaxDialBackoff
+	if maxDelay <= 0 {
+		maxDelay = DefaultRedisMaxDialBackoff
+	}
+	timeout := r.ConnectTimeout
+	if timeout <= 0 {
+		timeout = dialTimeout
+	}
+	dialer := &net.Dialer{KeepAlive: dialKeepAlive, Timeout: timeout}
+	return &dialBackoff{
+		backoff: lockBackoff{factor: 2, initial: r.DialBackoff, jitter: true, maxDelay: maxDelay},
+		dial:    dialer.DialContext,
+		wait:    sleepContext,
+	}
+}
+
+// dialContext will dial the connection (waits for the backoff if the previous dial failed)
+//
+// A successful dial resets the backoff
+// NOTE: the pools dial without the context of the operation, the wait is only limited by the max backoff
+func (b *dialBackoff) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if failures := b.failures.Load(); failures > 0 {
+		if err := b.wait(ctx, b.backoff.delay(int(failures-1))); err != nil {
+			return nil, err
+		}
+	}
+	conn, err := b.dial(ctx, network, addr)
+	if err != nil {
+		b.failures.Add(1)
+		return nil, err
+	}
+	b.failures.Store(0)
+	return conn, nil
+}
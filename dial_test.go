@@ -0,0 +1,154 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFailingDialBackoff will return a dial backoff where the dials fail, and the recorded waits
+func newFailingDialBackoff(config *RedisConfig) (*dialBackoff, *[]time.Duration) {
+	waits := new([]time.Duration)
+	b := config.newDialBackoff()
+	b.dial = func(context.Context, string, string) (net.Conn, error) {
+		return nil, syscall.ECONNREFUSED
+	}
+	b.wait = func(_ context.Context, duration time.Duration) error {
+		*waits = append(*waits, duration)
+		return nil
+	}
+	return b, waits
+}
+
+// TestRedisConfig_newDialBackoff will test the method newDialBackoff()
+func TestRedisConfig_newDialBackoff(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default max backoff", func(t *testing.T) {
+		b := (&RedisConfig{DialBackoff: 100 * time.Millisecond}).newDialBackoff()
+		assert.Equal(t, 100*time.Millisecond, b.backoff.initial)
+		assert.Equal(t, DefaultRedisMaxDialBackoff, b.backoff.maxDelay)
+		assert.True(t, b.backoff.jitter)
+	})
+
+	t.Run("max backoff", func(t *testing.T) {
+		b := (&RedisConfig{DialBackoff: 100 * time.Millisecond, MaxDialBackoff: time.Second}).newDialBackoff()
+		assert.Equal(t, time.Second, b.backoff.maxDelay)
+	})
+
+	t.Run("dial options", func(t *testing.T) {
+		assert.Len(t, (&RedisConfig{DialBackoff: time.Second, ConnectTimeout: time.Second}).dialOptions(), 2)
+	})
+}
+
+// Test_dialBackoff_dialContext will test the method dialContext()
+func Test_dialBackoff_dialContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("repeated failures grow the backoff (with jitter)", func(t *testing.T) {
+		const initial = 100 * time.Millisecond
+		b, waits := newFailingDialBackoff(&RedisConfig{DialBackoff: initial, MaxDialBackoff: 3 * time.Second})
+
+		// The first dial is immediate
+		for i := 0; i < 8; i++ {
+			_, err := b.dialContext(context.Background(), "tcp", "localhost:6379")
+			require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		}
+		require.Len(t, *waits, 7)
+
+		// Between half and the full delay: 100ms, 200ms, 400ms, 800ms, 1.6s, 3s (max), 3s (max)
+		for i, wait := range *waits {
+			delay := min(initial<<i, 3*time.Second)
+			assert.GreaterOrEqual(t, wait, delay/2)
+			assert.Less(t, wait, delay)
+			if i > 0 && delay < 3*time.Second {
+				assert.Greater(t, wait, (*waits)[i-1])
+			}
+		}
+	})
+
+	t.Run("the waits are randomized", func(t *testing.T) {
+		waited := make(map[time.Duration]bool)
+		for i := 0; i < 10; i++ {
+			b, waits := newFailingDialBackoff(&RedisConfig{DialBackoff: time.Second})
+			for j := 0; j < 2; j++ {
+				_, _ = b.dialContext(context.Background(), "tcp", "localhost:6379")
+			}
+			require.Len(t, *waits, 1)
+			waited[(*waits)[0]] = true
+		}
+		assert.Greater(t, len(waited), 1)
+	})
+
+	t.Run("a successful dial resets the backoff", func(t *testing.T) {
+		b, waits := newFailingDialBackoff(&RedisConfig{DialBackoff: time.Second})
+		_, _ = b.dialContext(context.Background(), "tcp", "localhost:6379")
+		assert.Equal(t, int64(1), b.failures.Load())
+
+		b.dial = func(context.Context, string, string) (net.Conn, error) {
+			server, client := net.Pipe()
+			_ = server.Close()
+			return client, nil
+		}
+		conn, err := b.dialContext(context.Background(), "tcp", "localhost:6379")
+		require.NoError(t, err)
+		_ = conn.Close()
+		assert.Equal(t, int64(0), b.failures.Load())
+		assert.Len(t, *waits, 1)
+
+		// Immediate again
+		conn, err = b.dialContext(context.Background(), "tcp", "localhost:6379")
+		require.NoError(t, err)
+		_ = conn.Close()
+		assert.Len(t, *waits, 1)
+	})
+
+	t.Run("stops if the context is done while waiting", func(t *testing.T) {
+		b := (&RedisConfig{DialBackoff: time.Minute}).newDialBackoff()
+		b.failures.Store(1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := b.dialContext(ctx, "tcp", "localhost:6379")
+		require.ErrorIs(t, err, ErrContextDone)
+	})
+}
+
+// TestClient_DialBackoff will test the dial backoff when redis restarts (RedisConfig.DialBackoff)
+func TestClient_DialBackoff(t *testing.T) {
+	r := loadRedisInMemoryClient(t)
+	c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+		DialBackoff:        200 * time.Millisecond,
+		MaxIdleConnections: 1,
+		URL:                r.Addr(),
+	}))
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+	require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+	// The first dial fails immediately, the next dial waits
+	r.Close()
+	_, err = c.Get(context.Background(), testKey)
+	require.Error(t, err)
+	_, err = c.Get(context.Background(), testKey)
+	require.Error(t, err)
+
+	start := time.Now()
+	_, err = c.Get(context.Background(), testKey)
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrContextDone))
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// Reconnects once redis is back
+	require.NoError(t, r.Restart())
+	require.Eventually(t, func() bool {
+		_, err = c.Get(context.Background(), testKey)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,55 @@
+package cachestore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+)
+
+// ValueEncoding is the encoding of the stored values (see: WithValueEncoding)
+type ValueEncoding string
+
+// Supported value encodings
+const (
+	ValueEncodingBase64 ValueEncoding = "base64" // Base64 (standard encoding), binary safe as text
+	ValueEncodingNone   ValueEncoding = "none"   // Stored as is (default)
+)
+
+// base64Marker is the prefix of a base64 encoded value (values without the marker are read as is)
+const base64Marker = "~b64~"
+
+// String is the string version of the value encoding
+func (e ValueEncoding) String() string {
+	return string(e)
+}
+
+// IsValid will return true if the value encoding is supported
+func (e ValueEncoding) IsValid() bool {
+	return e == ValueEncodingNone || e == ValueEncodingBase64
+}
+
+// encodeBinary will encode the data (after the compression) using the value encoding (if enabled)
+func (c *Client) encodeBinary(data []byte) []byte {
+	if c.options.valueEncoding != ValueEncodingBase64 {
+		return data
+	}
+	encoded := make([]byte, len(base64Marker)+base64.StdEncoding.EncodedLen(len(data)))
+	copy(encoded, base64Marker)
+	base64.StdEncoding.Encode(encoded[len(base64Marker):], data)
+	return encoded
+}
+
+// decodeBinary will decode the data if it starts with the marker of the value encoding (if enabled)
+//
+// Values without the marker (written before the encoding was enabled) are returned as-is
+func (c *Client) decodeBinary(data []byte) ([]byte, error) {
+	if c.options.valueEncoding != ValueEncodingBase64 || !bytes.HasPrefix(data, []byte(base64Marker)) {
+		return data, nil
+	}
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)-len(base64Marker)))
+	n, err := base64.StdEncoding.Decode(decoded, data[len(base64Marker):])
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding value (base64): %w", err)
+	}
+	return decoded[:n], nil
+}
@@ -0,0 +1,167 @@
+package cachestore
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// binaryTestValue is a value with nulls, high-bit bytes and invalid UTF-8
+const binaryTestValue = "\x00binary\x00\xff\xfe\x80\xc0\xc1\r\n\x7fEND\x00"
+
+// TestValueEncoding_IsValid will test the method IsValid()
+func TestValueEncoding_IsValid(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, ValueEncodingNone.IsValid())
+	assert.True(t, ValueEncodingBase64.IsValid())
+	assert.False(t, ValueEncoding("hex").IsValid())
+	assert.False(t, ValueEncoding("").IsValid())
+	assert.Equal(t, "base64", ValueEncodingBase64.String())
+}
+
+// TestClient_decodeBinary will test the methods encodeBinary() and decodeBinary()
+func TestClient_decodeBinary(t *testing.T) {
+	t.Parallel()
+
+	c := &Client{options: defaultClientOptions()}
+	WithValueEncoding(ValueEncodingBase64)(c.options)
+
+	t.Run("round trip", func(t *testing.T) {
+		encoded := c.encodeBinary([]byte(binaryTestValue))
+		assert.True(t, strings.HasPrefix(string(encoded), base64Marker))
+		for _, b := range encoded {
+			assert.Less(t, b, byte(0x80))
+			assert.Greater(t, b, byte(0x20))
+		}
+
+		decoded, err := c.decodeBinary(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, binaryTestValue, string(decoded))
+	})
+
+	t.Run("empty value", func(t *testing.T) {
+		encoded := c.encodeBinary(nil)
+		assert.Equal(t, base64Marker, string(encoded))
+
+		decoded, err := c.decodeBinary(encoded)
+		require.NoError(t, err)
+		assert.Empty(t, decoded)
+	})
+
+	t.Run("values without the marker are read as is", func(t *testing.T) {
+		decoded, err := c.decodeBinary([]byte(testValue))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(decoded))
+	})
+
+	t.Run("invalid base64", func(t *testing.T) {
+		_, err := c.decodeBinary([]byte(base64Marker + "not base64!"))
+		require.Error(t, err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		none := &Client{options: defaultClientOptions()}
+		assert.Equal(t, binaryTestValue, string(none.encodeBinary([]byte(binaryTestValue))))
+
+		decoded, err := none.decodeBinary([]byte(base64Marker + "AAAA"))
+		require.NoError(t, err)
+		assert.Equal(t, base64Marker+"AAAA", string(decoded))
+	})
+}
+
+// TestClient_ValueEncoding will test the option WithValueEncoding()
+func TestClient_ValueEncoding(t *testing.T) {
+
+	t.Run("unsupported value encoding", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithValueEncoding("hex"))
+		require.ErrorIs(t, err, ErrUnsupportedValueEncoding)
+		assert.Nil(t, c)
+	})
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - binary round trip", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithValueEncoding(ValueEncodingBase64))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, binaryTestValue))
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, binaryTestValue, value)
+
+			require.NoError(t, c.SetBytes(context.Background(), testKey+"-bytes", []byte(binaryTestValue)))
+			var data []byte
+			data, err = c.GetBytes(context.Background(), testKey+"-bytes")
+			require.NoError(t, err)
+			assert.Equal(t, []byte(binaryTestValue), data)
+
+			model := &genericStruct{StringField: "\x00null\x00 and ünïcödé"}
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", model, 0))
+			found := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey+"-model", found))
+			assert.Equal(t, model, found)
+
+			// Stored as base64 text
+			if testCase.redis != nil {
+				var stored string
+				stored, err = testCase.redis.Get(testKey)
+				require.NoError(t, err)
+				assert.True(t, strings.HasPrefix(stored, base64Marker))
+				assert.NotContains(t, stored, "\x00")
+			}
+		})
+
+		t.Run(testCase.name+" - compressed and encoded", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts,
+				WithValueEncoding(ValueEncodingBase64), WithCompression(CompressionGzip), WithCompressionThreshold(0),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, binaryTestValue))
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, binaryTestValue, value)
+
+			var swapped bool
+			swapped, err = c.CompareAndSwap(context.Background(), testKey, binaryTestValue, testValue, 0)
+			require.NoError(t, err)
+			assert.True(t, swapped)
+		})
+
+		t.Run(testCase.name+" - values stored without the encoding are read as is", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithValueEncoding(ValueEncodingBase64))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			// Stored directly (without the encoding)
+			if testCase.redis != nil {
+				require.NoError(t, testCase.redis.Set(testKey, testValue))
+			} else {
+				require.NoError(t, c.FreeCache().Set([]byte(testKey), []byte(testValue), 0))
+			}
+
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+		})
+	}
+
+	t.Run("["+Memcached.String()+"] - binary round trip", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithValueEncoding(ValueEncodingBase64))
+
+		require.NoError(t, c.Set(context.Background(), testKey, binaryTestValue))
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, binaryTestValue, value)
+
+		raw, err := c.Memcached().Get(testKey)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(raw.Value), base64Marker))
+	})
+}
@@ -7,7 +7,9 @@ type Engine string
 const (
 	Empty     Engine = "empty"     // No engine set
 	FreeCache Engine = "freecache" // FreeCache (in-memory cache)
+	Memcached Engine = "memcached" // Memcached
 	Redis     Engine = "redis"     // Redis
+	Tiered    Engine = "tiered"    // FreeCache (local) in front of Redis
 )
 
 // String is the string version of engine
@@ -19,3 +21,8 @@ func (e Engine) String() string {
 func (e Engine) IsEmpty() bool {
 	return e == Empty
 }
+
+// usesRedis will return true if the engine stores the data in Redis
+func (e Engine) usesRedis() bool {
+	return e == Redis || e == Tiered
+}
@@ -13,6 +13,8 @@ func TestEngine_String(t *testing.T) {
 		assert.Equal(t, "empty", Empty.String())
 		assert.Equal(t, "redis", Redis.String())
 		assert.Equal(t, "freecache", FreeCache.String())
+		assert.Equal(t, "memcached", Memcached.String())
+		assert.Equal(t, "tiered", Tiered.String())
 	})
 }
 
@@ -2,6 +2,7 @@ package cachestore
 
 import (
 	"errors"
+	"fmt"
 )
 
 // ErrKeyNotFound is returned when a record is not found for a given key
@@ -10,6 +11,12 @@ var ErrKeyNotFound = errors.New("key not found")
 // ErrKeyRequired is returned when the key is empty (key->value)
 var ErrKeyRequired = errors.New("key is empty and required")
 
+// ErrInvalidKey is when the key validator rejects a key (wraps the validator error, see: WithKeyValidator)
+var ErrInvalidKey = errors.New("key is not valid")
+
+// ErrKeyTooLong is when the key exceeds the max key length (see: WithMaxKeyLength)
+var ErrKeyTooLong = errors.New("key is too long")
+
 // ErrSecretRequired is returned when the secret is empty (value)
 var ErrSecretRequired = errors.New("secret is empty and required")
 
@@ -22,11 +29,146 @@ var ErrLockCreateFailed = errors.New("failed creating cache lock")
 // ErrLockExists is the error when trying to create a lock fails due to an existing lock
 var ErrLockExists = errors.New("lock already exists with a different secret")
 
+// ErrLockNamespaceRequired is when the redis locks cannot be listed without a lock namespace (see: WithLockNamespace)
+var ErrLockNamespaceRequired = errors.New("listing the redis locks requires a lock namespace")
+
+// ErrLoaderRequired is when the loader function is missing (GetOrSet)
+var ErrLoaderRequired = errors.New("loader function is required")
+
+// ErrPipelineRequired is when the pipeline function is missing (Pipeline)
+var ErrPipelineRequired = errors.New("pipeline function is required")
+
 // ErrTTWCannotBeEmpty is when the TTW field is empty
 var ErrTTWCannotBeEmpty = errors.New("the TTW value cannot be empty")
 
+// ErrTTLCannotBeEmpty is when the TTL field is empty
+var ErrTTLCannotBeEmpty = errors.New("the TTL value cannot be empty")
+
+// ErrInvalidMemcachedConfig is when the memcached config is missing or invalid
+var ErrInvalidMemcachedConfig = errors.New("invalid memcached config")
+
+// ErrEngineNotSupported is when the operation is not supported by the current engine (the error includes the engine name)
+var ErrEngineNotSupported = errors.New("operation is not supported by the cachestore engine")
+
+// ErrNotSupported is when the operation is not supported by the current engine
+//
+// Deprecated: use ErrEngineNotSupported (same error)
+var ErrNotSupported = ErrEngineNotSupported
+
+// ErrDependenciesNotSupported is when the current engine does not support dependency keys (wraps ErrEngineNotSupported)
+var ErrDependenciesNotSupported = fmt.Errorf("dependencies: %w", ErrEngineNotSupported)
+
+// ErrUnsafeFlush is when Flush would remove every key (no key prefix) without WithAllowUnsafeFlush
+var ErrUnsafeFlush = errors.New("flush without a key prefix removes every key, use WithKeyPrefix or WithAllowUnsafeFlush")
+
+// ErrClientClosed is returned when the client has no engine set (ie: after Close)
+var ErrClientClosed = errors.New("cachestore client is closed, no engine is set")
+
 // ErrInvalidRedisConfig is when the redis config is missing or invalid
 var ErrInvalidRedisConfig = errors.New("invalid redis config")
 
+// ErrInvalidRedisURL is when the redis URL cannot be parsed, or the scheme, host or port is invalid
+var ErrInvalidRedisURL = errors.New("invalid redis url")
+
+// ErrInvalidSentinelConfig is when the redis sentinel config is missing or invalid
+var ErrInvalidSentinelConfig = errors.New("invalid redis sentinel config")
+
+// ErrClusterModeRequired is when a single node client is connected to a node of a redis cluster (MOVED or ASK redirection)
+var ErrClusterModeRequired = errors.New("redis node is part of a cluster, set RedisConfig.ClusterAddresses to use the cluster mode")
+
+// ErrSentinelRoleCheckFailed is when the connection is no longer to the master (failover)
+var ErrSentinelRoleCheckFailed = errors.New("redis sentinel role check failed, connection is not to the master")
+
 // ErrAppNameRequired is when the app name is required
 var ErrAppNameRequired = errors.New("app name is required")
+
+// ErrValueNotNumeric is when the value stored at a key is not an integer (counters)
+var ErrValueNotNumeric = errors.New("value stored at key is not an integer")
+
+// ErrValueNotTime is when the value stored at a key is not a RFC3339 time (see: GetTime)
+var ErrValueNotTime = errors.New("value stored at key is not a RFC3339 time")
+
+// ErrUnsupportedCompression is when the compression type is not supported
+var ErrUnsupportedCompression = errors.New("unsupported compression type")
+
+// ErrUnsupportedValueEncoding is when the value encoding is not supported
+var ErrUnsupportedValueEncoding = errors.New("unsupported value encoding")
+
+// ErrPoolExhausted is when all the connections are in use (MaxActiveConnections) and none was released in time
+//
+// See: RedisConfig.PoolWaitTimeout, the error wraps the redigo error (redis.ErrPoolExhausted or the context error)
+var ErrPoolExhausted = errors.New("redis connection pool exhausted")
+
+// ErrContextDone is when the context is canceled or the deadline is exceeded (wraps the context error)
+var ErrContextDone = errors.New("context is done, the cachestore operation was stopped")
+
+// ErrMetricsRegistration is when the Prometheus metrics cannot be registered (conflicting collectors)
+var ErrMetricsRegistration = errors.New("failed registering the cachestore prometheus metrics")
+
+// ErrModelMarshal is when the model cannot be encoded using the serializer (wraps the serializer error)
+var ErrModelMarshal = errors.New("failed encoding the model")
+
+// ErrNotMsgpack is when the value was not encoded using the msgpack serializer (ie: JSON, see: WithMsgpack)
+var ErrNotMsgpack = errors.New("value was not encoded using msgpack")
+
+// ErrNotJSON is when the stored value is not well-formed JSON (see: GetRaw)
+var ErrNotJSON = errors.New("value is not valid json")
+
+// ErrInvalidModelOutput is when the output of GetModelMulti is not a pointer to a slice, or a map with string keys
+var ErrInvalidModelOutput = errors.New("output must be a pointer to a slice, or a map with string keys")
+
+// ErrModelUnmarshal is when the value exists but cannot be decoded into the model (wraps the serializer error)
+var ErrModelUnmarshal = errors.New("failed decoding the cached value into the model")
+
+// ErrModelVersionMismatch is when the stored model has another version than the model (see: WithModelVersioning)
+//
+// Wraps ErrKeyNotFound, a mismatch is a miss (errors.Is(err, ErrKeyNotFound) is true)
+var ErrModelVersionMismatch = fmt.Errorf("stored model version does not match: %w", ErrKeyNotFound)
+
+// ErrInvalidFreeCacheSize is when the FreeCache size is below the minimum (MinFreeCacheSize)
+var ErrInvalidFreeCacheSize = errors.New("invalid freecache size")
+
+// ErrValueTooLarge is when the value exceeds the max value size (see: WithMaxValueSize) or the FreeCache entry size limit
+var ErrValueTooLarge = errors.New("value is too large for the cache")
+
+// ErrInternalPanic is when an operation panicked and the panic was recovered (see: WithPanicRecovery)
+var ErrInternalPanic = errors.New("cachestore operation panicked")
+
+// wrapError will add the operation, key and engine to an error (nil is returned as is)
+//
+// The error is wrapped, errors.Is and errors.As still match the sentinel errors (ie: ErrKeyRequired)
+func (c *Client) wrapError(operation, key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("cachestore: %s key=%q engine=%s: %w", operation, key, c.Engine(), err)
+}
+
+// checkClosed will return ErrClientClosed if the client has no engine (ie: after Close)
+func (c *Client) checkClosed() error {
+	if c.Engine().IsEmpty() {
+		return ErrClientClosed
+	}
+	return nil
+}
+
+// engineNotSupported will return ErrEngineNotSupported with the name of the current engine
+func (c *Client) engineNotSupported() error {
+	return fmt.Errorf("%w: %s", ErrEngineNotSupported, c.Engine())
+}
+
+// recoverPanic will set the error to the recovered panic of the operation (see: WithPanicRecovery)
+//
+// Must be deferred directly (recover only works in the deferred function), does nothing if disabled
+func (c *Client) recoverPanic(err *error) {
+	if !c.options.panicRecovery {
+		return
+	}
+	if recovered := recover(); recovered != nil {
+		if recoveredErr, ok := recovered.(error); ok {
+			*err = fmt.Errorf("%w: %w", ErrInternalPanic, recoveredErr)
+			return
+		}
+		*err = fmt.Errorf("%w: %v", ErrInternalPanic, recovered)
+	}
+}
@@ -0,0 +1,59 @@
+package cachestore
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// CacheEvent is a cache operation recorded into the context (see: WithObservabilityContext)
+type CacheEvent struct {
+	Duration  time.Duration // Latency of the operation
+	Key       string        // Key without the key prefix (the pattern for DeleteByPattern and Scan, empty for the multi key operations)
+	Operation string        // Name used in the metrics (get, set, delete, write_lock...)
+	Result    string        // hit, miss, success or error
+}
+
+// cacheEventsKey is the context key of the collector
+type cacheEventsKey struct{}
+
+// cacheEvents is the collector of the events for a context (safe for concurrent use)
+type cacheEvents struct {
+	events []CacheEvent
+	sync.Mutex
+}
+
+// ContextWithCacheEvents will return a child context that collects the cache events (see: WithObservabilityContext)
+//
+// The contexts derived from it share the same collector, a context that already has a collector is returned as is
+func ContextWithCacheEvents(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(cacheEventsKey{}).(*cacheEvents); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheEventsKey{}, &cacheEvents{})
+}
+
+// CacheEventsFromContext will return a copy of the cache events recorded in the context (in the order completed)
+//
+// Returns nil if the context has no collector (see: ContextWithCacheEvents)
+func CacheEventsFromContext(ctx context.Context) []CacheEvent {
+	collector, ok := ctx.Value(cacheEventsKey{}).(*cacheEvents)
+	if !ok {
+		return nil
+	}
+	collector.Lock()
+	defer collector.Unlock()
+	return append([]CacheEvent{}, collector.events...)
+}
+
+// recordEvent will add the event to the collector of the context (if any)
+func recordEvent(ctx context.Context, event CacheEvent) {
+	if ctx == nil {
+		return
+	}
+	if collector, ok := ctx.Value(cacheEventsKey{}).(*cacheEvents); ok {
+		collector.Lock()
+		collector.events = append(collector.events, event)
+		collector.Unlock()
+	}
+}
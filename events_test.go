@@ -0,0 +1,123 @@
+package cachestore
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCacheEventsFromContext will test the methods ContextWithCacheEvents() and CacheEventsFromContext()
+func TestCacheEventsFromContext(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no collector", func(t *testing.T) {
+		assert.Nil(t, CacheEventsFromContext(context.Background()))
+		recordEvent(context.Background(), CacheEvent{Operation: operationGet})
+	})
+
+	t.Run("derived contexts share the collector", func(t *testing.T) {
+		ctx := ContextWithCacheEvents(context.Background())
+		assert.Empty(t, CacheEventsFromContext(ctx))
+		assert.NotNil(t, CacheEventsFromContext(ctx))
+
+		child, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		assert.Equal(t, child, ContextWithCacheEvents(child))
+
+		recordEvent(child, CacheEvent{Key: testKey, Operation: operationGet, Result: resultHit})
+		assert.Equal(t, []CacheEvent{{Key: testKey, Operation: operationGet, Result: resultHit}}, CacheEventsFromContext(ctx))
+
+		// A copy is returned
+		events := CacheEventsFromContext(ctx)
+		events[0].Key = "changed"
+		assert.Equal(t, testKey, CacheEventsFromContext(ctx)[0].Key)
+	})
+}
+
+// TestClient_ObservabilityContext will test the option WithObservabilityContext()
+func TestClient_ObservabilityContext(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - operations are recorded", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithObservabilityContext(), WithKeyPrefix("app"))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			ctx := ContextWithCacheEvents(context.Background())
+			require.NoError(t, c.Set(ctx, testKey, testValue))
+			_, err = c.Get(ctx, testKey)
+			require.NoError(t, err)
+			_, err = c.Get(ctx, testKey+"-missing")
+			require.NoError(t, err)
+			err = c.GetModel(ctx, testKey+"-missing", new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			require.NoError(t, c.Delete(ctx, testKey))
+			_, err = c.Get(ctx, "  ")
+			require.Error(t, err)
+
+			events := CacheEventsFromContext(ctx)
+			require.Len(t, events, 6)
+			for _, event := range events {
+				assert.Positive(t, event.Duration)
+			}
+			assert.Equal(t, CacheEvent{Key: testKey, Operation: operationSet, Result: resultSuccess, Duration: events[0].Duration}, events[0])
+			assert.Equal(t, CacheEvent{Key: testKey, Operation: operationGet, Result: resultHit, Duration: events[1].Duration}, events[1])
+			assert.Equal(t, CacheEvent{Key: testKey + "-missing", Operation: operationGet, Result: resultMiss, Duration: events[2].Duration}, events[2])
+			assert.Equal(t, CacheEvent{Key: testKey + "-missing", Operation: operationGetModel, Result: resultMiss, Duration: events[3].Duration}, events[3])
+			assert.Equal(t, CacheEvent{Key: testKey, Operation: operationDelete, Result: resultSuccess, Duration: events[4].Duration}, events[4])
+			assert.Equal(t, resultError, events[5].Result)
+
+			// Another context has its own events
+			assert.Nil(t, CacheEventsFromContext(context.Background()))
+		})
+
+		t.Run(testCase.name+" - concurrent operations", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithObservabilityContext())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			// A request that fans out
+			const total = 20
+			ctx := ContextWithCacheEvents(context.Background())
+			var wg sync.WaitGroup
+			for i := 0; i < total; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					child, cancel := context.WithTimeout(ctx, time.Minute)
+					defer cancel()
+					assert.NoError(t, c.Set(child, testKey+strconv.Itoa(i), testValue))
+				}(i)
+			}
+			wg.Wait()
+
+			keys := make([]string, 0, total)
+			for _, event := range CacheEventsFromContext(ctx) {
+				assert.Equal(t, operationSet, event.Operation)
+				keys = append(keys, event.Key)
+			}
+			assert.Len(t, keys, total)
+			for i := 0; i < total; i++ {
+				assert.Contains(t, keys, testKey+strconv.Itoa(i))
+			}
+		})
+
+		t.Run(testCase.name+" - not recorded by default", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			ctx := ContextWithCacheEvents(context.Background())
+			require.NoError(t, c.Set(ctx, testKey, testValue))
+			_, err = c.Get(ctx, testKey)
+			require.NoError(t, err)
+			assert.Empty(t, CacheEventsFromContext(ctx))
+		})
+	}
+}
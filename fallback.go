@@ -0,0 +1,67 @@
+package cachestore
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// hasFallback will return true if the client has a fallback cache for Redis (see: WithEngineFallback)
+func (c *Client) hasFallback() bool {
+	return c.options.fallback != nil && c.Engine().usesRedis()
+}
+
+// readFallback will read the key from the fallback cache if the Redis error is a connection error
+//
+// Returns false if the fallback is disabled or the error is not a connection error (the error is returned)
+// A key missing from the fallback cache is a miss (found is false)
+func (c *Client) readFallback(key string, err error) (data []byte, found, ok bool) {
+	if !c.hasFallback() || !isConnectionError(err) {
+		return nil, false, false
+	}
+	if data, err = c.options.fallback.Get([]byte(key)); err != nil {
+		return nil, false, true
+	}
+	return data, true, true
+}
+
+// setFallback will store the value in the fallback cache (if enabled)
+//
+// The value expires with the key, errors are ignored (value too large)
+func (c *Client) setFallback(key string, value []byte, ttl time.Duration) {
+	if !c.hasFallback() {
+		return
+	}
+	_ = c.options.fallback.Set([]byte(key), value, int(ttl.Seconds()))
+}
+
+// deleteFallback will remove the keys from the fallback cache (if enabled)
+func (c *Client) deleteFallback(keys ...string) {
+	if !c.hasFallback() {
+		return
+	}
+	for _, key := range keys {
+		_ = c.options.fallback.Del([]byte(key))
+	}
+}
+
+// clearFallback will remove all the keys from the fallback cache (if enabled)
+func (c *Client) clearFallback() {
+	if !c.hasFallback() {
+		return
+	}
+	c.options.fallback.Clear()
+}
+
+// isConnectionError will return true if Redis cannot be reached (infrastructure), not for a logical error
+//
+// Refused, reset or closed connections, network errors, timeouts and an exhausted pool (see: RedisConfig.PoolWaitTimeout)
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, ErrContextDone) {
+		return false
+	} else if isRetryableError(err) || errors.Is(err, ErrPoolExhausted) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
@@ -0,0 +1,233 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coocood/freecache"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFallbackTestClient will return a redis client with a fallback cache, the in-memory redis and the fallback cache
+func newFallbackTestClient(t *testing.T) (ClientInterface, *miniredis.Miniredis, *freecache.Cache) {
+	r := loadRedisInMemoryClient(t)
+	local := freecache.NewCache(DefaultCacheSize)
+
+	c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithEngineFallback(local))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c, r, local
+}
+
+// TestClient_EngineFallback will test reading from the fallback cache when redis is unavailable (WithEngineFallback)
+func TestClient_EngineFallback(t *testing.T) {
+
+	t.Run("reads continue from the local cache when redis stops", func(t *testing.T) {
+		c, r, _ := newFallbackTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0))
+
+		// Redis is stopped mid-run
+		r.Close()
+
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+
+		model := new(genericStruct)
+		require.NoError(t, c.GetModel(context.Background(), testKey+"-model", model))
+		assert.Equal(t, testValue, model.StringField)
+
+		// A key missing from the local cache is a miss
+		value, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+		assert.Empty(t, value)
+		require.ErrorIs(t, c.GetModel(context.Background(), testKey+"-missing", model), ErrKeyNotFound)
+
+		// Writes still fail (the local copy is updated)
+		require.Error(t, c.Set(context.Background(), testKey, testValue+"-new"))
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue+"-new", value)
+
+		// Redis is read again once it is back
+		require.NoError(t, r.Restart())
+		require.NoError(t, r.Set(testKey, testValue+"-redis"))
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue+"-redis", value)
+	})
+
+	t.Run("delete removes the local copy", func(t *testing.T) {
+		c, r, local := newFallbackTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		require.NoError(t, c.Set(context.Background(), testKey+"-2", testValue))
+		require.NoError(t, c.Delete(context.Background(), testKey))
+		require.NoError(t, c.DeleteMany(context.Background(), testKey+"-2"))
+		assert.Equal(t, int64(0), local.EntryCount())
+
+		r.Close()
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Empty(t, value)
+	})
+
+	t.Run("every delete and write updates the local copy", func(t *testing.T) {
+		ctx := context.Background()
+		tests := []struct {
+			name     string
+			write    func(c ClientInterface) error
+			expected string // The value read from the local copy once redis is stopped (empty if removed)
+		}{
+			{"DeleteByPattern", func(c ClientInterface) error {
+				_, err := c.DeleteByPattern(ctx, testKey+"*")
+				return err
+			}, ""},
+			{"DeleteDependency", func(c ClientInterface) error {
+				return c.DeleteDependency(ctx, "user")
+			}, ""},
+			{"DeleteModel", func(c ClientInterface) error {
+				return c.DeleteModel(ctx, testKey)
+			}, ""},
+			{"EmptyCache", func(c ClientInterface) error {
+				return c.EmptyCache(ctx)
+			}, ""},
+			{"SetMulti", func(c ClientInterface) error {
+				return c.SetMulti(ctx, map[string]string{testKey: testValue + "-new"})
+			}, testValue + "-new"},
+			{"GetSet", func(c ClientInterface) error {
+				_, _, err := c.GetSet(ctx, testKey, testValue+"-new")
+				return err
+			}, testValue + "-new"},
+			{"CompareAndSwap", func(c ClientInterface) error {
+				_, err := c.CompareAndSwap(ctx, testKey, testValue, testValue+"-new", 0)
+				return err
+			}, testValue + "-new"},
+			{"CompareAndSwap (not swapped)", func(c ClientInterface) error {
+				_, err := c.CompareAndSwap(ctx, testKey, "other", testValue+"-new", 0)
+				return err
+			}, ""},
+			{"Increment", func(c ClientInterface) error {
+				require.NoError(t, c.SetInt(ctx, testKey, 1, 0))
+				_, err := c.Increment(ctx, testKey, 1)
+				return err
+			}, ""},
+			{"Append", func(c ClientInterface) error {
+				_, err := c.Append(ctx, testKey, "-new")
+				return err
+			}, ""},
+			{"Pipeline Set", func(c ClientInterface) error {
+				return c.Pipeline(ctx, func(p Pipeliner) error {
+					p.Set(testKey, testValue+"-new", 0)
+					return nil
+				})
+			}, testValue + "-new"},
+			{"Pipeline Delete", func(c ClientInterface) error {
+				return c.Pipeline(ctx, func(p Pipeliner) error {
+					p.Delete(testKey)
+					return nil
+				})
+			}, ""},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				c, r, _ := newFallbackTestClient(t)
+
+				require.NoError(t, c.Set(ctx, testKey, testValue, "user"))
+				require.NoError(t, test.write(c))
+
+				// Redis is stopped, the deleted or overwritten value is never returned
+				r.Close()
+				value, err := c.Get(ctx, testKey)
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, value)
+			})
+		}
+
+		t.Run("models", func(t *testing.T) {
+			c, r, _ := newFallbackTestClient(t)
+
+			require.NoError(t, c.SetModel(ctx, testKey, &genericStruct{StringField: testValue}, 0))
+			require.NoError(t, c.SetModel(ctx, testKey+"-2", &genericStruct{StringField: testValue}, 0))
+			require.NoError(t, c.SetModelMulti(ctx, map[string]interface{}{
+				testKey: &genericStruct{StringField: testValue + "-multi"},
+			}, 0))
+			replaced, err := c.ReplaceModel(ctx, testKey+"-2", &genericStruct{StringField: testValue + "-replaced"}, 0)
+			require.NoError(t, err)
+			assert.True(t, replaced)
+
+			r.Close()
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(ctx, testKey, model))
+			assert.Equal(t, testValue+"-multi", model.StringField)
+			require.NoError(t, c.GetModel(ctx, testKey+"-2", model))
+			assert.Equal(t, testValue+"-replaced", model.StringField)
+		})
+	})
+
+	t.Run("logical errors do not fall back", func(t *testing.T) {
+		c, r, local := newFallbackTestClient(t)
+
+		_, err := c.Get(context.Background(), "  ")
+		require.ErrorIs(t, err, ErrKeyRequired)
+
+		// An error reply from redis is returned (not the local copy)
+		require.NoError(t, local.Set([]byte(testKey), []byte(testValue), 0))
+		_, err = r.SetAdd(testKey, testValue)
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "WRONGTYPE")
+	})
+
+	t.Run("without a fallback the error is returned", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		r.Close()
+		_, err = c.Get(context.Background(), testKey)
+		require.Error(t, err)
+	})
+}
+
+// Test_isConnectionError will test the method isConnectionError()
+func Test_isConnectionError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err        error
+		name       string
+		connection bool
+	}{
+		{name: "nil", err: nil, connection: false},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, connection: true},
+		{name: "dial error", err: &net.OpError{Op: "dial", Err: errors.New("no such host")}, connection: true},
+		{name: "connection reset", err: syscall.ECONNRESET, connection: true},
+		{name: "eof", err: io.EOF, connection: true},
+		{name: "pool exhausted", err: fmt.Errorf("%w: %w", ErrPoolExhausted, redis.ErrPoolExhausted), connection: true},
+		{name: "redis error", err: redis.Error("WRONGTYPE Operation against a key"), connection: false},
+		{name: "key required", err: ErrKeyRequired, connection: false},
+		{name: "context done", err: fmt.Errorf("%w: %w", ErrContextDone, context.DeadlineExceeded), connection: false},
+		{name: "other", err: errors.New("other"), connection: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.connection, isConnectionError(test.err))
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package cachestore
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"runtime/debug"
+	"strconv"
+	"time"
 
 	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
@@ -15,18 +20,27 @@ const (
 
 	// DefaultGCPercent is the percentage when full it will run GC
 	DefaultGCPercent = 20
+
+	// MinFreeCacheSize is the smallest cache size (in bytes) supported by FreeCache (512KB)
+	MinFreeCacheSize = 512 * 1024
+
+	// freeCacheEntryHeaderSize is the size (bytes) of the header stored with each FreeCache entry
+	freeCacheEntryHeaderSize = 24
+
+	// freeCacheSegments is the number of segments in a FreeCache (each entry must fit in 1/4 of a segment)
+	freeCacheSegments = 256
 )
 
-// loadFreeCache will load the FreeCache client
+// loadFreeCache will load the FreeCache client (the expirations use the clock)
 //
 // This is a default cache solution for running a local single server.
-func loadFreeCache(cacheSize, percent int) (c *freecache.Cache) {
+func loadFreeCache(cacheSize, percent int, clock Clock) (c *freecache.Cache) {
 
 	// Set the defaults for cache size
 	if cacheSize <= 0 {
 		cacheSize = DefaultCacheSize
 	}
-	c = freecache.NewCache(cacheSize)
+	c = freecache.NewCacheCustomTimer(cacheSize, freeCacheTimer{clock: clock})
 
 	// Set the default GC percent
 	if percent <= 0 {
@@ -36,28 +50,142 @@ func loadFreeCache(cacheSize, percent int) (c *freecache.Cache) {
 	return
 }
 
+// freeCacheEntryLimit will return the max size (bytes) of an entry (key and value) for the cache size
+func freeCacheEntryLimit(cacheSize int) int {
+	if cacheSize < MinFreeCacheSize { // FreeCache raises smaller sizes to the minimum
+		cacheSize = MinFreeCacheSize
+	}
+	return cacheSize/freeCacheSegments/4 - freeCacheEntryHeaderSize
+}
+
+// setFreeCache will set the key->value, returning ErrValueTooLarge if the entry exceeds the size limit
+//
+// entryLimit is the max size of an entry (0 if unknown), ttl is in seconds
+func setFreeCache(freeCacheClient *freecache.Cache, entryLimit int, key string, value []byte, ttl int) error {
+	return largeEntryError(entryLimit, key, value, freeCacheClient.Set([]byte(key), value, ttl))
+}
+
+// replaceFreeCache will set the key->value only if the key exists (returns false if the key does not exist)
+//
+// ttl is in seconds
+// The check and write happen atomically (under the FreeCache segment lock)
+func replaceFreeCache(freeCacheClient *freecache.Cache, entryLimit int, key string, value []byte, ttl int) (bool, error) {
+	_, replaced, err := freeCacheClient.Update([]byte(key), func(_ []byte, found bool) ([]byte, bool, int) {
+		return value, found, ttl
+	})
+	if err != nil {
+		return false, largeEntryError(entryLimit, key, value, err)
+	}
+	return replaced, nil
+}
+
+// compareAndSwapFreeCache will set the key->value only if the current value is the expected value
+//
+// ttl is in seconds, returns false if the key does not exist or has a different value (nothing is written)
+// The compare and write happen atomically (under the FreeCache segment lock)
+func compareAndSwapFreeCache(freeCacheClient *freecache.Cache, entryLimit int, key string, expected, value []byte,
+	ttl int) (bool, error) {
+	_, swapped, err := freeCacheClient.Update([]byte(key), func(current []byte, found bool) ([]byte, bool, int) {
+		return value, found && bytes.Equal(current, expected), ttl
+	})
+	if err != nil {
+		return false, largeEntryError(entryLimit, key, value, err)
+	}
+	return swapped, nil
+}
+
+// getAndRefreshFreeCache will return the value and update the expiration of the key (ttl is in seconds)
+//
+// The value is written again with the new expiration under the FreeCache segment lock (atomic)
+// Returns ErrKeyNotFound if the key does not exist (nothing is written)
+func getAndRefreshFreeCache(freeCacheClient *freecache.Cache, key string, ttl int) ([]byte, error) {
+	var value []byte
+	found, _, err := freeCacheClient.Update([]byte(key), func(current []byte, found bool) ([]byte, bool, int) {
+		value = current
+		return current, found, ttl
+	})
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrKeyNotFound
+	}
+	return value, nil
+}
+
+// getSetFreeCache will set the key->value and return the previous value (if the key existed)
+//
+// ttl is in seconds
+// The read and write happen atomically (under the FreeCache segment lock)
+func getSetFreeCache(freeCacheClient *freecache.Cache, entryLimit int, key string, value []byte,
+	ttl int) ([]byte, bool, error) {
+	old, found, err := freeCacheClient.SetAndGet([]byte(key), value, ttl)
+	if err != nil {
+		return nil, false, largeEntryError(entryLimit, key, value, err)
+	}
+	return old, found, nil
+}
+
+// largeEntryError will wrap freecache.ErrLargeEntry with ErrValueTooLarge (other errors are returned as is)
+//
+// entryLimit is the max size of an entry (0 if unknown)
+func largeEntryError(entryLimit int, key string, value []byte, err error) error {
+	if !errors.Is(err, freecache.ErrLargeEntry) {
+		return err
+	}
+	if entryLimit > 0 {
+		return fmt.Errorf(
+			"%w: value is %d bytes, the limit is %d bytes for this key: %w",
+			ErrValueTooLarge, len(value), entryLimit-len(key), err,
+		)
+	}
+	return fmt.Errorf(
+		"%w: value is %d bytes, the limit is about 1/1024 of the freecache size: %w",
+		ErrValueTooLarge, len(value), err,
+	)
+}
+
 // writeLockFreeCache will write a lock record into memory using a secret and expiration
 //
 // ttl is in seconds
+// The check and write happen atomically (under the FreeCache segment lock)
 func writeLockFreeCache(freeCacheClient *freecache.Cache, lockKey, secret string, ttl int64) (bool, error) { //nolint:unparam // bool is not being used yet
 
-	// Try to get an existing lock (if it fails, make a new lock)
-	lockKeyBytes := []byte(lockKey)
+	// Write the lock if it does not exist, or if it exists with the same secret
 	secretBytes := []byte(secret)
-	data, err := freeCacheClient.Get(lockKeyBytes)
-	if err != nil && errors.Is(err, freecache.ErrNotFound) {
-		return true, freeCacheClient.Set(lockKeyBytes, secretBytes, int(ttl))
-	} else if err != nil {
+	_, replaced, err := freeCacheClient.Update([]byte(lockKey), func(value []byte, found bool) ([]byte, bool, int) {
+		if found && !secretMatches(value, secretBytes) { // Secret mismatch (lock exists with different secret)
+			return nil, false, 0
+		}
+		return secretBytes, true, int(ttl)
+	})
+	if err != nil {
 		return false, err
+	} else if !replaced {
+		return false, cache.ErrLockMismatch
 	}
+	return true, nil
+}
+
+// extendLockFreeCache will update the expiration of a lock only if it exists and matches the given secret
+//
+// ttl is in seconds
+// The check and write happen atomically (under the FreeCache segment lock)
+func extendLockFreeCache(freeCacheClient *freecache.Cache, lockKey, secret string, ttl int64) (bool, error) {
 
-	// Check secret
-	if string(data) != secret { // Secret mismatch (lock exists with different secret)
+	// Re-write the lock with the new expiration (only if the secret matches)
+	secretBytes := []byte(secret)
+	_, replaced, err := freeCacheClient.Update([]byte(lockKey), func(value []byte, found bool) ([]byte, bool, int) {
+		if !found || !secretMatches(value, secretBytes) { // Lock does not exist or has a different secret
+			return nil, false, 0
+		}
+		return value, true, int(ttl)
+	})
+	if err != nil {
+		return false, err
+	} else if !replaced {
 		return false, cache.ErrLockMismatch
 	}
-
-	// Same secret / lock again?
-	return true, freeCacheClient.Set(lockKeyBytes, secretBytes, int(ttl))
+	return true, nil
 }
 
 // releaseLockFreeCache will attempt to release a lock if it exists and matches the given secret
@@ -73,7 +201,7 @@ func releaseLockFreeCache(freeCacheClient *freecache.Cache, lockKey, secret stri
 	}
 
 	// Check secret if found
-	if string(data) == secret { // If it matches, remove the key
+	if secretMatches(data, []byte(secret)) { // If it matches, remove the key
 		freeCacheClient.Del(lockKeyBytes)
 		return true, nil
 	}
@@ -81,3 +209,95 @@ func releaseLockFreeCache(freeCacheClient *freecache.Cache, lockKey, secret stri
 	// Key found does not match the secret, do not remove
 	return false, cache.ErrLockMismatch
 }
+
+// secretMatches will compare the stored secret of a lock with the given secret in constant time (timing analysis)
+func secretMatches(stored, secret []byte) bool {
+	return subtle.ConstantTimeCompare(stored, secret) == 1
+}
+
+// forceReleaseLockFreeCache will remove the lock regardless of the secret (returns true if the lock existed)
+func forceReleaseLockFreeCache(freeCacheClient *freecache.Cache, lockKey string) bool {
+	return freeCacheClient.Del([]byte(lockKey))
+}
+
+// incrementFreeCache will add the delta to the integer stored at the key (missing keys start at zero)
+//
+// The existing expiration of the key is preserved (now is the current time of the clock)
+// This is not atomic by itself, the caller must hold a lock on the key
+func incrementFreeCache(freeCacheClient *freecache.Cache, key string, delta int64, now time.Time) (int64, error) {
+
+	// Get the current value (if it exists)
+	var current int64
+	keyBytes := []byte(key)
+	data, expireAt, err := freeCacheClient.GetWithExpiration(keyBytes)
+	if err != nil && !errors.Is(err, freecache.ErrNotFound) {
+		return 0, err
+	} else if err == nil {
+		if current, err = strconv.ParseInt(string(data), 10, 64); err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrValueNotNumeric, err.Error())
+		}
+	}
+
+	// Store the new value (keeping the remaining ttl)
+	current += delta
+	return current, freeCacheClient.Set(
+		keyBytes, []byte(strconv.FormatInt(current, 10)), remainingFreeCacheTTL(now, expireAt),
+	)
+}
+
+// appendFreeCache will append the value to the bytes stored at the key (missing keys start empty)
+//
+// entryLimit is the max size of an entry (0 if unknown)
+// The existing expiration of the key is preserved (now is the current time of the clock)
+// This is not atomic by itself, the caller must hold a lock on the key
+func appendFreeCache(freeCacheClient *freecache.Cache, entryLimit int, key, value string, now time.Time) (int, error) {
+
+	// Get the current value (if it exists)
+	keyBytes := []byte(key)
+	data, expireAt, err := freeCacheClient.GetWithExpiration(keyBytes)
+	if err != nil && !errors.Is(err, freecache.ErrNotFound) {
+		return 0, err
+	}
+
+	// Store the new value (keeping the remaining ttl)
+	data = append(data, value...)
+	if err = setFreeCache(
+		freeCacheClient, entryLimit, key, data, remainingFreeCacheTTL(now, expireAt),
+	); err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// remainingFreeCacheTTL will return the seconds left from now until the expiration (0 is no expiration)
+func remainingFreeCacheTTL(now time.Time, expireAt uint32) int {
+	if expireAt == 0 {
+		return 0
+	}
+	if remaining := int64(expireAt) - now.Unix(); remaining > 0 {
+		return int(remaining)
+	}
+	return 1
+}
+
+// deleteByPrefixFreeCache will remove all the keys that start with the prefix (returns the total removed)
+func deleteByPrefixFreeCache(freeCacheClient *freecache.Cache, prefix string) (total int) {
+
+	// Find the keys first (do not remove while iterating)
+	var keys [][]byte
+	prefixBytes := []byte(prefix)
+	iterator := freeCacheClient.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if bytes.HasPrefix(entry.Key, prefixBytes) {
+			keys = append(keys, entry.Key)
+		}
+	}
+
+	// Remove the keys
+	for _, key := range keys {
+		if freeCacheClient.Del(key) {
+			total++
+		}
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package cachestore
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+// freeCacheSweeper removes the expired entries of a FreeCache in the background (see: WithFreeCacheExpireInterval)
+// and reports the evictions (see: WithOnEvicted)
+//
+// FreeCache only removes an expired entry when the key is read or the space is reused,
+// and the iterator skips expired entries, so each sweep records the keys that expire before
+// the next sweep and removes them once they have expired
+type freeCacheSweeper struct {
+	clock     Clock                  // Current time for the expirations
+	done      chan struct{}          // Closed to stop the goroutine
+	evacuated int64                  // FreeCache EvacuateCount at the last check
+	expire    bool                   // Remove the expired entries (see: WithFreeCacheExpireInterval)
+	interval  time.Duration          // Time between the sweeps
+	onEvicted func(countDelta int64) // Called with the entries evicted since the last check (if set)
+	pending   map[string]uint32      // Keys expiring before the next sweep -> expiration (unix seconds)
+	stopOnce  sync.Once              // Stop only once
+	stopped   chan struct{}          // Closed when the goroutine exits
+}
+
+// newFreeCacheSweeper will create a sweeper (start it using run)
+func newFreeCacheSweeper(interval time.Duration, clock Clock) *freeCacheSweeper {
+	return &freeCacheSweeper{
+		clock:    clock,
+		done:     make(chan struct{}),
+		interval: interval,
+		pending:  make(map[string]uint32),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// startFreeCacheSweeper will create a sweeper and start the goroutine
+//
+// The expired entries are removed if the interval is set, otherwise the evictions are checked every
+// DefaultEvictionCheckInterval (onEvicted is optional)
+func startFreeCacheSweeper(freeCacheClient *freecache.Cache, interval time.Duration, clock Clock,
+	onEvicted func(countDelta int64),
+) *freeCacheSweeper {
+	s := newFreeCacheSweeper(interval, clock)
+	if s.expire = interval > 0; !s.expire {
+		s.interval = DefaultEvictionCheckInterval
+	}
+	s.onEvicted = onEvicted
+	s.evacuated = freeCacheClient.EvacuateCount()
+	go s.run(freeCacheClient)
+	return s
+}
+
+// run will sweep the cache every interval until the sweeper is stopped
+func (s *freeCacheSweeper) run(freeCacheClient *freecache.Cache) {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if s.expire {
+				s.sweep(freeCacheClient)
+			}
+			s.checkEvictions(freeCacheClient)
+		}
+	}
+}
+
+// sweep will remove the recorded keys that have expired and record the keys expiring before the next sweep
+//
+// The entries removed are counted in the native FreeCache statistics (ExpiredCount and MissCount)
+func (s *freeCacheSweeper) sweep(freeCacheClient *freecache.Cache) {
+	now := s.clock.Now().Unix()
+
+	// Remove the expired keys (TTL does not change the statistics, Get removes the expired entry atomically)
+	for key, expireAt := range s.pending {
+		if int64(expireAt) > now {
+			continue
+		}
+		delete(s.pending, key)
+		if _, err := freeCacheClient.TTL([]byte(key)); errors.Is(err, freecache.ErrNotFound) {
+			_, _ = freeCacheClient.Get([]byte(key))
+		}
+	}
+
+	// Record the keys that expire before the next sweep
+	next := now + int64(s.interval/time.Second) + 1
+	iterator := freeCacheClient.NewIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if entry.ExpireAt != 0 && int64(entry.ExpireAt) <= next {
+			s.pending[string(entry.Key)] = entry.ExpireAt
+		}
+	}
+}
+
+// checkEvictions will call onEvicted with the entries evicted since the last check (if any)
+//
+// FreeCache has no eviction callback, the EvacuateCount is compared (reset by ResetStats)
+func (s *freeCacheSweeper) checkEvictions(freeCacheClient *freecache.Cache) {
+	if s.onEvicted == nil {
+		return
+	}
+	count := freeCacheClient.EvacuateCount()
+	delta := count - s.evacuated
+	s.evacuated = count
+	if delta > 0 {
+		s.onEvicted(delta)
+	}
+}
+
+// stop will stop the goroutine and wait for it to exit (safe to call more than once)
+func (s *freeCacheSweeper) stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+	<-s.stopped
+}
@@ -0,0 +1,206 @@
+package cachestore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_freeCacheSweeper_sweep will test the method sweep()
+func Test_freeCacheSweeper_sweep(t *testing.T) {
+	t.Parallel()
+
+	t.Run("expired entries are removed", func(t *testing.T) {
+		clock := &testClock{}
+		c := freecache.NewCacheCustomTimer(MinFreeCacheSize, freeCacheTimer{clock: clock})
+		require.NoError(t, c.Set([]byte(testKey+"-expires"), []byte(testValue), 10))
+		require.NoError(t, c.Set([]byte(testKey+"-later"), []byte(testValue), 3600))
+		require.NoError(t, c.Set([]byte(testKey+"-forever"), []byte(testValue), 0))
+
+		s := newFreeCacheSweeper(time.Minute, clock)
+		s.sweep(c)
+		assert.Len(t, s.pending, 1)
+
+		// Expired entries are still stored until removed
+		clock.Advance(20 * time.Second)
+		assert.Equal(t, int64(3), c.EntryCount())
+
+		s.sweep(c)
+		assert.Equal(t, int64(2), c.EntryCount())
+		assert.Equal(t, int64(1), c.ExpiredCount())
+		assert.Empty(t, s.pending)
+	})
+
+	t.Run("keys written again are kept", func(t *testing.T) {
+		clock := &testClock{}
+		c := freecache.NewCacheCustomTimer(MinFreeCacheSize, freeCacheTimer{clock: clock})
+		require.NoError(t, c.Set([]byte(testKey), []byte(testValue), 10))
+
+		s := newFreeCacheSweeper(time.Minute, clock)
+		s.sweep(c)
+
+		require.NoError(t, c.Set([]byte(testKey), []byte(testValue), 3600))
+		clock.Advance(20 * time.Second)
+
+		s.sweep(c)
+		value, err := c.Get([]byte(testKey))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(value))
+		assert.Equal(t, int64(0), c.ExpiredCount())
+	})
+}
+
+// TestClient_FreeCacheExpireInterval will test the background sweeper (WithFreeCacheExpireInterval)
+func TestClient_FreeCacheExpireInterval(t *testing.T) {
+
+	t.Run("disabled by default", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		assert.Nil(t, c.(*Client).options.sweeper)
+	})
+
+	t.Run("not used by redis", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+			URL: loadRedisInMemoryClient(t).Addr(),
+		}), WithFreeCacheExpireInterval(time.Second))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		assert.Nil(t, c.(*Client).options.sweeper)
+	})
+
+	t.Run("expired entries are removed in the background", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithFreeCacheExpireInterval(time.Second))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+		require.NotNil(t, c.(*Client).options.sweeper)
+
+		require.NoError(t, c.SetTTL(context.Background(), testKey, testValue, 3*time.Second))
+		require.NoError(t, c.Set(context.Background(), testKey+"-forever", testValue))
+
+		// The key is recorded by a sweep before it expires, and removed by a later sweep
+		assert.Eventually(t, func() bool {
+			return c.FreeCache().EntryCount() == 1
+		}, 8*time.Second, 50*time.Millisecond)
+		assert.Equal(t, int64(1), c.FreeCache().ExpiredCount())
+	})
+
+	t.Run("the goroutine exits on close", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithFreeCacheExpireInterval(time.Second))
+		require.NoError(t, err)
+
+		sweeper := c.(*Client).options.sweeper
+		require.NotNil(t, sweeper)
+
+		c.Close(context.Background())
+		assert.Nil(t, c.(*Client).options.sweeper)
+		select {
+		case <-sweeper.stopped:
+		default:
+			assert.Fail(t, "sweeper goroutine is still running")
+		}
+
+		// Closing again (or stopping again) is safe
+		c.Close(context.Background())
+		sweeper.stop()
+	})
+
+	t.Run("the local tier is swept", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithTieredCache(nil, &RedisConfig{
+			URL: loadRedisInMemoryClient(t).Addr(),
+		}, time.Minute), WithFreeCacheExpireInterval(time.Second))
+		require.NoError(t, err)
+
+		sweeper := c.(*Client).options.sweeper
+		require.NotNil(t, sweeper)
+
+		c.Close(context.Background())
+		<-sweeper.stopped
+	})
+}
+
+// Test_freeCacheSweeper_checkEvictions will test the method checkEvictions()
+func Test_freeCacheSweeper_checkEvictions(t *testing.T) {
+	t.Parallel()
+
+	c := freecache.NewCache(MinFreeCacheSize)
+	var deltas []int64
+	s := newFreeCacheSweeper(time.Minute, &testClock{})
+	s.onEvicted = func(countDelta int64) {
+		deltas = append(deltas, countDelta)
+	}
+
+	// Nothing evicted
+	s.checkEvictions(c)
+	assert.Empty(t, deltas)
+
+	// Fill the cache over its capacity (the oldest entries are evicted)
+	value := make([]byte, 256)
+	for i := 0; i < 4*MinFreeCacheSize/len(value); i++ {
+		require.NoError(t, c.Set([]byte(testKey+strconv.Itoa(i)), value, 0))
+	}
+	require.Positive(t, c.EvacuateCount())
+	s.checkEvictions(c)
+	require.Len(t, deltas, 1)
+	assert.Equal(t, c.EvacuateCount(), deltas[0])
+
+	// Only the new evictions are reported (a reset of the statistics is not an eviction)
+	s.checkEvictions(c)
+	c.ResetStatistics()
+	s.checkEvictions(c)
+	assert.Len(t, deltas, 1)
+}
+
+// TestClient_OnEvicted will test the eviction callback (WithOnEvicted)
+func TestClient_OnEvicted(t *testing.T) {
+
+	t.Run("disabled by default", func(t *testing.T) {
+		options := defaultClientOptions()
+		assert.Nil(t, options.onEvicted)
+	})
+
+	t.Run("the evictions of a full cache are reported", func(t *testing.T) {
+		var evicted atomic.Int64
+		c, err := NewClient(context.Background(),
+			WithFreeCache(), WithFreeCacheSize(MinFreeCacheSize), WithFreeCacheExpireInterval(time.Second),
+			WithOnEvicted(func(countDelta int64) {
+				evicted.Add(countDelta)
+			}),
+		)
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		value := strings.Repeat("v", 256)
+		for i := 0; i < 4*MinFreeCacheSize/len(value); i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+strconv.Itoa(i), value))
+		}
+		require.Positive(t, c.FreeCache().EvacuateCount())
+
+		assert.Eventually(t, func() bool {
+			return evicted.Load() > 0
+		}, 5*time.Second, 50*time.Millisecond)
+		assert.Equal(t, c.FreeCache().EvacuateCount(), evicted.Load())
+	})
+
+	t.Run("the goroutine starts without an expire interval, and exits on close", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithOnEvicted(func(int64) {}))
+		require.NoError(t, err)
+
+		sweeper := c.(*Client).options.sweeper
+		require.NotNil(t, sweeper)
+		assert.False(t, sweeper.expire)
+		assert.Equal(t, DefaultEvictionCheckInterval, sweeper.interval)
+
+		c.Close(context.Background())
+		<-sweeper.stopped
+	})
+}
@@ -1,19 +1,84 @@
 package cachestore
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func Test_loadFreeCache(t *testing.T) {
 	t.Run("default values", func(t *testing.T) {
-		c := loadFreeCache(0, 0)
+		c := loadFreeCache(0, 0, realClock{})
 		require.NotNil(t, c)
 	})
 
 	t.Run("custom values", func(t *testing.T) {
-		c := loadFreeCache(DefaultCacheSize+1024, 15)
+		c := loadFreeCache(DefaultCacheSize+1024, 15, realClock{})
 		require.NotNil(t, c)
 	})
 }
+
+// Test_setFreeCache will test the method setFreeCache()
+func Test_setFreeCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("entry limit", func(t *testing.T) {
+		assert.Equal(t, 488, freeCacheEntryLimit(MinFreeCacheSize))
+		assert.Equal(t, 488, freeCacheEntryLimit(1024)) // Raised to the minimum
+		assert.Equal(t, 102376, freeCacheEntryLimit(DefaultCacheSize))
+	})
+
+	t.Run("largest value that fits", func(t *testing.T) {
+		c := freecache.NewCache(MinFreeCacheSize)
+		limit := freeCacheEntryLimit(MinFreeCacheSize)
+		require.NoError(t, setFreeCache(c, limit, testKey, []byte(strings.Repeat("x", limit-len(testKey))), 0))
+
+		err := setFreeCache(c, limit, testKey, []byte(strings.Repeat("x", limit-len(testKey)+1)), 0)
+		require.ErrorIs(t, err, ErrValueTooLarge)
+		require.ErrorIs(t, err, freecache.ErrLargeEntry)
+	})
+
+	t.Run("other errors are not wrapped", func(t *testing.T) {
+		c := freecache.NewCache(MinFreeCacheSize)
+		err := setFreeCache(c, 0, strings.Repeat("k", 65536), []byte(testValue), 0)
+		require.ErrorIs(t, err, freecache.ErrLargeKey)
+		require.NotErrorIs(t, err, ErrValueTooLarge)
+	})
+}
+
+// Test_secretMatches will test the method secretMatches()
+func Test_secretMatches(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, secretMatches([]byte(testValue), []byte(testValue)))
+	assert.False(t, secretMatches([]byte(testValue), []byte(testValue+"x")))
+	assert.False(t, secretMatches([]byte(testValue), []byte(testValue[:len(testValue)-1]+"x")))
+	assert.False(t, secretMatches([]byte(testValue), nil))
+	assert.False(t, secretMatches(nil, []byte(testValue)))
+}
+
+// Test_appendFreeCache will test the method appendFreeCache()
+func Test_appendFreeCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("value is too large", func(t *testing.T) {
+		c := freecache.NewCache(MinFreeCacheSize)
+		limit := freeCacheEntryLimit(MinFreeCacheSize)
+
+		length, err := appendFreeCache(c, limit, testKey, strings.Repeat("x", limit-len(testKey)), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, limit-len(testKey), length)
+
+		_, err = appendFreeCache(c, limit, testKey, "x", time.Now())
+		require.ErrorIs(t, err, ErrValueTooLarge)
+
+		// The existing value is kept
+		value, getErr := c.Get([]byte(testKey))
+		require.NoError(t, getErr)
+		assert.Len(t, value, limit-len(testKey))
+	})
+}
@@ -0,0 +1,25 @@
+package cachestore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// KeyHasher will transform a key into the key stored in the engine (see: WithKeyHashing)
+//
+// The hasher must be deterministic, the same key always returns the same stored key
+type KeyHasher func(key string) string
+
+// KeyHasherSHA256 will return the SHA-256 of the key (hex, always 64 characters)
+func KeyHasherSHA256(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// hashKey will transform the key using the key hasher (if enabled, see: WithKeyHashing)
+func (c *Client) hashKey(key string) string {
+	if c.options.keyHasher == nil {
+		return key
+	}
+	return c.options.keyHasher(key)
+}
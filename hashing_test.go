@@ -0,0 +1,134 @@
+package cachestore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeyHasherSHA256 will test the method KeyHasherSHA256()
+func TestKeyHasherSHA256(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deterministic and fixed length", func(t *testing.T) {
+		hashed := KeyHasherSHA256(testKey)
+		assert.Len(t, hashed, 64)
+		assert.Equal(t, hashed, KeyHasherSHA256(testKey))
+		assert.Len(t, KeyHasherSHA256(strings.Repeat(testKey, 1000)), 64)
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", KeyHasherSHA256(""))
+	})
+
+	t.Run("different keys do not collide", func(t *testing.T) {
+		hashes := make(map[string]string)
+		for i := 0; i < 10000; i++ {
+			key := testKey + ":" + strconv.Itoa(i)
+			hashed := KeyHasherSHA256(key)
+			_, collision := hashes[hashed]
+			require.False(t, collision, key)
+			hashes[hashed] = key
+		}
+		assert.NotEqual(t, KeyHasherSHA256(testKey+"a"), KeyHasherSHA256(testKey+"b"))
+	})
+}
+
+// TestClient_KeyHashing will test the keys stored using a key hasher (WithKeyHashing)
+func TestClient_KeyHashing(t *testing.T) {
+
+	longKey := "user:123:" + strings.Repeat("composite:", 50) + "end"
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - the long key and its hash resolve to the same entry", func(t *testing.T) {
+			shared := sharedClientOpts(testCase)
+			c, err := NewClient(context.Background(), shared, WithKeyHashing(KeyHasherSHA256), WithKeyPrefix("app:"))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			var raw ClientInterface
+			raw, err = NewClient(context.Background(), shared, WithKeyPrefix("app:"))
+			require.NoError(t, err)
+			defer raw.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), " "+longKey+" ", testValue))
+
+			// Stored under the hash (after the key prefix)
+			var value string
+			value, err = raw.Get(context.Background(), KeyHasherSHA256(longKey))
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			var found bool
+			found, err = raw.Exists(context.Background(), longKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+			if testCase.engine == Redis {
+				assert.True(t, testCase.redis.Exists("app:"+KeyHasherSHA256(longKey)))
+			}
+
+			value, err = c.Get(context.Background(), longKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			// The results use the requested keys
+			var values map[string]string
+			values, err = c.GetMulti(context.Background(), longKey, testKey+"-missing")
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{longKey: testValue}, values)
+
+			require.NoError(t, c.Delete(context.Background(), longKey))
+			found, err = raw.Exists(context.Background(), KeyHasherSHA256(longKey))
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - locks", func(t *testing.T) {
+			shared := sharedClientOpts(testCase)
+			c, err := NewClient(context.Background(), shared, WithKeyHashing(KeyHasherSHA256))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			var raw ClientInterface
+			raw, err = NewClient(context.Background(), shared)
+			require.NoError(t, err)
+			defer raw.Close(context.Background())
+
+			var secret string
+			secret, err = c.WaitWriteLock(context.Background(), longKey, 30, 1)
+			require.NoError(t, err)
+
+			var acquired bool
+			_, acquired, err = raw.TryWriteLock(context.Background(), KeyHasherSHA256(longKey), 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), longKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+		})
+
+		t.Run(testCase.name+" - the max key length is checked on the hashed key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyHashing(KeyHasherSHA256), WithMaxKeyLength(64))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), longKey, testValue))
+			_, err = c.WaitWriteLock(context.Background(), longKey, 30, 1)
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("a hasher returning an empty key", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithKeyHashing(func(string) string { return "" }))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.ErrorIs(t, c.Set(context.Background(), testKey, testValue), ErrKeyRequired)
+	})
+}
@@ -0,0 +1,81 @@
+package cachestore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// healthChecker pings the engine in the background and keeps the result of the last probe (see: WithHealthCheck)
+type healthChecker struct {
+	done     chan struct{}                   // Closed to stop the goroutine
+	healthy  atomic.Bool                     // Result of the last probe
+	interval time.Duration                   // Time between the probes
+	ping     func(ctx context.Context) error // Probe of the engine (Ping)
+	stopOnce sync.Once                       // Stop only once
+	stopped  chan struct{}                   // Closed when the goroutine exits
+}
+
+// newHealthChecker will create a health checker that is healthy until the first probe (start it using run)
+func newHealthChecker(interval time.Duration, ping func(ctx context.Context) error) *healthChecker {
+	h := &healthChecker{
+		done:     make(chan struct{}),
+		interval: interval,
+		ping:     ping,
+		stopped:  make(chan struct{}),
+	}
+	h.healthy.Store(true)
+	return h
+}
+
+// startHealthChecker will create a health checker and start the goroutine
+func startHealthChecker(interval time.Duration, ping func(ctx context.Context) error) *healthChecker {
+	h := newHealthChecker(interval, ping)
+	go h.run()
+	return h
+}
+
+// run will probe the engine every interval until the health checker is stopped
+func (h *healthChecker) run() {
+	defer close(h.stopped)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.done:
+			return
+		case <-ticker.C:
+			h.probe()
+		}
+	}
+}
+
+// probe will ping the engine and record the result (a probe is limited to the interval)
+func (h *healthChecker) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), h.interval)
+	defer cancel()
+	h.healthy.Store(h.ping(ctx) == nil)
+}
+
+// stop will stop the goroutine and wait for it to exit (safe to call more than once)
+func (h *healthChecker) stop() {
+	h.stopOnce.Do(func() {
+		close(h.done)
+	})
+	<-h.stopped
+}
+
+// IsHealthy will return if the last probe of the engine succeeded (see: WithHealthCheck)
+//
+// Without WithHealthCheck the client is healthy until it is closed
+// Operations are still attempted when the client is not healthy (this is not a circuit breaker)
+func (c *Client) IsHealthy() bool {
+	if c.Engine() == Empty {
+		return false
+	} else if c.options.healthCheck == nil {
+		return true
+	}
+	return c.options.healthCheck.healthy.Load()
+}
@@ -0,0 +1,101 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errProbeFailed is the error returned by the failing probes
+var errProbeFailed = errors.New("probe failed")
+
+// Test_healthChecker_probe will test the method probe()
+func Test_healthChecker_probe(t *testing.T) {
+	t.Parallel()
+
+	var failing atomic.Bool
+	h := newHealthChecker(time.Minute, func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return context.DeadlineExceeded
+		} else if failing.Load() {
+			return errProbeFailed
+		}
+		return nil
+	})
+
+	// Healthy until the first probe
+	assert.True(t, h.healthy.Load())
+
+	failing.Store(true)
+	h.probe()
+	assert.False(t, h.healthy.Load())
+
+	failing.Store(false)
+	h.probe()
+	assert.True(t, h.healthy.Load())
+}
+
+// TestClient_HealthCheck will test the option WithHealthCheck() and the method IsHealthy()
+func TestClient_HealthCheck(t *testing.T) {
+
+	t.Run("healthy without a health check", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		assert.True(t, c.IsHealthy())
+
+		c.Close(context.Background())
+		assert.False(t, c.IsHealthy())
+	})
+
+	t.Run("stopping redis flips the health", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(),
+			WithRedis(&RedisConfig{URL: r.Addr()}),
+			WithHealthCheck(10*time.Millisecond),
+		)
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+		assert.True(t, c.IsHealthy())
+
+		r.Close()
+		require.Eventually(t, func() bool { return !c.IsHealthy() }, 5*time.Second, 5*time.Millisecond)
+
+		// Operations are still attempted (and fail)
+		_, err = c.Get(context.Background(), testKey)
+		require.Error(t, err)
+
+		require.NoError(t, r.Restart())
+		require.Eventually(t, c.IsHealthy, 5*time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("close stops the goroutine", func(t *testing.T) {
+		before := runtime.NumGoroutine()
+
+		clients := make([]ClientInterface, 0, 5)
+		for i := 0; i < 5; i++ {
+			c, err := NewClient(context.Background(), WithFreeCache(), WithHealthCheck(time.Millisecond))
+			require.NoError(t, err)
+			clients = append(clients, c)
+		}
+		assert.GreaterOrEqual(t, runtime.NumGoroutine(), before+len(clients))
+
+		for _, c := range clients {
+			c.Close(context.Background())
+			c.Close(context.Background()) // Safe to close more than once
+			assert.False(t, c.IsHealthy())
+		}
+
+		// Wait for the goroutines to exit (Eventually runs the condition in another goroutine)
+		deadline := time.Now().Add(time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		assert.LessOrEqual(t, runtime.NumGoroutine(), before)
+	})
+}
@@ -0,0 +1,53 @@
+package cachestore
+
+import (
+	"errors"
+)
+
+// Hooks are optional callbacks for the cache operations (nil functions are skipped)
+//
+// Hooks run synchronously on the calling goroutine before the operation returns,
+// offload any heavy work (goroutine or queue) to avoid slowing down the cache
+// The operation is the name used in the metrics (get, set, delete, write_lock...)
+type Hooks struct {
+	OnError func(operation, key string, err error) // Operation failed (the key is empty for GetMulti, GetModelMulti, SetMulti, SetModelMulti, DeleteMany and DeleteDependency)
+	OnHit   func(key string)                       // Key was found (Get, GetBytes, GetModel, GetModelMulti, GetModelWithTTL, GetMulti, GetOrSet and GetOrSetModel)
+	OnMiss  func(key string)                       // Key was not found (Get, GetBytes, GetModel, GetModelMulti, GetModelWithTTL, GetMulti, GetOrSet and GetOrSetModel)
+	OnSet   func(key string)                       // Key was stored (Set, SetBytes, SetTTL, SetModel, SetModelIfChanged, SetModelMulti, SetMulti and ReplaceModel)
+}
+
+// onRead will run the OnHit, OnMiss or OnError hook for a read
+func (c *Client) onRead(operation, key string, found bool, err error) {
+	if err != nil {
+		c.onError(operation, key, err)
+	} else if found && c.options.hooks.OnHit != nil {
+		c.options.hooks.OnHit(key)
+	} else if !found && c.options.hooks.OnMiss != nil {
+		c.options.hooks.OnMiss(key)
+	}
+}
+
+// onReadModel will run the hooks for reading a model (ErrKeyNotFound is a miss)
+func (c *Client) onReadModel(operation, key string, err error) {
+	if errors.Is(err, ErrKeyNotFound) {
+		c.onRead(operation, key, false, nil)
+		return
+	}
+	c.onRead(operation, key, true, err)
+}
+
+// onSet will run the OnSet or OnError hook for a write
+func (c *Client) onSet(operation, key string, err error) {
+	if err != nil {
+		c.onError(operation, key, err)
+	} else if c.options.hooks.OnSet != nil {
+		c.options.hooks.OnSet(key)
+	}
+}
+
+// onError will run the OnError hook (if the operation failed)
+func (c *Client) onError(operation, key string, err error) {
+	if err != nil && c.options.hooks.OnError != nil {
+		c.options.hooks.OnError(operation, key, err)
+	}
+}
@@ -0,0 +1,169 @@
+package cachestore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hookRecorder will record the keys passed to the hooks
+type hookRecorder struct {
+	errors []string
+	hits   []string
+	misses []string
+	sets   []string
+	sync.Mutex
+}
+
+// hooks will return the hooks that record the keys (errors are recorded as operation:key)
+func (r *hookRecorder) hooks() Hooks {
+	return Hooks{
+		OnError: func(operation, key string, _ error) {
+			r.Lock()
+			defer r.Unlock()
+			r.errors = append(r.errors, operation+":"+key)
+		},
+		OnHit: func(key string) {
+			r.Lock()
+			defer r.Unlock()
+			r.hits = append(r.hits, key)
+		},
+		OnMiss: func(key string) {
+			r.Lock()
+			defer r.Unlock()
+			r.misses = append(r.misses, key)
+		},
+		OnSet: func(key string) {
+			r.Lock()
+			defer r.Unlock()
+			r.sets = append(r.sets, key)
+		},
+	}
+}
+
+// TestClient_Hooks will test the hooks for the cache operations
+func TestClient_Hooks(t *testing.T) {
+
+	t.Run("nil hooks are skipped", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithHooks(Hooks{}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		_, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), "")
+		require.ErrorIs(t, err, ErrKeyRequired)
+	})
+
+	t.Run("only some hooks are set", func(t *testing.T) {
+		var misses []string
+		c, err := NewClient(context.Background(), WithFreeCache(), WithHooks(Hooks{
+			OnMiss: func(key string) {
+				misses = append(misses, key)
+			},
+		}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		_, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+		assert.Equal(t, []string{testKey + "-missing"}, misses)
+	})
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - hit and miss", func(t *testing.T) {
+			recorder := &hookRecorder{}
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithHooks(recorder.hooks()))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0))
+
+			_, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			_, err = c.Get(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			require.NoError(t, c.GetModel(context.Background(), testKey+"-model", &genericStruct{}))
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey+"-model-missing", &genericStruct{}), ErrKeyNotFound)
+
+			recorder.Lock()
+			defer recorder.Unlock()
+			assert.Equal(t, []string{testKey, testKey + "-model"}, recorder.hits)
+			assert.Equal(t, []string{testKey + "-missing", testKey + "-model-missing"}, recorder.misses)
+			assert.Equal(t, []string{testKey, testKey + "-model"}, recorder.sets)
+			assert.Empty(t, recorder.errors)
+		})
+
+		t.Run(testCase.name+" - multiple keys", func(t *testing.T) {
+			recorder := &hookRecorder{}
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithHooks(recorder.hooks()))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.SetMulti(context.Background(), map[string]string{
+				testKey + "-1": testValue,
+				testKey + "-2": testValue,
+			}))
+			_, err = c.GetMulti(context.Background(), testKey+"-1", testKey+"-2", testKey+"-3")
+			require.NoError(t, err)
+
+			recorder.Lock()
+			defer recorder.Unlock()
+			sort.Strings(recorder.sets)
+			assert.Equal(t, []string{testKey + "-1", testKey + "-2"}, recorder.sets)
+			assert.Equal(t, []string{testKey + "-1", testKey + "-2"}, recorder.hits)
+			assert.Equal(t, []string{testKey + "-3"}, recorder.misses)
+		})
+
+		t.Run(testCase.name+" - errors", func(t *testing.T) {
+			recorder := &hookRecorder{}
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithHooks(recorder.hooks()))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err = c.Get(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			require.ErrorIs(t, c.Set(ctx, testKey, testValue), context.Canceled)
+			require.ErrorIs(t, c.Delete(ctx, testKey), context.Canceled)
+			_, err = c.GetMulti(ctx, testKey)
+			require.ErrorIs(t, err, context.Canceled)
+
+			// The lock already exists (different secret)
+			_, err = c.WriteLockWithSecret(context.Background(), testKey+"-lock", testValue, 30)
+			require.NoError(t, err)
+			_, err = c.WriteLockWithSecret(context.Background(), testKey+"-lock", testValue+"-other", 30)
+			require.Error(t, err)
+
+			recorder.Lock()
+			defer recorder.Unlock()
+			assert.Equal(t, []string{
+				operationGet + ":",
+				operationSet + ":" + testKey,
+				operationDelete + ":" + testKey,
+				operationGetMulti + ":",
+				operationWriteLock + ":" + testKey + "-lock",
+			}, recorder.errors)
+			assert.Empty(t, recorder.hits)
+			assert.Empty(t, recorder.misses)
+			assert.Empty(t, recorder.sets)
+		})
+	}
+}
@@ -2,41 +2,92 @@ package cachestore
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
+	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 )
 
 // LockService are the locking related methods
 type LockService interface {
+	AcquireLock(ctx context.Context, lockKey string, ttl int64) (Lock, error)
+	ExtendLock(ctx context.Context, lockKey, secret string, ttl int64) (bool, error)
+	ForceReleaseLock(ctx context.Context, lockKey string) (bool, error)
+	ListLocks(ctx context.Context) ([]LockInfo, error)
 	ReleaseLock(ctx context.Context, lockKey, secret string) (bool, error)
+	TryWriteLock(ctx context.Context, lockKey string, ttl int64) (string, bool, error)
 	WaitWriteLock(ctx context.Context, lockKey string, ttl, ttw int64) (string, error)
 	WriteLock(ctx context.Context, lockKey string, ttl int64) (string, error)
+	WriteLockMany(ctx context.Context, lockKeys []string, ttl int64) (map[string]string, error)
 	WriteLockWithSecret(ctx context.Context, lockKey, secret string, ttl int64) (string, error)
 }
 
 // CacheService are the cache related methods
 type CacheService interface {
+	Append(ctx context.Context, key, value string) (int, error)
+	CompareAndSwap(ctx context.Context, key, expected, newValue string, ttl time.Duration) (bool, error)
+	Decrement(ctx context.Context, key string, delta int64) (int64, error)
 	Delete(ctx context.Context, key string) error
+	DeleteByPattern(ctx context.Context, pattern string) (int, error)
+	DeleteDependency(ctx context.Context, dependencies ...string) error
+	DeleteMany(ctx context.Context, keys ...string) error
+	DeleteModel(ctx context.Context, key string) error
+	Exists(ctx context.Context, key string) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
+	GetAndRefresh(ctx context.Context, key string, ttl time.Duration) (string, error)
+	GetBytes(ctx context.Context, key string) ([]byte, error)
+	GetDuration(ctx context.Context, key string) (time.Duration, error)
+	GetInt(ctx context.Context, key string) (int64, error)
 	GetModel(ctx context.Context, key string, model interface{}) error
+	GetModelMulti(ctx context.Context, keys []string, out interface{}) ([]string, error)
+	GetModelWithTTL(ctx context.Context, key string, model interface{}) (time.Duration, error)
+	GetMulti(ctx context.Context, keys ...string) (map[string]string, error)
+	GetOrSet(ctx context.Context, key string, ttl time.Duration, loader func(ctx context.Context) (string, error), dependencies ...string) (string, error)
+	GetOrSetModel(ctx context.Context, key string, model interface{}, ttl time.Duration, loader func(ctx context.Context) (interface{}, error), dependencies ...string) error
+	GetRaw(ctx context.Context, key string) (json.RawMessage, error)
+	GetSet(ctx context.Context, key, value string) (string, bool, error)
+	GetTime(ctx context.Context, key string) (time.Time, error)
+	Increment(ctx context.Context, key string, delta int64) (int64, error)
+	Pipeline(ctx context.Context, fn func(p Pipeliner) error) error
+	Scan(ctx context.Context, pattern string, fn func(key string) error) error
 	Set(ctx context.Context, key string, value interface{}, dependencies ...string) error
+	ReplaceModel(ctx context.Context, key string, model interface{}, ttl time.Duration, dependencies ...string) (bool, error)
+	SetBytes(ctx context.Context, key string, value []byte, dependencies ...string) error
+	SetDuration(ctx context.Context, key string, d, ttl time.Duration) error
+	SetInt(ctx context.Context, key string, n int64, ttl time.Duration) error
 	SetTTL(ctx context.Context, key string, value interface{}, ttl time.Duration, dependencies ...string) error
 	SetModel(ctx context.Context, key string, model interface{}, ttl time.Duration, dependencies ...string) error
+	SetModelIfChanged(ctx context.Context, key string, model interface{}, ttl time.Duration, dependencies ...string) (bool, error)
+	SetModelMulti(ctx context.Context, items map[string]interface{}, ttl time.Duration, dependencies ...string) error
+	SetMulti(ctx context.Context, items map[string]string, dependencies ...string) error
+	SetTime(ctx context.Context, key string, t time.Time, ttl time.Duration) error
+	Touch(ctx context.Context, key string, ttl time.Duration) error
 }
 
 // ClientInterface is the cachestore interface
 type ClientInterface interface {
 	CacheService
 	LockService
+	Clear(ctx context.Context) error
 	Close(ctx context.Context)
 	Debug(on bool)
 	EmptyCache(ctx context.Context) error
+	EmptyCachePattern(ctx context.Context, pattern string) (int, error)
 	Engine() Engine
+	Flush(ctx context.Context) error
 	FreeCache() *freecache.Cache
+	FreeCacheStats() (*FreeCacheStats, error)
 	IsDebug() bool
+	IsHealthy() bool
 	IsNewRelicEnabled() bool
+	Memcached() *memcache.Client
+	MemcachedConfig() *MemcachedConfig
+	Ping(ctx context.Context) error
+	PoolStats() (*PoolStats, error)
 	Redis() *cache.Client
 	RedisConfig() *RedisConfig
+	ResetStats()
+	Stats() Stats
 }
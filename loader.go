@@ -0,0 +1,190 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// loaderLockPrefix is the prefix of the lock keys used to serialize the loaders (see: WithLoaderLock)
+const loaderLockPrefix = "loader-lock:"
+
+// ModelLoader loads the model of a missing key and the TTL to store it (see: WithModelLoader)
+//
+// The key is without the key prefix, a nil model is a miss (ErrKeyNotFound) and nothing is stored
+type ModelLoader func(ctx context.Context, key string) (model interface{}, ttl time.Duration, err error)
+
+// modelLoader is a model loader registered for the keys with the prefix
+type modelLoader struct {
+	load   ModelLoader
+	prefix string
+}
+
+// GetOrSet will return the value of the key, or call the loader on a miss and store the result with the TTL (read-through)
+//
+// A loader error is returned and nothing is stored
+// Concurrent loaders for the same key can be serialized using WithLoaderLock() (thundering herd)
+// NOTE: memcached does not support dependency keys
+func (c *Client) GetOrSet(ctx context.Context, key string, ttl time.Duration,
+	loader func(ctx context.Context) (string, error), dependencies ...string,
+) (string, error) {
+
+	// Require the loader
+	if loader == nil {
+		return "", ErrLoaderRequired
+	}
+
+	// Return the cached value (hit)
+	value, found, err := c.get(ctx, operationGetOrSet, key)
+	if err != nil || found {
+		return value, err
+	}
+
+	// Serialize the loaders for the key (if enabled)
+	var release func()
+	if release, err = c.loaderLock(ctx, key); err != nil {
+		return "", err
+	} else if release != nil {
+		defer release()
+
+		// Another loader may have stored the value while waiting for the lock
+		if value, found, err = c.get(ctx, operationGetOrSet, key); err != nil || found {
+			return value, err
+		}
+	}
+
+	// Load the value and store it
+	if value, err = loader(ctx); err != nil {
+		return "", err
+	}
+	if err = c.SetTTL(ctx, key, value, ttl, dependencies...); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// GetOrSetModel will get the model of the key, or call the loader on a miss and store the result with the TTL (read-through)
+//
+// Model needs to be a pointer to a struct, the loaded value is stored using SetModel and decoded into the model
+// A value that cannot be decoded (ErrModelUnmarshal) and a loader error are returned, nothing is stored
+// A stored model of another version (see: WithModelVersioning) is a miss, the loader is called
+// Concurrent misses for the same key on this node call the loader once (and share the result),
+// the loader runs with the context of the first caller (see WithLoaderLock() to serialize across nodes)
+// NOTE: memcached does not support dependency keys
+func (c *Client) GetOrSetModel(ctx context.Context, key string, model interface{}, ttl time.Duration,
+	loader func(ctx context.Context) (interface{}, error), dependencies ...string,
+) error {
+
+	// Require the loader
+	if loader == nil {
+		return ErrLoaderRequired
+	}
+
+	// Return the cached model (hit), only a miss calls the loader
+	err := c.getModel(ctx, operationGetOrSetModel, key, model)
+	if !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+
+	// Load and store the model once on this node (concurrent misses wait for the result)
+	return c.shareLoadModel(ctx, key, model, func(ctx context.Context) (interface{}, time.Duration, error) {
+		loaded, loadErr := loader(ctx)
+		return loaded, ttl, loadErr
+	}, dependencies)
+}
+
+// readThroughModel will load the model of a missing key using the model loader of the key prefix (if any)
+//
+// Returns the miss (ie: ErrKeyNotFound) if no model loader is registered for the key (see: WithModelLoader)
+func (c *Client) readThroughModel(ctx context.Context, key string, model interface{}, miss error) error {
+	key = c.trimKey(key)
+	load := c.modelLoader(key)
+	if load == nil {
+		return miss
+	}
+	return c.shareLoadModel(ctx, key, model, func(ctx context.Context) (interface{}, time.Duration, error) {
+		loaded, ttl, err := load(ctx, key)
+		if err == nil && loaded == nil {
+			return nil, 0, ErrKeyNotFound
+		}
+		return loaded, ttl, err
+	}, nil)
+}
+
+// modelLoader will return the model loader of the longest prefix matching the key (nil if none)
+func (c *Client) modelLoader(key string) ModelLoader {
+	var found *modelLoader
+	for i := range c.options.modelLoaders {
+		current := &c.options.modelLoaders[i]
+		if strings.HasPrefix(key, current.prefix) && (found == nil || len(current.prefix) > len(found.prefix)) {
+			found = current
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	return found.load
+}
+
+// shareLoadModel will load and store the model once on this node and decode it into the model
+//
+// Concurrent misses for the same key wait for the result of the first loader
+func (c *Client) shareLoadModel(ctx context.Context, key string, model interface{},
+	loader func(ctx context.Context) (interface{}, time.Duration, error), dependencies []string,
+) error {
+	data, err, _ := c.options.loaders.Do(c.options.keyPrefix+c.trimKey(key), func() (interface{}, error) {
+		return c.loadModel(ctx, key, loader, dependencies)
+	})
+	if err != nil {
+		return err
+	}
+	return c.unmarshalModel(ctx, data.([]byte), model)
+}
+
+// loadModel will call the loader, store the model (with the TTL of the loader) and return the serialized model
+func (c *Client) loadModel(ctx context.Context, key string,
+	loader func(ctx context.Context) (interface{}, time.Duration, error), dependencies []string,
+) ([]byte, error) {
+
+	// Serialize the loaders for the key (if enabled)
+	release, err := c.loaderLock(ctx, key)
+	if err != nil {
+		return nil, err
+	} else if release != nil {
+		defer release()
+
+		// Another node may have stored the model while waiting for the lock
+		var data []byte
+		if data, err = c.getModelBytes(ctx, key); !errors.Is(err, ErrKeyNotFound) {
+			return data, err
+		}
+	}
+
+	// Load the model and store it
+	var loaded interface{}
+	var ttl time.Duration
+	if loaded, ttl, err = loader(ctx); err != nil {
+		return nil, err
+	}
+	if err = c.SetModel(ctx, key, loaded, ttl, dependencies...); err != nil {
+		return nil, err
+	}
+	return c.marshalModel(ctx, loaded)
+}
+
+// loaderLock will wait for the loader lock of the key and return the function to release it
+//
+// The key is validated by the read (the internal lock key is not validated, listed, measured or hooked)
+// Returns a nil function if the loader lock is disabled or not supported by the engine (memcached)
+func (c *Client) loaderLock(ctx context.Context, key string) (func(), error) {
+	if c.options.loaderLockTTL <= 0 || c.Engine() == Memcached {
+		return nil, nil
+	}
+
+	// Wait up to the lock TTL (the max time of a loader)
+	ttl := int64(c.options.loaderLockTTL.Seconds())
+	return c.waitInternalLock(
+		ctx, c.options.keyPrefix+c.options.lockNamespace+loaderLockPrefix+c.hashKey(c.trimKey(key)), ttl, ttl,
+	)
+}
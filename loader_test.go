@@ -0,0 +1,425 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errLoaderFailed is the error returned by the failing loaders
+var errLoaderFailed = errors.New("loader failed")
+
+// TestClient_GetOrSet will test the method GetOrSet()
+func TestClient_GetOrSet(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing loader", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.GetOrSet(context.Background(), testKey, time.Minute, nil)
+			require.ErrorIs(t, err, ErrLoaderRequired)
+		})
+
+		t.Run(testCase.name+" - empty key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.GetOrSet(context.Background(), "", time.Minute, func(context.Context) (string, error) {
+				return testValue, nil
+			})
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - miss calls the loader, hit uses the cache", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var calls int
+			loader := func(context.Context) (string, error) {
+				calls++
+				return testValue, nil
+			}
+
+			var value string
+			value, err = c.GetOrSet(context.Background(), testKey, time.Minute, loader)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			value, err = c.GetOrSet(context.Background(), testKey, time.Minute, loader)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+			assert.Equal(t, 1, calls)
+
+			// The value was stored
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			stats := c.Stats()
+			assert.Equal(t, int64(1), stats.Sets)
+			assert.Equal(t, int64(2), stats.Hits)
+			assert.Equal(t, int64(1), stats.Misses)
+		})
+
+		t.Run(testCase.name+" - loader error is not cached", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			_, err = c.GetOrSet(context.Background(), testKey+"-error", time.Minute, func(context.Context) (string, error) {
+				return "", errLoaderFailed
+			})
+			require.ErrorIs(t, err, errLoaderFailed)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey+"-error")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - concurrent loaders are serialized", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithLoaderLock(5*time.Second))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var calls atomic.Int32
+			loader := func(context.Context) (string, error) {
+				calls.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				return testValue, nil
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					value, getErr := c.GetOrSet(context.Background(), testKey+"-herd", time.Minute, loader)
+					assert.NoError(t, getErr)
+					assert.Equal(t, testValue, value)
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, int32(1), calls.Load())
+		})
+
+		t.Run(testCase.name+" - the loader lock with the max key length", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), sharedClientOpts(testCase), WithLoaderLock(5*time.Second), WithMaxKeyLength(10),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// The internal lock key is longer than the max
+			var value string
+			value, err = c.GetOrSet(context.Background(), "abcdefghij", time.Minute, func(context.Context) (string, error) {
+				return testValue, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			model := new(genericStruct)
+			require.NoError(t, c.GetOrSetModel(context.Background(), "abcdefghi2", model, time.Minute,
+				func(context.Context) (interface{}, error) {
+					return &genericStruct{StringField: testValue}, nil
+				},
+			))
+			assert.Equal(t, testValue, model.StringField)
+		})
+
+		t.Run(testCase.name+" - the loader lock with a key validator", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), sharedClientOpts(testCase), WithLoaderLock(5*time.Second),
+				WithKeyValidator(func(key string) error {
+					if !strings.HasPrefix(key, "app:") {
+						return errors.New("key must start with app:")
+					}
+					return nil
+				}),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var value string
+			value, err = c.GetOrSet(context.Background(), "app:x", time.Minute, func(context.Context) (string, error) {
+				return testValue, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+		})
+
+		t.Run(testCase.name+" - the loader lock is internal", func(t *testing.T) {
+			c, err := NewClient(
+				context.Background(), sharedClientOpts(testCase), WithLoaderLock(5*time.Second), WithObservabilityContext(),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			ctx := ContextWithCacheEvents(context.Background())
+			_, err = c.GetOrSet(ctx, testKey+"-internal", time.Minute, func(context.Context) (string, error) {
+				// Not listed while loading
+				locks, listErr := c.ListLocks(context.Background())
+				assert.NoError(t, listErr)
+				assert.Empty(t, locks)
+				return testValue, nil
+			})
+			require.NoError(t, err)
+
+			// Not recorded (metrics and hooks)
+			events := CacheEventsFromContext(ctx)
+			require.NotEmpty(t, events)
+			for _, event := range events {
+				assert.NotContains(t, event.Operation, "lock")
+			}
+		})
+	}
+}
+
+// TestClient_GetOrSetModel will test the method GetOrSetModel()
+func TestClient_GetOrSetModel(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing loader", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.GetOrSetModel(context.Background(), testKey, &genericStruct{}, time.Minute, nil)
+			require.ErrorIs(t, err, ErrLoaderRequired)
+		})
+
+		t.Run(testCase.name+" - miss calls the loader, hit uses the cache", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var calls int
+			loader := func(context.Context) (interface{}, error) {
+				calls++
+				return &genericStruct{IntField: 123, StringField: testValue}, nil
+			}
+
+			model := new(genericStruct)
+			require.NoError(t, c.GetOrSetModel(context.Background(), testKey, model, time.Minute, loader))
+			assert.Equal(t, &genericStruct{IntField: 123, StringField: testValue}, model)
+
+			model = new(genericStruct)
+			require.NoError(t, c.GetOrSetModel(context.Background(), testKey, model, time.Minute, loader))
+			assert.Equal(t, &genericStruct{IntField: 123, StringField: testValue}, model)
+			assert.Equal(t, 1, calls)
+
+			// The model was stored
+			model = new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, testValue, model.StringField)
+		})
+
+		t.Run(testCase.name+" - value cannot be decoded", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey+"-corrupt", `{"int_field":"not-a-number"}`))
+			err = c.GetOrSetModel(context.Background(), testKey+"-corrupt", &genericStruct{}, time.Minute,
+				func(context.Context) (interface{}, error) {
+					t.Error("the loader should not be called")
+					return nil, errLoaderFailed
+				},
+			)
+			require.ErrorIs(t, err, ErrModelUnmarshal)
+		})
+
+		t.Run(testCase.name+" - loader error is not cached", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.GetOrSetModel(context.Background(), testKey+"-error", &genericStruct{}, time.Minute,
+				func(context.Context) (interface{}, error) {
+					return nil, errLoaderFailed
+				},
+			)
+			require.ErrorIs(t, err, errLoaderFailed)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey+"-error")
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - concurrent misses call the loader once", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var calls atomic.Int32
+			loader := func(context.Context) (interface{}, error) {
+				calls.Add(1)
+				time.Sleep(50 * time.Millisecond)
+				return &genericStruct{StringField: testValue}, nil
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					model := new(genericStruct)
+					assert.NoError(t, c.GetOrSetModel(context.Background(), testKey+"-herd", model, time.Minute, loader))
+					assert.Equal(t, testValue, model.StringField)
+				}()
+			}
+			wg.Wait()
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	}
+}
+
+// TestClient_ModelLoader will test GetModel using the model loaders (see: WithModelLoader)
+func TestClient_ModelLoader(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - miss calls the loader once, then the model is cached", func(t *testing.T) {
+			var calls atomic.Int32
+			var loadedKey string
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithKeyPrefix("app:"),
+				WithModelLoader("config:", func(_ context.Context, key string) (interface{}, time.Duration, error) {
+					calls.Add(1)
+					loadedKey = key
+					return &genericStruct{IntField: 123, StringField: testValue}, time.Minute, nil
+				}),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), " config:"+testKey, model))
+			assert.Equal(t, &genericStruct{IntField: 123, StringField: testValue}, model)
+			assert.Equal(t, "config:"+testKey, loadedKey)
+
+			model = new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), "config:"+testKey, model))
+			assert.Equal(t, testValue, model.StringField)
+			assert.Equal(t, int32(1), calls.Load())
+
+			// Stored with the TTL of the loader
+			assert.Equal(t, int64(1), c.Stats().Sets)
+			testCase.FastForward(2 * time.Minute)
+			if testCase.engine == Redis {
+				assert.False(t, testCase.redis.Exists("app:config:"+testKey))
+			}
+
+			// Other keys are not loaded
+			err = c.GetModel(context.Background(), "user:"+testKey, new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Equal(t, int32(1), calls.Load())
+		})
+
+		t.Run(testCase.name+" - the longest prefix is used", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithModelLoader("", func(context.Context, string) (interface{}, time.Duration, error) {
+					return &genericStruct{StringField: "any"}, 0, nil
+				}),
+				WithModelLoader("config:", func(context.Context, string) (interface{}, time.Duration, error) {
+					return &genericStruct{StringField: "config"}, 0, nil
+				}),
+				WithModelLoader("config:feature:", func(context.Context, string) (interface{}, time.Duration, error) {
+					return &genericStruct{StringField: "feature"}, 0, nil
+				}),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			for key, expected := range map[string]string{
+				"user:1":           "any",
+				"config:1":         "config",
+				"config:feature:1": "feature",
+			} {
+				model := new(genericStruct)
+				require.NoError(t, c.GetModel(context.Background(), key, model))
+				assert.Equal(t, expected, model.StringField, key)
+			}
+		})
+
+		t.Run(testCase.name+" - loader error is returned and not cached", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithModelLoader("config:", func(context.Context, string) (interface{}, time.Duration, error) {
+					return nil, 0, errLoaderFailed
+				}),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.GetModel(context.Background(), "config:"+testKey, new(genericStruct))
+			require.ErrorIs(t, err, errLoaderFailed)
+
+			var found bool
+			found, err = c.Exists(context.Background(), "config:"+testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+
+		t.Run(testCase.name+" - a nil model is a miss", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase),
+				WithModelLoader("config:", func(context.Context, string) (interface{}, time.Duration, error) {
+					return nil, 0, nil
+				}),
+			)
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			err = c.GetModel(context.Background(), "config:"+testKey, new(genericStruct))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+
+			var found bool
+			found, err = c.Exists(context.Background(), "config:"+testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
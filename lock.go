@@ -2,34 +2,137 @@ package cachestore
 
 import (
 	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/coocood/freecache"
 	"github.com/mrz1836/go-cache"
 	"github.com/pkg/errors"
 )
 
+const (
+
+	// DefaultLockNamespace is the prefix of the lock keys, a lock never collides with a data key (see: WithLockNamespace)
+	DefaultLockNamespace = "lock:"
+
+	// DefaultLockSecretBytes is the number of random bytes of a generated lock secret (64 hex characters)
+	DefaultLockSecretBytes = 32
+
+	// MinLockSecretBytes is the smallest number of random bytes of a generated lock secret (see: WithLockSecretBytes)
+	MinLockSecretBytes = 8
+)
+
+// Lock is a lock held by the client (see: AcquireLock), the key and secret are kept by the lock
+type Lock interface {
+	Extend(ctx context.Context, ttl int64) error // Update the TTL (seconds), see: ExtendLock
+	Key() string                                 // Lock key (without the key prefix)
+	Release(ctx context.Context) error           // Release the lock, safe to call more than once, see: ReleaseLock
+	Secret() string                              // Secret stored in the lock
+}
+
+// heldLock is a Lock using the client methods
+type heldLock struct {
+	client   *Client
+	key      string
+	released bool
+	secret   string
+	sync.Mutex
+}
+
+// Extend will update the TTL (seconds) of the lock (cache.ErrLockMismatch if the lock expired or was released)
+func (l *heldLock) Extend(ctx context.Context, ttl int64) error {
+	_, err := l.client.ExtendLock(ctx, l.key, l.secret, ttl)
+	return err
+}
+
+// Key will return the lock key
+func (l *heldLock) Key() string {
+	return l.key
+}
+
+// Release will release the lock, once released the next calls do nothing
+//
+// A lock that expired and was acquired by someone else returns cache.ErrLockMismatch (and is not held anymore)
+func (l *heldLock) Release(ctx context.Context) error {
+	l.Lock()
+	defer l.Unlock()
+	if l.released {
+		return nil
+	}
+	_, err := l.client.ReleaseLock(ctx, l.key, l.secret)
+	if err == nil || errors.Is(err, cache.ErrLockMismatch) {
+		l.released = true
+	}
+	return err
+}
+
+// Secret will return the secret stored in the lock
+func (l *heldLock) Secret() string {
+	return l.secret
+}
+
+// LockInfo is a lock that is held (see: ListLocks)
+type LockInfo struct {
+	Key string        `json:"key"` // Lock key (without the key prefix)
+	TTL time.Duration `json:"ttl"` // Remaining time before the lock expires (zero is no expiration)
+}
+
 // WriteLock will create a unique lock/secret with a TTL (seconds) to expire
 // The lockKey is unique and should be deterministic
-// The secret will be automatically generated and stored in the locked key (returned)
-func (c *Client) WriteLock(ctx context.Context, lockKey string, ttl int64) (string, error) {
+// The secret will be automatically generated and stored in the locked key (returned), see: WithLockSecretBytes
+func (c *Client) WriteLock(ctx context.Context, lockKey string, ttl int64) (secret string, err error) {
 
-	var secret string
-	var err error
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationWriteLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationWriteLock, lockKey, start, writeResult(err))
+		c.onError(operationWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
 	// Create a secret
-	if secret, err = RandomHex(32); err != nil {
+	if secret, err = RandomHex(c.options.lockSecretBytes); err != nil {
 		// This will "ALMOST NEVER" error out
 		return "", errors.Wrap(ErrSecretGenerationFailed, err.Error())
 	}
 
 	// Test the key and secret
-	if err = validateLockValues(lockKey, secret); err != nil {
+	if err = c.validateLockValues(lockKey, secret); err != nil {
+		return "", err
+	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
 		return "", err
 	}
 
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return "", c.engineNotSupported()
+	}
+
 	// Lock using Redis
-	if c.Engine() == Redis {
-		if _, err = cache.WriteLock(
+	if c.Engine().usesRedis() {
+		if _, err = writeLockRedis(
 			ctx, c.options.redis, lockKey, secret, ttl,
 		); err != nil {
 			return "", errors.Wrap(ErrLockCreateFailed, err.Error())
@@ -41,6 +144,7 @@ func (c *Client) WriteLock(ctx context.Context, lockKey string, ttl int64) (stri
 			return "", errors.Wrap(ErrLockCreateFailed, err.Error())
 		}
 	}
+	c.registerLock(lockKey)
 
 	return secret, nil
 }
@@ -48,18 +152,49 @@ func (c *Client) WriteLock(ctx context.Context, lockKey string, ttl int64) (stri
 // WriteLockWithSecret will create a lock with the given secret with a TTL (seconds) to expire
 // The lockKey is unique and should be deterministic
 // The secret should be unique per instance/process that wants to acquire the lock
-func (c *Client) WriteLockWithSecret(ctx context.Context, lockKey, secret string, ttl int64) (string, error) {
+func (c *Client) WriteLockWithSecret(ctx context.Context, lockKey, secret string, ttl int64) (_ string, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationWriteLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationWriteLock, lockKey, start, writeResult(err))
+		c.onError(operationWriteLock, lockKey, err)
+	}(lockKey)
 
-	var err error
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
 	// Test the key and secret
-	if err = validateLockValues(lockKey, secret); err != nil {
+	if err = c.validateLockValues(lockKey, secret); err != nil {
 		return "", err
 	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return "", err
+	}
+
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return "", c.engineNotSupported()
+	}
 
 	// Lock using Redis
-	if c.Engine() == Redis {
-		if _, err = cache.WriteLock(
+	if c.Engine().usesRedis() {
+		if _, err = writeLockRedis(
 			ctx, c.options.redis, lockKey, secret, ttl,
 		); err != nil {
 			return "", errors.Wrap(ErrLockCreateFailed, err.Error())
@@ -71,66 +206,655 @@ func (c *Client) WriteLockWithSecret(ctx context.Context, lockKey, secret string
 			return "", errors.Wrap(ErrLockCreateFailed, err.Error())
 		}
 	}
+	c.registerLock(lockKey)
 
 	return secret, nil
 }
 
+// TryWriteLock will try to create a unique lock/secret with a TTL (seconds) to expire, without waiting
+// The lockKey is unique and should be deterministic
+// If the lock is already held, acquired is false and there is no error
+func (c *Client) TryWriteLock(ctx context.Context, lockKey string, ttl int64) (secret string, acquired bool, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationTryWriteLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationTryWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationTryWriteLock, lockKey, start, writeResult(err))
+		c.onError(operationTryWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Create a secret
+	if secret, err = RandomHex(c.options.lockSecretBytes); err != nil {
+		// This will "ALMOST NEVER" error out
+		return "", false, errors.Wrap(ErrSecretGenerationFailed, err.Error())
+	}
+
+	// Test the key and secret
+	if err = c.validateLockValues(lockKey, secret); err != nil {
+		return "", false, err
+	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return "", false, err
+	}
+
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return "", false, c.engineNotSupported()
+	}
+
+	// Lock using Redis or FreeCache
+	if c.Engine().usesRedis() {
+		_, err = writeLockRedis(ctx, c.options.redis, lockKey, secret, ttl)
+	} else if c.Engine() == FreeCache {
+		_, err = writeLockFreeCache(c.options.freeCache, lockKey, secret, ttl)
+	}
+
+	// The lock is held by someone else (not an error)
+	if errors.Is(err, cache.ErrLockMismatch) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, errors.Wrap(ErrLockCreateFailed, err.Error())
+	}
+	c.registerLock(lockKey)
+
+	return secret, true, nil
+}
+
 // WaitWriteLock will aggressively try to make a lock until the TTW (in seconds) is reached
-func (c *Client) WaitWriteLock(ctx context.Context, lockKey string, ttl, ttw int64) (string, error) {
+//
+// The delay between the attempts is set using WithLockBackoff() (default: every 10 milliseconds)
+// A canceled context stops waiting immediately (ErrContextDone)
+// Only the result is measured and hooked (OnError), not each attempt on a lock held by someone else
+func (c *Client) WaitWriteLock(ctx context.Context, lockKey string, ttl, ttw int64) (secret string, err error) {
 
-	var secret string
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationWaitWriteLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationWaitWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationWaitWriteLock, lockKey, start, writeResult(err))
+		c.onError(operationWaitWriteLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
 	// Test the values
-	if len(lockKey) == 0 {
+	if err = c.checkClosed(); err != nil {
+		return secret, err
+	} else if len(lockKey) == 0 {
 		return secret, ErrKeyRequired
+	} else if err = c.validateKey(lockKey); err != nil {
+		return secret, err
+	} else if err = c.checkKeyLength(c.hashKey(lockKey)); err != nil {
+		return secret, err
 	} else if ttw <= 0 {
 		return secret, ErrTTWCannotBeEmpty
+	} else if c.Engine() == Memcached {
+		return secret, c.engineNotSupported()
 	}
 
+	// Create a secret (the same for each attempt)
+	if secret, err = RandomHex(c.options.lockSecretBytes); err != nil {
+		// This will "ALMOST NEVER" error out
+		return "", errors.Wrap(ErrSecretGenerationFailed, err.Error())
+	}
+	builtKey := c.buildLockKey(lockKey)
+
 	// Create the end time for the loop
-	end := time.Now().Add(time.Duration(ttw) * time.Second)
+	end := c.options.clock.Now().Add(time.Duration(ttw) * time.Second)
 
-	// Loop until we have a secret, or we are passed the end time
-	for {
-		if secret, _ = c.WriteLock(
-			ctx, lockKey, ttl,
-		); len(secret) > 0 || time.Now().After(end) {
+	// Loop until the lock is written, or we are passed the end time (stop if the context is done)
+	// The attempts are not measured or hooked, only the result of WaitWriteLock
+	for attempt := 0; ; attempt++ {
+		if err = checkContext(ctx); err != nil {
+			return "", err
+		}
+		if err = c.tryLockKey(ctx, builtKey, secret, ttl); err == nil {
+			c.registerLock(builtKey)
+			return secret, nil
+		} else if c.options.clock.Now().After(end) {
 			break
 		}
-		time.Sleep(lockRetrySleepTime)
+		if err = sleepContext(ctx, c.options.lockBackoff.delay(attempt)); err != nil {
+			return "", err
+		}
 	}
 
-	// No secret, lock creating failed or did not complete
-	if len(secret) == 0 {
+	// Lock creating failed or did not complete (held by someone else)
+	if errors.Is(err, cache.ErrLockMismatch) {
 		return "", ErrLockCreateFailed
 	}
+	return "", errors.Wrap(ErrLockCreateFailed, err.Error())
+}
 
-	return secret, nil
+// tryLockKey will make one attempt to write the lock (the lock key is already built), limited to the operation timeout
+func (c *Client) tryLockKey(ctx context.Context, lockKey, secret string, ttl int64) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+	return c.writeLockKey(ctx, lockKey, secret, ttl)
+}
+
+// WriteLockMany will create a unique lock/secret with a TTL (seconds) for each lock key (all or nothing)
+//
+// The keys are locked in sorted order (a global order, two callers locking the same keys never deadlock),
+// each lock waits for a lock held by another caller (up to writeLockManyTTW seconds, see: WaitWriteLock, WithLockBackoff)
+// If a lock fails the locks already taken are released and the error is returned (ie: ErrLockCreateFailed)
+// Returns the secret of each lock key, duplicate keys are locked once
+func (c *Client) WriteLockMany(ctx context.Context, lockKeys []string, ttl int64) (secrets map[string]string, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationWriteLockMany).End()
+
+	// Add the operation, key and engine to the error
+	defer func() {
+		err = c.wrapError(operationWriteLockMany, "", err)
+	}()
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func() {
+		c.observe(ctx, operationWriteLockMany, "", start, writeResult(err))
+		c.onError(operationWriteLockMany, "", err)
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Sort the keys (global order) and remove the duplicates
+	sorted := append([]string{}, lockKeys...)
+	sort.Strings(sorted)
+	secrets = make(map[string]string, len(sorted))
+	for i, lockKey := range sorted {
+		if i > 0 && lockKey == sorted[i-1] {
+			continue
+		}
+		var secret string
+		if secret, err = c.WaitWriteLock(ctx, lockKey, ttl, writeLockManyTTW); err != nil {
+			c.releaseLocks(ctx, secrets)
+			return nil, err
+		}
+		secrets[lockKey] = secret
+	}
+	return secrets, nil
+}
+
+// releaseLocks will release the locks (key->secret), the errors are ignored (the locks expire using the TTL)
+func (c *Client) releaseLocks(ctx context.Context, secrets map[string]string) {
+	ctx = withoutCancel(ctx)
+	for lockKey, secret := range secrets {
+		_, _ = c.ReleaseLock(ctx, lockKey, secret)
+	}
+}
+
+// AcquireLock will create a unique lock with a TTL (seconds) to expire and return the Lock (see: WriteLock)
+//
+// The Lock keeps the key and secret, use Release (ie: defer) and Extend instead of ReleaseLock and ExtendLock
+func (c *Client) AcquireLock(ctx context.Context, lockKey string, ttl int64) (Lock, error) {
+	secret, err := c.WriteLock(ctx, lockKey, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return &heldLock{client: c, key: lockKey, secret: secret}, nil
+}
+
+// ExtendLock will update the TTL (seconds) of a lock only if the secret matches (renew a lock held by a long-running job)
+//
+// A lock that does not exist (expired) or has a different secret returns cache.ErrLockMismatch
+func (c *Client) ExtendLock(ctx context.Context, lockKey, secret string, ttl int64) (extended bool, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationExtendLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationExtendLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationExtendLock, lockKey, start, writeResult(err))
+		c.onError(operationExtendLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Test the key, secret and ttl
+	if err = c.validateLockValues(lockKey, secret); err != nil {
+		return false, err
+	} else if ttl <= 0 {
+		return false, ErrTTLCannotBeEmpty
+	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return false, c.engineNotSupported()
+	}
+
+	// Extend the lock (default is FreeCache)
+	if c.Engine().usesRedis() {
+		extended, err = extendLockRedis(ctx, c.options.redis, lockKey, secret, ttl)
+	} else {
+		extended, err = extendLockFreeCache(c.options.freeCache, lockKey, secret, ttl)
+	}
+	if extended {
+		c.registerLock(lockKey)
+	}
+	return extended, err
 }
 
 // ReleaseLock will release a given lock key only if the secret matches
-func (c *Client) ReleaseLock(ctx context.Context, lockKey, secret string) (bool, error) {
+func (c *Client) ReleaseLock(ctx context.Context, lockKey, secret string) (released bool, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationReleaseLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationReleaseLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationReleaseLock, lockKey, start, writeResult(err))
+		c.onError(operationReleaseLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
 
 	// Test the key and secret
-	if err := validateLockValues(lockKey, secret); err != nil {
+	if err = c.validateLockValues(lockKey, secret); err != nil {
+		return false, err
+	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return false, c.engineNotSupported()
+	}
+
+	// Release the lock (default is FreeCache)
+	if c.Engine().usesRedis() {
+		released, err = releaseLockRedis(ctx, c.options.redis, lockKey, secret)
+	} else {
+		released, err = releaseLockFreeCache(c.options.freeCache, lockKey, secret)
+	}
+	if released {
+		c.unregisterLock(lockKey)
+	}
+	return released, err
+}
+
+// ForceReleaseLock will remove a lock regardless of the secret (returns true if the lock existed)
+//
+// CAUTION: this is an admin operation to clear a stuck lock (ie: the holder crashed), the holder is not notified
+// and a later ReleaseLock or ExtendLock by the holder fails (ErrLockMismatch) or affects a new holder's lock.
+// Use ReleaseLock with the secret in the normal flow, and ListLocks to find the stuck locks
+func (c *Client) ForceReleaseLock(ctx context.Context, lockKey string) (released bool, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationForceReleaseLock).End()
+
+	// Add the operation, key and engine to the error
+	defer func(lockKey string) {
+		err = c.wrapError(operationForceReleaseLock, lockKey, err)
+	}(lockKey)
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func(lockKey string) {
+		c.observe(ctx, operationForceReleaseLock, lockKey, start, writeResult(err))
+		c.onError(operationForceReleaseLock, lockKey, err)
+	}(lockKey)
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Require an open client and the key (valid, and not too long)
+	if err = c.checkClosed(); err != nil {
+		return false, err
+	} else if len(lockKey) == 0 {
+		return false, ErrKeyRequired
+	} else if err = c.validateKey(lockKey); err != nil {
 		return false, err
+	} else if err = c.checkKeyLength(c.hashKey(lockKey)); err != nil {
+		return false, err
+	}
+	lockKey = c.buildLockKey(lockKey)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return false, err
+	}
+
+	// Memcached does not support locks (yet)
+	if c.Engine() == Memcached {
+		return false, c.engineNotSupported()
+	}
+
+	// Remove the lock (default is FreeCache)
+	if c.Engine().usesRedis() {
+		if released, err = forceReleaseLockRedis(ctx, c.options.redis, lockKey); err != nil {
+			return false, err
+		}
+	} else {
+		released = forceReleaseLockFreeCache(c.options.freeCache, lockKey)
+	}
+	c.unregisterLock(lockKey)
+	return released, nil
+}
+
+// ListLocks will return the locks that are held with the remaining TTL (diagnostics, ie: finding stuck locks)
+//
+// Redis scans the lock namespace (SCAN, the locks of all the nodes), FreeCache keeps an index in the client
+// Redis requires a lock namespace (see: WithLockNamespace), otherwise the data keys cannot be told apart from the locks
+// The locks are only read (PTTL or TTL), never acquired or changed; the keys are sorted and without the key prefix
+// The internal locks (counters and the loader lock, see: WithLoaderLock) are not listed
+func (c *Client) ListLocks(ctx context.Context) (locks []LockInfo, err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationListLocks).End()
+
+	// Update the metrics, run the hooks
+	start := time.Now()
+	defer func() {
+		c.observe(ctx, operationListLocks, "", start, writeResult(err))
+		c.onError(operationListLocks, "", err)
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Stop if the client is closed, memcached does not support locks (yet)
+	if err = c.checkClosed(); err != nil {
+		return nil, err
+	} else if c.Engine() == Memcached {
+		return nil, c.engineNotSupported()
+	}
+
+	// Scan the lock namespace (default is FreeCache)
+	var ttls map[string]time.Duration
+	if c.Engine().usesRedis() {
+		if len(c.options.lockNamespace) == 0 {
+			return nil, ErrLockNamespaceRequired
+		}
+		if ttls, err = listLocksRedis(
+			ctx, c.options.redis, escapePattern(c.options.keyPrefix+c.options.lockNamespace)+"*", c.options.scanCount,
+		); err != nil {
+			return nil, err
+		}
+	} else {
+		ttls = c.options.locks.list(c.options.freeCache)
+	}
+
+	locks = make([]LockInfo, 0, len(ttls))
+	for lockKey, ttl := range ttls {
+		if key := c.stripLockKey(lockKey); !isInternalLock(key) {
+			locks = append(locks, LockInfo{Key: key, TTL: ttl})
+		}
+	}
+	sort.Slice(locks, func(i, j int) bool {
+		return locks[i].Key < locks[j].Key
+	})
+	return locks, nil
+}
+
+// registerLock will add the lock to the index (FreeCache, redis scans the lock namespace, see: ListLocks)
+//
+// lockKey includes the key prefix
+func (c *Client) registerLock(lockKey string) {
+	if !c.Engine().usesRedis() {
+		c.options.locks.add(c.options.freeCache, lockKey)
+	}
+}
+
+// unregisterLock will remove the released lock from the index (FreeCache, see: ListLocks)
+//
+// lockKey includes the key prefix
+func (c *Client) unregisterLock(lockKey string) {
+	if !c.Engine().usesRedis() {
+		c.options.locks.remove(lockKey)
 	}
+}
+
+// lockIndex is the index of the locks held (FreeCache, see: ListLocks)
+//
+// Locks that are no longer held (expired or removed) are pruned when listed or when the index is full
+type lockIndex struct {
+	keys    map[string]struct{} // Lock keys (with the key prefix)
+	maxKeys int                 // Max number of keys before pruning
+	sync.Mutex
+}
+
+// newLockIndex will create an empty index that prunes when holding more than maxKeys keys
+func newLockIndex(maxKeys int) *lockIndex {
+	return &lockIndex{
+		keys:    make(map[string]struct{}),
+		maxKeys: maxKeys,
+	}
+}
 
-	// Release the lock
-	if c.Engine() == Redis {
-		return cache.ReleaseLock(ctx, c.options.redis, lockKey, secret)
+// add will add the lock key to the index (the index is pruned when full)
+func (l *lockIndex) add(freeCacheClient *freecache.Cache, lockKey string) {
+	l.Lock()
+	defer l.Unlock()
+	l.keys[lockKey] = struct{}{}
+	if len(l.keys) > l.maxKeys {
+		l.prune(freeCacheClient)
 	}
+}
+
+// prune will remove the keys that are no longer cached (TTL does not change the statistics or the entries)
+func (l *lockIndex) prune(freeCacheClient *freecache.Cache) {
+	for lockKey := range l.keys {
+		if _, err := freeCacheClient.TTL([]byte(lockKey)); err != nil {
+			delete(l.keys, lockKey)
+		}
+	}
+}
+
+// list will prune the index and return the remaining ttl of each lock key (zero is no expiration)
+func (l *lockIndex) list(freeCacheClient *freecache.Cache) map[string]time.Duration {
+	l.Lock()
+	defer l.Unlock()
+	ttls := make(map[string]time.Duration, len(l.keys))
+	for lockKey := range l.keys {
+		ttl, err := freeCacheClient.TTL([]byte(lockKey))
+		if err != nil {
+			delete(l.keys, lockKey)
+			continue
+		}
+		ttls[lockKey] = time.Duration(ttl) * time.Second
+	}
+	return ttls
+}
+
+// remove will remove the lock key from the index
+func (l *lockIndex) remove(lockKey string) {
+	l.Lock()
+	defer l.Unlock()
+	delete(l.keys, lockKey)
+}
+
+// clear will remove all the keys
+func (l *lockIndex) clear() {
+	l.Lock()
+	defer l.Unlock()
+	l.keys = make(map[string]struct{})
+}
 
-	// Default is FreeCache
+// lockBackoff is the delay between the attempts of WaitWriteLock (exponential, with optional jitter)
+type lockBackoff struct {
+	factor   float64       // Multiplier applied to the delay after each attempt
+	initial  time.Duration // Delay after the first attempt
+	jitter   bool          // Randomize each delay (between half and the full delay)
+	maxDelay time.Duration // Max delay between the attempts
+}
+
+// delay will return the delay after the attempt (zero based)
+func (b lockBackoff) delay(attempt int) time.Duration {
+	delay := b.initial
+	if b.factor > 1 {
+		if grown := float64(b.initial) * math.Pow(b.factor, float64(attempt)); grown < float64(b.maxDelay) {
+			delay = time.Duration(grown)
+		} else {
+			delay = b.maxDelay
+		}
+	}
+	if b.jitter && delay > 1 {
+		delay = delay/2 + rand.N(delay/2) //nolint:gosec // jitter does not need a secure random number
+	}
+	return delay
+}
+
+// lockCounter will take the internal lock of a counter or an append (FreeCache has no atomic read-modify-write)
+//
+// The key is already built (the user key is validated)
+// Returns the function to release the lock, or ErrLockCreateFailed if not acquired after counterLockTTW
+func (c *Client) lockCounter(ctx context.Context, key string) (release func(), err error) {
+	return c.waitInternalLock(
+		ctx, c.options.keyPrefix+c.options.lockNamespace+counterLockPrefix+c.stripKey(key), counterLockTTL, counterLockTTW,
+	)
+}
+
+// waitInternalLock will wait up to the TTW (seconds) to write an internal lock with a TTL (seconds)
+//
+// The lock key is already built, the internal lock key is not validated, listed (see: ListLocks), measured or hooked
+// Returns the function to release the lock, or ErrLockCreateFailed if not acquired after the TTW
+func (c *Client) waitInternalLock(ctx context.Context, lockKey string, ttl, ttw int64) (release func(), err error) {
+	var secret string
+	if secret, err = RandomHex(c.options.lockSecretBytes); err != nil {
+		return nil, errors.Wrap(ErrSecretGenerationFailed, err.Error())
+	}
+
+	// Loop until the lock is written, or we are passed the end time (stop if the context is done)
+	end := c.options.clock.Now().Add(time.Duration(ttw) * time.Second)
+	for attempt := 0; ; attempt++ {
+		if err = checkContext(ctx); err != nil {
+			return nil, err
+		} else if err = c.writeLockKey(ctx, lockKey, secret, ttl); err == nil {
+			return func() {
+				_, _ = c.releaseLockKey(withoutCancel(ctx), lockKey, secret)
+			}, nil
+		} else if !errors.Is(err, cache.ErrLockMismatch) {
+			return nil, errors.Wrap(ErrLockCreateFailed, err.Error())
+		} else if c.options.clock.Now().After(end) {
+			return nil, ErrLockCreateFailed
+		} else if err = sleepContext(ctx, c.options.lockBackoff.delay(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// writeLockKey will write the lock using the engine (redis or FreeCache), the lock key is already built
+//
+// Returns cache.ErrLockMismatch if the lock is held with another secret
+func (c *Client) writeLockKey(ctx context.Context, lockKey, secret string, ttl int64) (err error) {
+	if c.Engine().usesRedis() {
+		_, err = writeLockRedis(ctx, c.options.redis, lockKey, secret, ttl)
+	} else {
+		_, err = writeLockFreeCache(c.options.freeCache, lockKey, secret, ttl)
+	}
+	return err
+}
+
+// releaseLockKey will release the lock held with the secret using the engine, the lock key is already built
+func (c *Client) releaseLockKey(ctx context.Context, lockKey, secret string) (bool, error) {
+	if c.Engine().usesRedis() {
+		return releaseLockRedis(ctx, c.options.redis, lockKey, secret)
+	}
 	return releaseLockFreeCache(c.options.freeCache, lockKey, secret)
 }
 
+// buildLockKey will hash the lock key (if enabled) and add the key prefix and the lock namespace (see: WithLockNamespace)
+func (c *Client) buildLockKey(lockKey string) string {
+	return c.options.keyPrefix + c.options.lockNamespace + c.hashKey(lockKey)
+}
+
+// stripLockKey will remove the key prefix and the lock namespace from the lock key
+func (c *Client) stripLockKey(lockKey string) string {
+	return strings.TrimPrefix(c.stripKey(lockKey), c.options.lockNamespace)
+}
+
+// isInternalLock will return true if the lock key (without the key prefix and lock namespace) is an internal lock
+//
+// The internal locks (counters and loaders) are not listed, see: ListLocks
+func isInternalLock(lockKey string) bool {
+	return strings.HasPrefix(lockKey, counterLockPrefix) || strings.HasPrefix(lockKey, loaderLockPrefix)
+}
+
 // validateLockValues will validate and test the lock/secret values
-func validateLockValues(lockKey, secret string) error {
+func (c *Client) validateLockValues(lockKey, secret string) error {
 
-	// Require a key to be present
-	if len(lockKey) == 0 {
+	// Require an open client and a key to be present (valid, and not too long)
+	if err := c.checkClosed(); err != nil {
+		return err
+	} else if len(lockKey) == 0 {
 		return ErrKeyRequired
+	} else if err := c.validateKey(lockKey); err != nil {
+		return err
+	} else if err := c.checkKeyLength(c.hashKey(lockKey)); err != nil {
+		return err
 	}
 
 	// Require a secret to be present
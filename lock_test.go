@@ -2,14 +2,23 @@ package cachestore
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/coocood/freecache"
+	"github.com/gomodule/redigo/redis"
 	"github.com/mrz1836/go-cache"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// evalShaCommand is the command used to run a script (redis)
+const evalShaCommand = "EVALSHA"
+
+// testLockKey is the stored key of the testKey lock (see: WithLockNamespace)
+const testLockKey = DefaultLockNamespace + testKey
+
 // TestClient_WriteLock will test the method WriteLock()
 func TestClient_WriteLock(t *testing.T) {
 
@@ -24,7 +33,7 @@ func TestClient_WriteLock(t *testing.T) {
 			secret, err = c.WriteLock(context.Background(), "", 30)
 			assert.Equal(t, "", secret)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrKeyRequired.Error())
+			require.ErrorIs(t, err, ErrKeyRequired)
 		})
 
 		t.Run(testCase.name+" - valid lock", func(t *testing.T) {
@@ -42,6 +51,32 @@ func TestClient_WriteLock(t *testing.T) {
 			}()
 		})
 
+		t.Run(testCase.name+" - configured secret length", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithLockSecretBytes(MinLockSecretBytes))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.Len(t, secret, 2*MinLockSecretBytes)
+
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			var acquired bool
+			secret, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			assert.Len(t, secret, 2*MinLockSecretBytes)
+
+			released, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+		})
+
 		t.Run(testCase.name+" - lock conflict", func(t *testing.T) {
 			var secret string
 			c, err := NewClient(context.Background(), testCase.opts)
@@ -59,11 +94,73 @@ func TestClient_WriteLock(t *testing.T) {
 			// Lock exists with different secret
 			secret, err = c.WriteLock(context.Background(), testKey, 30)
 			assert.Equal(t, "", secret)
-			require.EqualError(t, err, "key is locked with a different secret: failed creating cache lock")
+			require.ErrorIs(t, err, ErrLockCreateFailed)
+			assert.Contains(t, err.Error(), "key is locked with a different secret")
 		})
 	}
 
-	// todo: add redis lock tests
+	t.Run("["+Redis.String()+"] [mock] - lock uses SET NX PX", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.GenericCommand(cache.SetCommand).Expect("OK")
+
+		secret, err := c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+		assert.Len(t, secret, 64)
+		assert.True(t, setCmd.Called)
+	})
+
+	t.Run("["+Redis.String()+"] [mock] - lock conflict", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.GenericCommand(cache.SetCommand).ExpectError(redis.ErrNil)
+		refreshCmd := conn.GenericCommand(evalShaCommand).Expect(int64(0))
+
+		secret, err := c.WriteLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrLockCreateFailed)
+		assert.Empty(t, secret)
+		assert.True(t, setCmd.Called)
+		assert.True(t, refreshCmd.Called)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] - lock is shared by all clients", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		first, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer first.Close(context.Background())
+
+		var second ClientInterface
+		second, err = NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer second.Close(context.Background())
+
+		var secret string
+		secret, err = first.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		// The lock stores the secret with an expiration
+		var val string
+		val, err = r.Get(testLockKey)
+		require.NoError(t, err)
+		assert.Equal(t, secret, val)
+		assert.Equal(t, 30*time.Second, r.TTL(testLockKey))
+
+		// Another client (instance) cannot take or release the lock
+		_, err = second.WriteLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrLockCreateFailed)
+
+		var released bool
+		released, err = second.ReleaseLock(context.Background(), testKey, secret+"-bad-key")
+		require.ErrorIs(t, err, cache.ErrLockMismatch)
+		assert.False(t, released)
+		assert.True(t, r.Exists(testLockKey))
+
+		// The lock expires
+		r.FastForward(31 * time.Second)
+		_, err = second.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+	})
 }
 
 // TestClient_WriteLockWithSecret will test the method WriteLockWithSecret()
@@ -80,7 +177,7 @@ func TestClient_WriteLockWithSecret(t *testing.T) {
 			secret, err = c.WriteLockWithSecret(context.Background(), "", "", 30)
 			assert.Equal(t, "", secret)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrKeyRequired.Error())
+			require.ErrorIs(t, err, ErrKeyRequired)
 		})
 
 		t.Run(testCase.name+" - valid lock", func(t *testing.T) {
@@ -115,7 +212,8 @@ func TestClient_WriteLockWithSecret(t *testing.T) {
 			// Lock exists with different secret
 			secret, err = c.WriteLockWithSecret(context.Background(), testKey, "secret2", 30)
 			assert.Equal(t, "", secret)
-			require.EqualError(t, err, "key is locked with a different secret: failed creating cache lock")
+			require.ErrorIs(t, err, ErrLockCreateFailed)
+			assert.Contains(t, err.Error(), "key is locked with a different secret")
 		})
 
 		t.Run(testCase.name+" - update lock ttl", func(t *testing.T) {
@@ -153,7 +251,7 @@ func TestClient_ReleaseLock(t *testing.T) {
 			success, err = c.ReleaseLock(context.Background(), "", "some-value")
 			assert.False(t, success)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrKeyRequired.Error())
+			require.ErrorIs(t, err, ErrKeyRequired)
 		})
 
 		t.Run(testCase.name+" - missing secret", func(t *testing.T) {
@@ -165,7 +263,7 @@ func TestClient_ReleaseLock(t *testing.T) {
 			success, err = c.ReleaseLock(context.Background(), testKey, "")
 			assert.False(t, success)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrSecretRequired.Error())
+			require.ErrorIs(t, err, ErrSecretRequired)
 		})
 
 		t.Run(testCase.name+" - valid release", func(t *testing.T) {
@@ -202,7 +300,613 @@ func TestClient_ReleaseLock(t *testing.T) {
 		})
 	}
 
-	// todo: add redis lock tests
+	t.Run("["+Redis.String()+"] [mock] - valid release", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		releaseCmd := conn.GenericCommand(evalShaCommand).Expect(int64(1))
+
+		success, err := c.ReleaseLock(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+		assert.True(t, success)
+		assert.True(t, releaseCmd.Called)
+	})
+
+	t.Run("["+Redis.String()+"] [mock] - invalid secret", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		releaseCmd := conn.GenericCommand(evalShaCommand).Expect(int64(0))
+
+		success, err := c.ReleaseLock(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, cache.ErrLockMismatch)
+		assert.False(t, success)
+		assert.True(t, releaseCmd.Called)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] - missing lock is released", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var success bool
+		success, err = c.ReleaseLock(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+		assert.True(t, success)
+	})
+}
+
+// TestClient_LockSecretComparison will test the secret comparison of ReleaseLock and ExtendLock
+func TestClient_LockSecretComparison(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - a wrong secret fails, the right secret succeeds", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			// Same length, only the last character is different (and a prefix of the secret)
+			for _, wrong := range []string{secret[:len(secret)-1] + "x", secret[:len(secret)-1]} {
+				var ok bool
+				ok, err = c.ExtendLock(context.Background(), testKey, wrong, 60)
+				require.ErrorIs(t, err, cache.ErrLockMismatch)
+				assert.False(t, ok)
+
+				ok, err = c.ReleaseLock(context.Background(), testKey, wrong)
+				require.ErrorIs(t, err, cache.ErrLockMismatch)
+				assert.False(t, ok)
+			}
+
+			var ok bool
+			ok, err = c.ExtendLock(context.Background(), testKey, secret, 60)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		})
+	}
+}
+
+// TestClient_ForceReleaseLock will test the method ForceReleaseLock()
+func TestClient_ForceReleaseLock(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var released bool
+			released, err = c.ForceReleaseLock(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.False(t, released)
+		})
+
+		t.Run(testCase.name+" - releases a lock without the secret", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			// The secret is required to release the lock
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey, "wrong-secret")
+			require.ErrorIs(t, err, cache.ErrLockMismatch)
+			assert.False(t, released)
+
+			released, err = c.ForceReleaseLock(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			// The lock is available and no longer listed
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, locks)
+
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+		})
+
+		t.Run(testCase.name+" - lock does not exist", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var released bool
+			released, err = c.ForceReleaseLock(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			assert.False(t, released)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - force release uses DEL", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		delCmd := conn.Command(cache.DeleteCommand, testLockKey).Expect(int64(1))
+
+		released, err := c.ForceReleaseLock(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, released)
+		assert.True(t, delCmd.Called)
+	})
+}
+
+// TestClient_TryWriteLock will test the method TryWriteLock()
+func TestClient_TryWriteLock(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing lock key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			var acquired bool
+			secret, acquired, err = c.TryWriteLock(context.Background(), "", 30)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Empty(t, secret)
+			assert.False(t, acquired)
+		})
+
+		t.Run(testCase.name+" - valid lock", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			var acquired bool
+			secret, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			assert.Len(t, secret, 64)
+
+			defer func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, secret)
+			}()
+		})
+
+		t.Run(testCase.name+" - lock is held", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			defer func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, secret)
+			}()
+
+			// Returns immediately without an error
+			var other string
+			var acquired bool
+			other, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+			assert.Empty(t, other)
+
+			// Acquired after the release
+			_, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+
+			other, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.True(t, acquired)
+			assert.Len(t, other, 64)
+		})
+
+		t.Run(testCase.name+" - canceled context", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(ctx, testKey, 30)
+			require.ErrorIs(t, err, context.Canceled)
+			assert.False(t, acquired)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - redis error", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.GenericCommand(cache.SetCommand).ExpectError(redis.ErrPoolExhausted)
+
+		secret, acquired, err := c.TryWriteLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrLockCreateFailed)
+		assert.False(t, acquired)
+		assert.Empty(t, secret)
+		assert.True(t, setCmd.Called)
+	})
+}
+
+// TestClient_WriteLockMany will test the method WriteLockMany()
+func TestClient_WriteLockMany(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - locks all the keys", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			secrets, err := c.WriteLockMany(context.Background(), []string{testKey + "-b", testKey + "-a", testKey + "-b"}, 30)
+			require.NoError(t, err)
+			require.Len(t, secrets, 2)
+			for _, lockKey := range []string{testKey + "-a", testKey + "-b"} {
+				assert.Len(t, secrets[lockKey], 64)
+				_, err = c.WriteLock(context.Background(), lockKey, 30)
+				require.ErrorIs(t, err, ErrLockCreateFailed)
+
+				var released bool
+				released, err = c.ReleaseLock(context.Background(), lockKey, secrets[lockKey])
+				require.NoError(t, err)
+				assert.True(t, released)
+			}
+
+			// No keys
+			secrets, err = c.WriteLockMany(context.Background(), nil, 30)
+			require.NoError(t, err)
+			assert.Empty(t, secrets)
+		})
+
+		t.Run(testCase.name+" - a failure releases the locks already taken", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			held, err := c.WriteLock(context.Background(), testKey+"-c", 30)
+			require.NoError(t, err)
+
+			// Stop waiting for the held lock
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			var secrets map[string]string
+			secrets, err = c.WriteLockMany(ctx, []string{testKey + "-c", testKey + "-a", testKey + "-b"}, 30)
+			require.ErrorIs(t, err, ErrContextDone)
+			assert.Nil(t, secrets)
+
+			// The locks taken before the failure (sorted order) are released
+			secrets, err = c.WriteLockMany(context.Background(), []string{testKey + "-a", testKey + "-b"}, 30)
+			require.NoError(t, err)
+			assert.Len(t, secrets, 2)
+
+			// The lock held by someone else is not released
+			_, err = c.ReleaseLock(context.Background(), testKey+"-c", held)
+			require.NoError(t, err)
+		})
+
+		t.Run(testCase.name+" - an invalid key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, err = c.WriteLockMany(context.Background(), []string{testKey, ""}, 30)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Contains(t, err.Error(), "write_lock_many")
+
+			// Nothing is locked
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+		})
+
+		t.Run(testCase.name+" - waits for a lock held by another caller", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			held, err := c.WriteLock(context.Background(), testKey+"-b", 30)
+			require.NoError(t, err)
+			time.AfterFunc(50*time.Millisecond, func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey+"-b", held)
+			})
+
+			var secrets map[string]string
+			secrets, err = c.WriteLockMany(context.Background(), []string{testKey + "-a", testKey + "-b"}, 30)
+			require.NoError(t, err)
+			assert.Len(t, secrets, 2)
+		})
+
+		t.Run(testCase.name+" - overlapping keys in a different order do not deadlock", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			keySets := [][]string{
+				{testKey + "-a", testKey + "-b", testKey + "-c", testKey + "-d"},
+				{testKey + "-d", testKey + "-c", testKey + "-e"},
+				{testKey + "-e", testKey + "-b", testKey + "-a"},
+			}
+
+			const rounds = 20
+			holders := make(map[string]int)
+			var mu sync.Mutex
+			var wg sync.WaitGroup
+			acquired := make([]int, len(keySets))
+			for worker, lockKeys := range keySets {
+				wg.Add(1)
+				go func(worker int, lockKeys []string) {
+					defer wg.Done()
+					for i := 0; i < rounds; i++ {
+						secrets, lockErr := c.WriteLockMany(context.Background(), lockKeys, 30)
+						if !assert.NoError(t, lockErr) {
+							return
+						}
+
+						// A key is never held by two workers at the same time
+						mu.Lock()
+						for _, lockKey := range lockKeys {
+							holders[lockKey]++
+							assert.Equal(t, 1, holders[lockKey], lockKey)
+						}
+						mu.Unlock()
+						time.Sleep(time.Millisecond)
+						mu.Lock()
+						for _, lockKey := range lockKeys {
+							holders[lockKey]--
+						}
+						mu.Unlock()
+
+						for lockKey, secret := range secrets {
+							_, releaseErr := c.ReleaseLock(context.Background(), lockKey, secret)
+							assert.NoError(t, releaseErr)
+						}
+						acquired[worker]++
+					}
+				}(worker, lockKeys)
+			}
+			wg.Wait()
+
+			// Every call succeeds (waiting instead of failing)
+			assert.Equal(t, []int{rounds, rounds, rounds}, acquired)
+		})
+	}
+
+	t.Run("["+Memcached.String()+"] - not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		_, err := c.WriteLockMany(context.Background(), []string{testKey}, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+}
+
+// TestClient_AcquireLock will test the method AcquireLock() and the Lock methods
+func TestClient_AcquireLock(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing key", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var lock Lock
+			lock, err = c.AcquireLock(context.Background(), "", 30)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.Nil(t, lock)
+		})
+
+		t.Run(testCase.name+" - acquire, extend and release", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var lock Lock
+			lock, err = c.AcquireLock(context.Background(), testKey, 10)
+			require.NoError(t, err)
+			require.NotNil(t, lock)
+			assert.Equal(t, testKey, lock.Key())
+			assert.Len(t, lock.Secret(), 64)
+
+			// The lock is held
+			_, err = c.AcquireLock(context.Background(), testKey, 10)
+			require.ErrorIs(t, err, ErrLockCreateFailed)
+
+			require.NoError(t, lock.Extend(context.Background(), 60))
+			assert.Greater(t, testCase.TTL(c, testLockKey), 30*time.Second)
+
+			require.NoError(t, lock.Release(context.Background()))
+
+			// Released, a new lock can be acquired
+			var next Lock
+			next, err = c.AcquireLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			// Releasing again does not release the new lock, extending fails
+			require.NoError(t, lock.Release(context.Background()))
+			require.ErrorIs(t, lock.Extend(context.Background(), 30), cache.ErrLockMismatch)
+
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+			require.NoError(t, next.Release(context.Background()))
+		})
+
+		t.Run(testCase.name+" - expired lock acquired by someone else", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var lock Lock
+			lock, err = c.AcquireLock(context.Background(), testKey, 1)
+			require.NoError(t, err)
+
+			testCase.FastForward(2 * time.Second)
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			require.ErrorIs(t, lock.Release(context.Background()), cache.ErrLockMismatch)
+			require.NoError(t, lock.Release(context.Background())) // Not held anymore
+
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+		})
+	}
+
+	t.Run("["+FreeCache.String()+"] - concurrent releases", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		var lock Lock
+		lock, err = c.AcquireLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, lock.Release(context.Background()))
+			}()
+		}
+		wg.Wait()
+
+		var locks []LockInfo
+		locks, err = c.ListLocks(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, locks)
+	})
+}
+
+// TestClient_ExtendLock will test the method ExtendLock()
+func TestClient_ExtendLock(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - missing values", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var extended bool
+			extended, err = c.ExtendLock(context.Background(), "", testValue, 30)
+			require.ErrorIs(t, err, ErrKeyRequired)
+			assert.False(t, extended)
+
+			extended, err = c.ExtendLock(context.Background(), testKey, "", 30)
+			require.ErrorIs(t, err, ErrSecretRequired)
+			assert.False(t, extended)
+
+			extended, err = c.ExtendLock(context.Background(), testKey, testValue, 0)
+			require.ErrorIs(t, err, ErrTTLCannotBeEmpty)
+			assert.False(t, extended)
+		})
+
+		t.Run(testCase.name+" - renewed lock survives the original expiration", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 1)
+			require.NoError(t, err)
+
+			defer func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, secret)
+			}()
+
+			var extended bool
+			extended, err = c.ExtendLock(context.Background(), testKey, secret, 30)
+			require.NoError(t, err)
+			assert.True(t, extended)
+			assert.Greater(t, testCase.TTL(c, testLockKey), 20*time.Second)
+
+			// Past the original expiration
+			testCase.FastForward(2 * time.Second)
+
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+		})
+
+		t.Run(testCase.name+" - invalid secret", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			defer func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, secret)
+			}()
+
+			var extended bool
+			extended, err = c.ExtendLock(context.Background(), testKey, secret+"-bad-key", 60)
+			require.ErrorIs(t, err, cache.ErrLockMismatch)
+			assert.False(t, extended)
+			assert.LessOrEqual(t, testCase.TTL(c, testLockKey), 30*time.Second)
+		})
+
+		t.Run(testCase.name+" - lock does not exist", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var extended bool
+			extended, err = c.ExtendLock(context.Background(), testKey+"-missing", testValue, 30)
+			require.ErrorIs(t, err, cache.ErrLockMismatch)
+			assert.False(t, extended)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [mock] - extend uses a script", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		extendCmd := conn.GenericCommand(evalShaCommand).Expect(int64(1))
+
+		extended, err := c.ExtendLock(context.Background(), testKey, testValue, 30)
+		require.NoError(t, err)
+		assert.True(t, extended)
+		assert.True(t, extendCmd.Called)
+	})
 }
 
 // TestClient_WaitWriteLock will test the method WaitWriteLock()
@@ -222,7 +926,54 @@ func TestClient_WaitWriteLock(t *testing.T) {
 			secret, err = c.WaitWriteLock(ctx, "", 30, 10)
 			assert.Equal(t, "", secret)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrKeyRequired.Error())
+			require.ErrorIs(t, err, ErrKeyRequired)
+		})
+
+		t.Run(testCase.name+" - only the result is reported", func(t *testing.T) {
+			var mu sync.Mutex
+			var hooked []string
+			c, err := NewClient(context.Background(), testCase.opts, WithObservabilityContext(),
+				WithHooks(Hooks{OnError: func(operation, _ string, _ error) {
+					mu.Lock()
+					defer mu.Unlock()
+					hooked = append(hooked, operation)
+				}}),
+			)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			held, err := c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			time.AfterFunc(100*time.Millisecond, func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, held)
+			})
+
+			// Acquired after waiting (the failed attempts are not errors)
+			ctx := ContextWithCacheEvents(context.Background())
+			var secret string
+			secret, err = c.WaitWriteLock(ctx, testKey, 30, 5)
+			require.NoError(t, err)
+			assert.NotEmpty(t, secret)
+			events := CacheEventsFromContext(ctx)
+			require.Len(t, events, 1)
+			assert.Equal(t, operationWaitWriteLock, events[0].Operation)
+			assert.Equal(t, resultSuccess, events[0].Result)
+			mu.Lock()
+			assert.Empty(t, hooked)
+			mu.Unlock()
+
+			// Not acquired, one error
+			ctx = ContextWithCacheEvents(context.Background())
+			_, err = c.WaitWriteLock(ctx, testKey, 30, 1)
+			require.ErrorIs(t, err, ErrLockCreateFailed)
+			events = CacheEventsFromContext(ctx)
+			require.Len(t, events, 1)
+			assert.Equal(t, resultError, events[0].Result)
+			mu.Lock()
+			assert.Equal(t, []string{operationWaitWriteLock}, hooked)
+			mu.Unlock()
 		})
 
 		t.Run(testCase.name+" - missing ttw", func(t *testing.T) {
@@ -235,7 +986,7 @@ func TestClient_WaitWriteLock(t *testing.T) {
 			secret, err = c.WaitWriteLock(ctx, testKey, 30, 0)
 			assert.Equal(t, "", secret)
 			require.Error(t, err)
-			require.EqualError(t, err, ErrTTWCannotBeEmpty.Error())
+			require.ErrorIs(t, err, ErrTTWCannotBeEmpty)
 		})
 
 		t.Run(testCase.name+" - valid lock", func(t *testing.T) {
@@ -280,6 +1031,30 @@ func TestClient_WaitWriteLock(t *testing.T) {
 			}()
 		})
 
+		t.Run(testCase.name+" - canceled while waiting", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithLockBackoff(time.Second, 5*time.Second, 2))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			defer func() {
+				_, _ = c.ReleaseLock(context.Background(), testKey, secret)
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			var other string
+			other, err = c.WaitWriteLock(ctx, testKey, 30, 10)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Empty(t, other)
+			assert.Less(t, time.Since(start), 500*time.Millisecond)
+		})
+
 		t.Run(testCase.name+" - lock jammed, never completes", func(t *testing.T) {
 			var ctx context.Context
 			var secret string
@@ -301,3 +1076,349 @@ func TestClient_WaitWriteLock(t *testing.T) {
 		})
 	}
 }
+
+// TestClient_ListLocks will test the method ListLocks()
+func TestClient_ListLocks(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - no locks", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, locks)
+			assert.NotNil(t, locks)
+		})
+
+		t.Run(testCase.name+" - held locks are listed", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey+"-b", 30)
+			require.NoError(t, err)
+			_, _, err = c.TryWriteLock(context.Background(), testKey+"-a", 60)
+			require.NoError(t, err)
+			require.NoError(t, c.Set(context.Background(), testKey, testValue)) // Not a lock
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 2)
+			assert.Equal(t, testKey+"-a", locks[0].Key)
+			assert.InDelta(t, 60*time.Second, locks[0].TTL, float64(2*time.Second))
+			assert.Equal(t, testKey+"-b", locks[1].Key)
+			assert.InDelta(t, 30*time.Second, locks[1].TTL, float64(2*time.Second))
+
+			// Listing does not disturb the locks
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey+"-b", 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+
+			// Released locks are removed
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey+"-b", secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 1)
+			assert.Equal(t, testKey+"-a", locks[0].Key)
+		})
+
+		t.Run(testCase.name+" - extended and expired locks", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 10)
+			require.NoError(t, err)
+			_, err = c.WriteLock(context.Background(), testKey+"-expiring", 10)
+			require.NoError(t, err)
+
+			_, err = c.ExtendLock(context.Background(), testKey, secret, 120)
+			require.NoError(t, err)
+
+			testCase.FastForward(20 * time.Second)
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 1)
+			assert.Equal(t, testKey, locks[0].Key)
+			assert.Greater(t, locks[0].TTL, 90*time.Second)
+		})
+
+		t.Run(testCase.name+" - key prefix", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyPrefix("app:"))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			_, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 1)
+			assert.Equal(t, testKey, locks[0].Key)
+		})
+
+		t.Run(testCase.name+" - context is done", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err = c.ListLocks(ctx)
+			require.ErrorIs(t, err, ErrContextDone)
+		})
+	}
+
+	t.Run("["+Redis.String()+"] [in-memory] - locks of the other nodes are listed", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		node1, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer node1.Close(context.Background())
+		var node2 ClientInterface
+		node2, err = NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer node2.Close(context.Background())
+
+		_, err = node1.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		var locks []LockInfo
+		locks, err = node2.ListLocks(context.Background())
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		assert.Equal(t, testKey, locks[0].Key)
+	})
+	t.Run("["+Redis.String()+"] [in-memory] - the locks are scanned, not registered", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"app:lock:" + testKey}, r.Keys()) // No registry is written
+
+		// A lock written outside the client (ie: an older version) is listed
+		require.NoError(t, r.Set("app:lock:"+testKey+"-other", testValue))
+		r.SetTTL("app:lock:"+testKey+"-other", 60*time.Second)
+		require.NoError(t, r.Set("app:"+testKey+"-data", testValue)) // Not a lock
+
+		var locks []LockInfo
+		locks, err = c.ListLocks(context.Background())
+		require.NoError(t, err)
+		require.Len(t, locks, 2)
+		assert.Equal(t, testKey, locks[0].Key)
+		assert.Equal(t, testKey+"-other", locks[1].Key)
+		assert.Equal(t, 60*time.Second, locks[1].TTL)
+
+		// A lock removed outside the client is not listed
+		r.Del("app:lock:" + testKey)
+		locks, err = c.ListLocks(context.Background())
+		require.NoError(t, err)
+		require.Len(t, locks, 1)
+		assert.Equal(t, testKey+"-other", locks[0].Key)
+	})
+
+	t.Run("["+Redis.String()+"] [in-memory] - an empty lock namespace cannot be listed", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithLockNamespace(""))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		_, err = c.ListLocks(context.Background())
+		require.ErrorIs(t, err, ErrLockNamespaceRequired)
+	})
+}
+
+// TestClient_LockNamespace will test the lock keys and the data keys with the same name (see: WithLockNamespace)
+func TestClient_LockNamespace(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - a lock and a key with the same name do not collide", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+			var secret string
+			secret, err = c.WaitWriteLock(context.Background(), testKey, 30, 1)
+			require.NoError(t, err)
+
+			// The value is not replaced by the secret
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			// Removing the key does not release the lock
+			require.NoError(t, c.Delete(context.Background(), testKey))
+			var acquired bool
+			_, acquired, err = c.TryWriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.False(t, acquired)
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 1)
+			assert.Equal(t, testKey, locks[0].Key)
+
+			// Releasing the lock does not remove the key
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+		})
+
+		t.Run(testCase.name+" - custom namespace", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithKeyPrefix("app:"), WithLockNamespace("locks/"))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+			assert.Greater(t, testCase.TTL(c, "app:locks/"+testKey), 20*time.Second)
+
+			var locks []LockInfo
+			locks, err = c.ListLocks(context.Background())
+			require.NoError(t, err)
+			require.Len(t, locks, 1)
+			assert.Equal(t, testKey, locks[0].Key)
+
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey, secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+		})
+
+		t.Run(testCase.name+" - empty namespace uses the lock key as is", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithLockNamespace(""))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey, 30)
+			require.NoError(t, err)
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, secret, value)
+		})
+	}
+}
+
+// Test_lockIndex will test the FreeCache lock index (pruning)
+func Test_lockIndex(t *testing.T) {
+	t.Parallel()
+
+	freeCacheClient := freecache.NewCache(MinFreeCacheSize)
+	index := newLockIndex(2)
+
+	for _, key := range []string{"lock-1", "lock-2", "lock-3"} {
+		_, err := writeLockFreeCache(freeCacheClient, key, testValue, 30)
+		require.NoError(t, err)
+		index.add(freeCacheClient, key)
+	}
+	assert.Len(t, index.keys, 3) // All are held
+
+	// Removed outside the client (ie: Delete)
+	freeCacheClient.Del([]byte("lock-1"))
+	freeCacheClient.Del([]byte("lock-2"))
+	_, err := writeLockFreeCache(freeCacheClient, "lock-4", testValue, 30)
+	require.NoError(t, err)
+	index.add(freeCacheClient, "lock-4")
+	assert.Len(t, index.keys, 2)
+
+	// Released outside the client
+	freeCacheClient.Del([]byte("lock-3"))
+	ttls := index.list(freeCacheClient)
+	assert.Len(t, ttls, 1)
+	assert.Equal(t, 30*time.Second, ttls["lock-4"])
+	assert.Len(t, index.keys, 1)
+
+	index.remove("lock-4")
+	assert.Empty(t, index.keys)
+}
+
+// Test_lockBackoff_delay will test the method delay()
+func Test_lockBackoff_delay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("default is a fixed delay", func(t *testing.T) {
+		backoff := defaultClientOptions().lockBackoff
+		for attempt := 0; attempt < 10; attempt++ {
+			assert.Equal(t, lockRetrySleepTime, backoff.delay(attempt))
+		}
+	})
+
+	t.Run("exponential up to the max delay", func(t *testing.T) {
+		backoff := lockBackoff{factor: 2, initial: 10 * time.Millisecond, maxDelay: 50 * time.Millisecond}
+		assert.Equal(t, 10*time.Millisecond, backoff.delay(0))
+		assert.Equal(t, 20*time.Millisecond, backoff.delay(1))
+		assert.Equal(t, 40*time.Millisecond, backoff.delay(2))
+		assert.Equal(t, 50*time.Millisecond, backoff.delay(3))
+		assert.Equal(t, 50*time.Millisecond, backoff.delay(1000))
+	})
+
+	t.Run("jitter is between half and the full delay", func(t *testing.T) {
+		backoff := lockBackoff{factor: 2, initial: 10 * time.Millisecond, jitter: true, maxDelay: time.Second}
+		for attempt := 0; attempt < 20; attempt++ {
+			expected := backoff
+			expected.jitter = false
+			delay := backoff.delay(attempt)
+			assert.GreaterOrEqual(t, delay, expected.delay(attempt)/2)
+			assert.LessOrEqual(t, delay, expected.delay(attempt))
+		}
+	})
+}
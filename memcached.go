@@ -0,0 +1,135 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// loadMemcachedClient will load the cache client (memcached)
+func loadMemcachedClient(
+	ctx context.Context,
+	config *MemcachedConfig,
+	newRelicEnabled bool,
+) (*memcache.Client, error) {
+
+	// Check for a config
+	if config == nil || len(config.Servers) == 0 {
+		return nil, ErrInvalidMemcachedConfig
+	}
+
+	// If NewRelic is enabled
+	if newRelicEnabled {
+		if txn := newrelic.FromContext(ctx); txn != nil {
+			segment := txn.StartSegment("load_memcached_client")
+			segment.AddAttribute("servers", strings.Join(config.Servers, ","))
+			defer segment.End()
+		}
+	}
+
+	// Resolve the servers (fails on invalid addresses)
+	servers := new(memcache.ServerList)
+	if err := servers.SetServers(config.Servers...); err != nil {
+		return nil, err
+	}
+
+	// Create the client
+	client := memcache.NewFromSelector(servers)
+	client.MaxIdleConns = config.MaxIdleConnections
+	client.Timeout = config.Timeout
+
+	// Fire a ping to make sure it works!
+	if err := client.Ping(); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// setMemcached will set the key->value with an optional TTL (zero is no expiration)
+func setMemcached(client *memcache.Client, key string, value []byte, ttl time.Duration) error {
+	return client.Set(&memcache.Item{
+		Expiration: memcachedExpiration(ttl),
+		Key:        key,
+		Value:      value,
+	})
+}
+
+// replaceMemcached will set the key->value only if the key exists (returns false if the key does not exist)
+func replaceMemcached(client *memcache.Client, key string, value []byte, ttl time.Duration) (bool, error) {
+	err := client.Replace(&memcache.Item{
+		Expiration: memcachedExpiration(ttl),
+		Key:        key,
+		Value:      value,
+	})
+	if errors.Is(err, memcache.ErrNotStored) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// getMemcached will return the value for the key (ErrKeyNotFound if missing)
+func getMemcached(client *memcache.Client, key string) ([]byte, error) {
+	item, err := client.Get(key)
+	if err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return nil, ErrKeyNotFound
+		}
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// deleteMemcached will remove the key (missing keys are ignored)
+func deleteMemcached(client *memcache.Client, key string) error {
+	if err := client.Delete(key); err != nil && !errors.Is(err, memcache.ErrCacheMiss) {
+		return err
+	}
+	return nil
+}
+
+// touchMemcached will update the expiration of an existing key (zero is no expiration)
+func touchMemcached(client *memcache.Client, key string, ttl time.Duration) error {
+	if err := client.Touch(key, memcachedExpiration(ttl)); err != nil {
+		if errors.Is(err, memcache.ErrCacheMiss) {
+			return ErrKeyNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getMultiMemcached will get several keys using a single request per server
+//
+// Keys that are not found are not included in the results
+func getMultiMemcached(client *memcache.Client, keys []string) (map[string]string, error) {
+	items, err := client.GetMulti(keys)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(items))
+	for key, item := range items {
+		values[key] = string(item.Value)
+	}
+	return values, nil
+}
+
+// memcachedExpiration will convert the TTL into the memcached expiration
+//
+// Memcached treats anything larger than 30 days as a unix timestamp
+func memcachedExpiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	} else if ttl > memcachedMaxRelativeTTL {
+		return int32(time.Now().Add(ttl).Unix())
+	}
+	if seconds := int32(ttl.Seconds()); seconds > 0 {
+		return seconds
+	}
+	return 1 // Less than a second would be stored without an expiration
+}
@@ -0,0 +1,653 @@
+package cachestore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memcachedTestItem is a stored value in the in-memory memcached server
+type memcachedTestItem struct {
+	expiresAt time.Time
+	flags     uint32
+	value     []byte
+}
+
+// memcachedTestServer is a minimal in-memory memcached server (text protocol) for testing
+type memcachedTestServer struct {
+	items    map[string]memcachedTestItem
+	listener net.Listener
+	mu       sync.Mutex
+}
+
+// loadMemcachedInMemoryServer will start an in-memory memcached server and return the address
+func loadMemcachedInMemoryServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &memcachedTestServer{
+		items:    make(map[string]memcachedTestItem),
+		listener: listener,
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	go func() {
+		for {
+			conn, acceptErr := listener.Accept()
+			if acceptErr != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// serve will handle the commands of a single connection
+func (s *memcachedTestServer) serve(conn net.Conn) {
+	defer func() {
+		_ = conn.Close()
+	}()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if err = s.handle(rw, fields); err != nil {
+			return
+		}
+		if err = rw.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// handle will run a single command
+func (s *memcachedTestServer) handle(rw *bufio.ReadWriter, fields []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch fields[0] {
+	case "version":
+		_, err := rw.WriteString("VERSION 1.6.0-test\r\n")
+		return err
+	case "get", "gets":
+		for _, key := range fields[1:] {
+			if item, ok := s.lookup(key); ok {
+				if _, err := fmt.Fprintf(rw, "VALUE %s %d %d 0\r\n%s\r\n",
+					key, item.flags, len(item.value), item.value); err != nil {
+					return err
+				}
+			}
+		}
+		_, err := rw.WriteString("END\r\n")
+		return err
+	case "set", "replace":
+		flags, _ := strconv.ParseUint(fields[2], 10, 32)
+		expiration, _ := strconv.ParseInt(fields[3], 10, 64)
+		size, _ := strconv.Atoi(fields[4])
+		value := make([]byte, size+2)
+		if _, err := io.ReadFull(rw, value); err != nil {
+			return err
+		}
+		if _, ok := s.lookup(fields[1]); !ok && fields[0] == "replace" {
+			_, err := rw.WriteString("NOT_STORED\r\n")
+			return err
+		}
+		s.items[fields[1]] = memcachedTestItem{
+			expiresAt: memcachedTestExpiresAt(expiration),
+			flags:     uint32(flags),
+			value:     value[:size],
+		}
+		_, err := rw.WriteString("STORED\r\n")
+		return err
+	case "delete":
+		if _, ok := s.lookup(fields[1]); !ok {
+			_, err := rw.WriteString("NOT_FOUND\r\n")
+			return err
+		}
+		delete(s.items, fields[1])
+		_, err := rw.WriteString("DELETED\r\n")
+		return err
+	case "touch":
+		item, ok := s.lookup(fields[1])
+		if !ok {
+			_, err := rw.WriteString("NOT_FOUND\r\n")
+			return err
+		}
+		expiration, _ := strconv.ParseInt(fields[2], 10, 64)
+		item.expiresAt = memcachedTestExpiresAt(expiration)
+		s.items[fields[1]] = item
+		_, err := rw.WriteString("TOUCHED\r\n")
+		return err
+	case "flush_all":
+		s.items = make(map[string]memcachedTestItem)
+		_, err := rw.WriteString("OK\r\n")
+		return err
+	}
+	_, err := rw.WriteString("ERROR\r\n")
+	return err
+}
+
+// lookup will return the item if found and not expired
+func (s *memcachedTestServer) lookup(key string) (memcachedTestItem, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return item, false
+	} else if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		delete(s.items, key)
+		return item, false
+	}
+	return item, true
+}
+
+// memcachedTestExpiresAt will convert the memcached expiration into a time
+func memcachedTestExpiresAt(expiration int64) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	} else if expiration > int64(memcachedMaxRelativeTTL.Seconds()) {
+		return time.Unix(expiration, 0)
+	}
+	return time.Now().Add(time.Duration(expiration) * time.Second)
+}
+
+// newMemcachedTestClient will return a new client using the in-memory memcached server
+func newMemcachedTestClient(t *testing.T, opts ...ClientOps) ClientInterface {
+	opts = append([]ClientOps{WithMemcached([]string{loadMemcachedInMemoryServer(t)})}, opts...)
+	c, err := NewClient(context.Background(), opts...)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c
+}
+
+// Test_loadMemcachedClient will test the method loadMemcachedClient()
+func Test_loadMemcachedClient(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no config set", func(t *testing.T) {
+		c, err := loadMemcachedClient(context.Background(), nil, false)
+		require.Nil(t, c)
+		require.ErrorIs(t, err, ErrInvalidMemcachedConfig)
+	})
+
+	t.Run("no servers set", func(t *testing.T) {
+		c, err := loadMemcachedClient(context.Background(), &MemcachedConfig{}, false)
+		require.Nil(t, c)
+		require.ErrorIs(t, err, ErrInvalidMemcachedConfig)
+	})
+
+	t.Run("server is not running", func(t *testing.T) {
+		c, err := loadMemcachedClient(context.Background(), &MemcachedConfig{
+			Servers: []string{"127.0.0.1:1"},
+			Timeout: DefaultMemcachedTimeout,
+		}, false)
+		require.Nil(t, c)
+		require.Error(t, err)
+	})
+
+	t.Run("valid server", func(t *testing.T) {
+		c, err := loadMemcachedClient(getNewRelicCtx(t, testAppName, testTxn), &MemcachedConfig{
+			MaxIdleConnections: DefaultMemcachedMaxIdleConnections,
+			Servers:            []string{loadMemcachedInMemoryServer(t)},
+			Timeout:            DefaultMemcachedTimeout,
+		}, true)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, DefaultMemcachedTimeout, c.Timeout)
+		_ = c.Close()
+	})
+}
+
+// Test_memcachedExpiration will test the method memcachedExpiration()
+func Test_memcachedExpiration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no expiration", func(t *testing.T) {
+		assert.Equal(t, int32(0), memcachedExpiration(0))
+		assert.Equal(t, int32(0), memcachedExpiration(-1*time.Second))
+	})
+
+	t.Run("relative expiration", func(t *testing.T) {
+		assert.Equal(t, int32(1), memcachedExpiration(100*time.Millisecond))
+		assert.Equal(t, int32(60), memcachedExpiration(time.Minute))
+	})
+
+	t.Run("unix timestamp over 30 days", func(t *testing.T) {
+		expiration := memcachedExpiration(60 * 24 * time.Hour)
+		assert.Greater(t, int64(expiration), time.Now().Unix())
+	})
+}
+
+// TestClient_Memcached will test the cache methods using the memcached engine
+func TestClient_Memcached(t *testing.T) {
+
+	t.Run("engine and connection", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		assert.Equal(t, Memcached, c.Engine())
+		assert.NotNil(t, c.Memcached())
+		require.NotNil(t, c.MemcachedConfig())
+		assert.Equal(t, DefaultMemcachedMaxIdleConnections, c.MemcachedConfig().MaxIdleConnections)
+
+		c.Close(context.Background())
+		assert.Nil(t, c.Memcached())
+		assert.Equal(t, Empty, c.Engine())
+	})
+
+	t.Run("set, get and delete", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.Set(context.Background(), testKey, testValue, "dependency")
+		require.NoError(t, err)
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+
+		var found bool
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		err = c.Delete(context.Background(), testKey)
+		require.NoError(t, err)
+
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "", val)
+
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		// Deleting a missing key is not an error
+		err = c.Delete(context.Background(), testKey)
+		require.NoError(t, err)
+	})
+
+	t.Run("set ttl and touch", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.SetTTL(context.Background(), testKey, testValue, 1*time.Second)
+		require.NoError(t, err)
+
+		err = c.Touch(context.Background(), testKey, 0)
+		require.NoError(t, err)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+
+		err = c.Touch(context.Background(), testKey+"-missing", time.Minute)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("set ttl expires", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.SetTTL(context.Background(), testKey, testValue, 1*time.Second)
+		require.NoError(t, err)
+
+		time.Sleep(1100 * time.Millisecond)
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "", val)
+	})
+
+	t.Run("set and get model", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithCompression(CompressionGzip), WithCompressionThreshold(0))
+
+		model := &genericStruct{StringField: testValue, IntField: 123}
+		err := c.SetModel(context.Background(), testKey, model, time.Minute)
+		require.NoError(t, err)
+
+		result := new(genericStruct)
+		err = c.GetModel(context.Background(), testKey, result)
+		require.NoError(t, err)
+		assert.Equal(t, model, result)
+
+		err = c.GetModel(context.Background(), testKey+"-missing", result)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("replace model", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		replaced, err := c.ReplaceModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+		require.NoError(t, err)
+		assert.False(t, replaced)
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: "old"}, 0))
+		replaced, err = c.ReplaceModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+		require.NoError(t, err)
+		assert.True(t, replaced)
+
+		model := new(genericStruct)
+		require.NoError(t, c.GetModel(context.Background(), testKey, model))
+		assert.Equal(t, testValue, model.StringField)
+	})
+
+	t.Run("set model multi", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.SetModelMulti(context.Background(), map[string]interface{}{
+			testKey + "1": &genericStruct{StringField: testValue + "1"},
+			testKey + "2": &genericStruct{StringField: testValue + "2"},
+		}, time.Minute)
+		require.NoError(t, err)
+
+		model := new(genericStruct)
+		require.NoError(t, c.GetModel(context.Background(), testKey+"2", model))
+		assert.Equal(t, testValue+"2", model.StringField)
+	})
+
+	t.Run("lock admin and handles", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, err := c.ListLocks(context.Background())
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.ForceReleaseLock(context.Background(), testKey)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.AcquireLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("get and refresh", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		_, err := c.GetAndRefresh(context.Background(), testKey, time.Minute)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		require.ErrorIs(t, c.Flush(context.Background()), ErrUnsafeFlush)
+
+		c = newMemcachedTestClient(t, WithKeyPrefix("app:"))
+		require.ErrorIs(t, c.Flush(context.Background()), ErrEngineNotSupported)
+	})
+
+	t.Run("max value size", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithMaxValueSize(4))
+
+		require.NoError(t, c.Set(context.Background(), testKey, "1234"))
+		require.ErrorIs(t, c.Set(context.Background(), testKey, "12345"), ErrValueTooLarge)
+
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "1234", value)
+	})
+
+	t.Run("strict misses", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithStrictMisses())
+
+		require.NoError(t, c.Set(context.Background(), testKey, ""))
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "", value)
+
+		_, err = c.Get(context.Background(), testKey+"-missing")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("set model if changed", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		changed, err := c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		changed, err = c.SetModelIfChanged(context.Background(), testKey, &genericStruct{StringField: testValue}, 0)
+		require.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("set and get bytes", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		value := []byte{0x00, 0xff, 0x00, 'a', 0x10}
+		err := c.SetBytes(context.Background(), testKey, value)
+		require.NoError(t, err)
+
+		var result []byte
+		result, err = c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, value, result)
+
+		_, err = c.GetBytes(context.Background(), testKey+"-missing")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("get or set (the loader lock is skipped)", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithLoaderLock(5*time.Second))
+
+		value, err := c.GetOrSet(context.Background(), testKey, time.Minute, func(context.Context) (string, error) {
+			return testValue, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+	})
+
+	t.Run("set and get multi", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithKeyPrefix("prefix:"))
+
+		err := c.SetMulti(context.Background(), map[string]string{
+			testKey + "1": testValue + "1",
+			testKey + "2": testValue + "2",
+		})
+		require.NoError(t, err)
+
+		var values map[string]string
+		values, err = c.GetMulti(context.Background(), testKey+"1", testKey+"2", testKey+"3")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			testKey + "1": testValue + "1",
+			testKey + "2": testValue + "2",
+		}, values)
+	})
+
+	t.Run("delete many", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.SetMulti(context.Background(), map[string]string{
+			testKey + "1": testValue + "1",
+			testKey + "2": testValue + "2",
+		})
+		require.NoError(t, err)
+
+		err = c.DeleteMany(context.Background(), testKey+"1", testKey+"2", testKey+"3")
+		require.NoError(t, err)
+
+		var values map[string]string
+		values, err = c.GetMulti(context.Background(), testKey+"1", testKey+"2")
+		require.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("delete model", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.SetModel(context.Background(), testKey, &genericStruct{}, 0, "user")
+		require.NoError(t, err)
+
+		err = c.DeleteModel(context.Background(), testKey)
+		require.NoError(t, err)
+
+		var found bool
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("pipeline (one by one)", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		var get, getDeleted *PipelineCmd
+		err := c.Pipeline(context.Background(), func(p Pipeliner) error {
+			p.Set(testKey+"1", testValue, time.Minute)
+			p.Set(testKey+"2", testValue, 0)
+			get = p.Get(testKey + "1")
+			p.Delete(testKey + "2")
+			getDeleted = p.Get(testKey + "2")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testValue, get.Value())
+		assert.False(t, getDeleted.Found())
+	})
+
+	t.Run("empty cache", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+
+		err = c.EmptyCache(context.Background())
+		require.NoError(t, err)
+
+		var found bool
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("empty cache with a key prefix is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t, WithKeyPrefix("prefix:"))
+		err := c.EmptyCache(context.Background())
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("delete by pattern is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+		_, err := c.DeleteByPattern(context.Background(), testKey+"*")
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("get model with ttl is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, time.Minute))
+
+		_, err := c.GetModelWithTTL(context.Background(), testKey, new(genericStruct))
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("dependencies are not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		require.ErrorIs(t, c.DeleteDependency(context.Background(), "user"), ErrDependenciesNotSupported)
+	})
+
+	t.Run("set and get int", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		require.NoError(t, c.SetInt(context.Background(), testKey, 42, 0))
+		n, err := c.GetInt(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), n)
+
+		_, err = c.GetInt(context.Background(), testKey+"-missing")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("set and get time and duration", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		tm := time.Date(2024, 2, 29, 13, 14, 15, 123456789, time.UTC)
+		require.NoError(t, c.SetTime(context.Background(), testKey, tm, 0))
+		stored, err := c.GetTime(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, tm.Equal(stored))
+
+		require.NoError(t, c.SetDuration(context.Background(), testKey, -time.Minute, 0))
+		var d time.Duration
+		d, err = c.GetDuration(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, -time.Minute, d)
+	})
+
+	t.Run("counters are not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, err := c.Increment(context.Background(), testKey, 1)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.Decrement(context.Background(), testKey, 1)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("append is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, err := c.Append(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("get set is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, _, err := c.GetSet(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("compare and swap is not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, err := c.CompareAndSwap(context.Background(), testKey, testValue, testValue, 0)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+
+	t.Run("locks are not supported", func(t *testing.T) {
+		c := newMemcachedTestClient(t)
+
+		_, err := c.WriteLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.WriteLockWithSecret(context.Background(), testKey, testValue, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.WaitWriteLock(context.Background(), testKey, 30, 1)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, _, err = c.TryWriteLock(context.Background(), testKey, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.ExtendLock(context.Background(), testKey, testValue, 30)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+
+		_, err = c.ReleaseLock(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+	})
+}
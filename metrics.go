@@ -0,0 +1,160 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Operations (metric labels)
+const (
+	operationCompareAndSwap    = "compare_and_swap"
+	operationDelete            = "delete"
+	operationDeleteByPattern   = "delete_by_pattern"
+	operationDeleteDependency  = "delete_dependency"
+	operationDeleteMany        = "delete_many"
+	operationDeleteModel       = "delete_model"
+	operationExtendLock        = "extend_lock"
+	operationGet               = "get"
+	operationForceReleaseLock  = "force_release_lock"
+	operationGetAndRefresh     = "get_and_refresh"
+	operationGetBytes          = "get_bytes"
+	operationGetDuration       = "get_duration"
+	operationGetInt            = "get_int"
+	operationGetModel          = "get_model"
+	operationGetModelMulti     = "get_model_multi"
+	operationGetModelWithTTL   = "get_model_with_ttl"
+	operationGetOrSet          = "get_or_set"
+	operationGetOrSetModel     = "get_or_set_model"
+	operationGetMulti          = "get_multi"
+	operationGetRaw            = "get_raw"
+	operationGetTime           = "get_time"
+	operationListLocks         = "list_locks"
+	operationPipeline          = "pipeline"
+	operationReleaseLock       = "release_lock"
+	operationReplaceModel      = "replace_model"
+	operationScan              = "scan"
+	operationSet               = "set"
+	operationSetBytes          = "set_bytes"
+	operationSetDuration       = "set_duration"
+	operationSetInt            = "set_int"
+	operationSetModel          = "set_model"
+	operationSetModelIfChanged = "set_model_if_changed"
+	operationSetModelMulti     = "set_model_multi"
+	operationSetMulti          = "set_multi"
+	operationSetTime           = "set_time"
+	operationSetTTL            = "set_ttl"
+	operationTryWriteLock      = "try_write_lock"
+	operationWaitWriteLock     = "wait_write_lock"
+	operationWriteLock         = "write_lock"
+	operationWriteLockMany     = "write_lock_many"
+)
+
+// Results (metric labels)
+const (
+	resultError   = "error"
+	resultHit     = "hit"
+	resultMiss    = "miss"
+	resultSuccess = "success"
+)
+
+// metrics are the Prometheus collectors for the cache operations
+type metrics struct {
+	duration   *prometheus.HistogramVec // Latency by engine and operation
+	operations *prometheus.CounterVec   // Operations by engine, operation and result
+}
+
+// newMetrics will create and register the collectors
+//
+// Collectors already registered (another client using the same registerer) are shared
+func newMetrics(registerer prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "cachestore",
+			Name:      "operation_duration_seconds",
+			Help:      "Latency of the cache operations by engine and operation.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16), // 100µs to ~3.3s
+		}, []string{"engine", "operation"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "cachestore",
+			Name:      "operations_total",
+			Help:      "Total cache operations by engine, operation and result.",
+		}, []string{"engine", "operation", "result"}),
+	}
+
+	var err error
+	if m.duration, err = registerCollector(registerer, m.duration); err != nil {
+		return nil, err
+	}
+	if m.operations, err = registerCollector(registerer, m.operations); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// registerCollector will register the collector, or return the existing collector if already registered
+func registerCollector[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	err := registerer.Register(collector)
+	if err == nil {
+		return collector, nil
+	}
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing, nil
+		}
+	}
+	return collector, err
+}
+
+// observe will update the metrics for the operation and record the event into the context (if enabled)
+//
+// The key is the original key (without the key prefix), empty for the multi key operations
+func (c *Client) observe(ctx context.Context, operation, key string, start time.Time, result string) {
+	duration := time.Since(start)
+	if c.options.observabilityContext {
+		recordEvent(ctx, CacheEvent{Duration: duration, Key: key, Operation: operation, Result: result})
+	}
+	if c.options.metrics == nil {
+		return
+	}
+	engine := c.Engine().String()
+	c.options.metrics.operations.WithLabelValues(engine, operation, result).Inc()
+	c.options.metrics.duration.WithLabelValues(engine, operation).Observe(duration.Seconds())
+}
+
+// readResult will return the result of a read (hit, miss or error)
+func readResult(found bool, err error) string {
+	if err != nil {
+		return resultError
+	} else if found {
+		return resultHit
+	}
+	return resultMiss
+}
+
+// modelResult will return the result of reading a model (ErrKeyNotFound is a miss)
+func modelResult(err error) string {
+	if errors.Is(err, ErrKeyNotFound) {
+		return resultMiss
+	}
+	return readResult(true, err)
+}
+
+// replaceResult will return the result of a replace (a missing key is a miss)
+func replaceResult(replaced bool, err error) string {
+	if err == nil && !replaced {
+		return resultMiss
+	}
+	return writeResult(err)
+}
+
+// writeResult will return the result of a write (success or error)
+func writeResult(err error) string {
+	if err != nil {
+		return resultError
+	}
+	return resultSuccess
+}
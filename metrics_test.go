@@ -0,0 +1,157 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatherOperations will scrape the registry and return the operations counter for the labels
+func gatherOperations(t *testing.T, registry *prometheus.Registry, engine Engine, operation, result string) float64 {
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "cachestore_operations_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["engine"] == engine.String() && labels["operation"] == operation && labels["result"] == result {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+// Test_newMetrics will test the method newMetrics()
+func Test_newMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("register the collectors", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		m, err := newMetrics(registry)
+		require.NoError(t, err)
+		require.NotNil(t, m)
+
+		m.operations.WithLabelValues(Redis.String(), operationGet, resultHit).Inc()
+		m.duration.WithLabelValues(Redis.String(), operationGet).Observe(0.001)
+
+		var count int
+		count, err = testutil.GatherAndCount(registry, "cachestore_operations_total", "cachestore_operation_duration_seconds")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("collectors are shared (already registered)", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		first, err := newMetrics(registry)
+		require.NoError(t, err)
+
+		var second *metrics
+		second, err = newMetrics(registry)
+		require.NoError(t, err)
+		assert.Same(t, first.operations, second.operations)
+		assert.Same(t, first.duration, second.duration)
+	})
+
+	t.Run("conflicting collector", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachestore",
+			Name:      "operations_total",
+			Help:      "Conflicting counter without labels.",
+		}))
+
+		m, err := newMetrics(registry)
+		require.Error(t, err)
+		assert.Nil(t, m)
+	})
+}
+
+// TestClient_Metrics will test the Prometheus metrics for the cache operations
+func TestClient_Metrics(t *testing.T) {
+
+	t.Run("conflicting collector", func(t *testing.T) {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "cachestore",
+			Name:      "operations_total",
+			Help:      "Conflicting counter without labels.",
+		}))
+
+		c, err := NewClient(context.Background(), WithFreeCache(), WithPrometheus(registry))
+		require.ErrorIs(t, err, ErrMetricsRegistration)
+		assert.Nil(t, c)
+	})
+
+	t.Run("no metrics by default", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+	})
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - scrape the registry", func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase), WithPrometheus(registry))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			// A second client shares the collectors
+			var other ClientInterface
+			other, err = NewClient(context.Background(), WithFreeCache(), WithPrometheus(registry))
+			require.NoError(t, err)
+			defer other.Close(context.Background())
+
+			// Sets, hits, misses and errors
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			require.NoError(t, other.SetTTL(context.Background(), testKey, testValue, time.Minute))
+			_, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			_, err = c.Get(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			_, err = c.Get(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey+"-missing", &genericStruct{}), ErrKeyNotFound)
+			require.NoError(t, c.Delete(context.Background(), testKey))
+
+			// Locks
+			var secret string
+			secret, err = c.WriteLock(context.Background(), testKey+"-lock", 30)
+			require.NoError(t, err)
+			var released bool
+			released, err = c.ReleaseLock(context.Background(), testKey+"-lock", secret)
+			require.NoError(t, err)
+			assert.True(t, released)
+
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationSet, resultSuccess), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, FreeCache, operationSetTTL, resultSuccess), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationGet, resultHit), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationGet, resultMiss), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationGet, resultError), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationGetModel, resultMiss), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationDelete, resultSuccess), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationWriteLock, resultSuccess), 0)
+			assert.InDelta(t, 1, gatherOperations(t, registry, testCase.engine, operationReleaseLock, resultSuccess), 0)
+
+			// Latency is recorded for every operation
+			var count int
+			count, err = testutil.GatherAndCount(registry, "cachestore_operation_duration_seconds")
+			require.NoError(t, err)
+			assert.Positive(t, count)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package cachestore
+
+import (
+	"context"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// newRelicProduct is the NewRelic datastore product of the cachestore segments (the engine is the collection)
+const newRelicProduct newrelic.DatastoreProduct = "Cachestore"
+
+// startSegment will start a NewRelic datastore segment for the operation (if enabled and the ctx has a txn)
+//
+// Returns nil if no segment was started (End is safe to call on nil), the segment is tagged with the engine
+// NOTE: the redis commands also record their own segments (Redis product) when NewRelic is enabled
+func (c *Client) startSegment(ctx context.Context, operation string) *newrelic.DatastoreSegment {
+	if !c.options.newRelicEnabled {
+		return nil
+	}
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		return nil
+	}
+	engine := c.Engine().String()
+	segment := &newrelic.DatastoreSegment{
+		Collection: engine,
+		Operation:  operation,
+		Product:    newRelicProduct,
+		StartTime:  txn.StartSegmentNow(),
+	}
+	segment.AddAttribute("engine", engine)
+	return segment
+}
@@ -0,0 +1,81 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_startSegment will test the method startSegment()
+func TestClient_startSegment(t *testing.T) {
+
+	t.Run("newrelic is not enabled", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+
+		assert.Nil(t, c.(*Client).startSegment(getNewRelicCtx(t, testAppName, testTxn), operationGet))
+	})
+
+	t.Run("no txn in the context", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithNewRelic())
+		require.NoError(t, err)
+
+		segment := c.(*Client).startSegment(context.Background(), operationGet)
+		assert.Nil(t, segment)
+		segment.End() // Safe on nil
+	})
+
+	t.Run("datastore segment is started", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithNewRelic())
+		require.NoError(t, err)
+
+		segment := c.(*Client).startSegment(getNewRelicCtx(t, testAppName, testTxn), operationGet)
+		require.NotNil(t, segment)
+		assert.Equal(t, newRelicProduct, segment.Product)
+		assert.Equal(t, FreeCache.String(), segment.Collection)
+		assert.Equal(t, operationGet, segment.Operation)
+		assert.NotEqual(t, newrelic.SegmentStartTime{}, segment.StartTime)
+		segment.End()
+	})
+}
+
+// TestClient_NewRelicSegments will test the operations using a NewRelic txn (segments are ended on all paths)
+func TestClient_NewRelicSegments(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - operations record segments", func(t *testing.T) {
+			ctx := getNewRelicCtx(t, testAppName, testTxn)
+			c, err := NewClient(ctx, testCase.opts, WithNewRelic())
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(ctx, testKey, testValue))
+			var value string
+			value, err = c.Get(ctx, testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			require.NoError(t, c.SetModel(ctx, testKey, &genericStruct{StringField: testValue}, 0))
+			require.NoError(t, c.GetModel(ctx, testKey, &genericStruct{}))
+			require.NoError(t, c.Delete(ctx, testKey))
+
+			var secret string
+			secret, err = c.WriteLock(ctx, testKey, 30)
+			require.NoError(t, err)
+			_, err = c.ReleaseLock(ctx, testKey, secret)
+			require.NoError(t, err)
+
+			// Error paths
+			_, err = c.Get(ctx, "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+			require.ErrorIs(t, c.GetModel(ctx, testKey, &genericStruct{}), ErrKeyNotFound)
+		})
+	}
+}
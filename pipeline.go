@@ -0,0 +1,270 @@
+package cachestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/mrz1836/go-cache"
+)
+
+// Pipeliner buffers the commands of a pipeline (see: Pipeline)
+//
+// The commands are not sent until the pipeline function returns, read the results after Pipeline returns
+type Pipeliner interface {
+	Delete(key string) *PipelineCmd
+	Get(key string) *PipelineCmd
+	Set(key string, value interface{}, ttl time.Duration) *PipelineCmd
+}
+
+// PipelineCmd is a buffered command of a pipeline, the result is set once the pipeline is flushed
+type PipelineCmd struct {
+	args      []interface{} // Arguments of the redis command
+	command   string        // Redis command (GET, SET or DEL)
+	err       error         // Error of the command (nil if successful)
+	found     bool          // If the key was found (Get)
+	key       string        // Original key (without the key prefix)
+	operation string        // Operation of the command (get, set_ttl or delete)
+	result    string        // Value read (Get)
+	ttl       time.Duration // Expiration of the value (Set)
+	value     interface{}   // Value to set (Set)
+}
+
+// Err will return the error of the command (nil if successful, or not flushed)
+func (p *PipelineCmd) Err() error {
+	return p.err
+}
+
+// Found will return true if the key was found (Get)
+func (p *PipelineCmd) Found() bool {
+	return p.found
+}
+
+// Value will return the value read (Get), empty if the key was not found
+func (p *PipelineCmd) Value() string {
+	return p.result
+}
+
+// pipeline is the Pipeliner of a client (buffers the commands in order)
+type pipeline struct {
+	client *Client
+	cmds   []*PipelineCmd
+}
+
+// Delete will buffer removing the key
+func (p *pipeline) Delete(key string) *PipelineCmd {
+	cmd := &PipelineCmd{command: cache.DeleteCommand, key: key, operation: operationDelete}
+	return p.add(cmd, key)
+}
+
+// Get will buffer reading the key (a missing key is not an error, see: PipelineCmd.Found)
+func (p *pipeline) Get(key string) *PipelineCmd {
+	cmd := &PipelineCmd{command: cache.GetCommand, key: key, operation: operationGet}
+	return p.add(cmd, key)
+}
+
+// Set will buffer setting the key->value
+//
+// A zero TTL uses the default TTL (see: WithDefaultTTL), otherwise the value never expires
+func (p *pipeline) Set(key string, value interface{}, ttl time.Duration) *PipelineCmd {
+	cmd := &PipelineCmd{
+		command: cache.SetCommand, key: key, operation: operationSetTTL,
+		ttl: p.client.ttlOrDefault(ttl), value: value,
+	}
+	return p.add(cmd, key)
+}
+
+// add will build the redis command and buffer it (an invalid key or value is set as the error of the command)
+func (p *pipeline) add(cmd *PipelineCmd, key string) *PipelineCmd {
+	p.cmds = append(p.cmds, cmd)
+	if key, cmd.err = p.client.buildKey(key); cmd.err != nil {
+		return cmd
+	}
+	cmd.args = []interface{}{key}
+	if cmd.command != cache.SetCommand {
+		return cmd
+	}
+
+	// Compress the value (if enabled) and check the size
+	var encoded interface{}
+	if encoded, cmd.err = p.client.encodeValue(cmd.value); cmd.err != nil {
+		return cmd
+	} else if cmd.err = p.client.checkValueSize(valueToBytes(encoded)); cmd.err != nil {
+		return cmd
+	}
+	cmd.args = append(cmd.args, encoded)
+	if cmd.ttl > 0 {
+		cmd.args = append(cmd.args, setExpireOption, cmd.ttl.Milliseconds())
+	}
+	return cmd
+}
+
+// Pipeline will buffer the commands of the function and send them in a single round trip (redis)
+//
+// The commands are sent in the order they were buffered (a Get after a Set of the same key reads the new value),
+// the results are set on each command (see: PipelineCmd) once Pipeline returns
+// Nothing is sent if the function returns an error, the error is returned
+// The pipeline is not atomic: a failed command does not stop the others (partial failure), the first
+// error of a command is returned, check each command for its own error (a missing key is not an error)
+// A command with an invalid key or value fails without being sent (ie: ErrKeyRequired)
+// FreeCache, memcached and a redis cluster run the commands one by one (in order)
+// NOTE: the local tier (see: WithTieredCache) is updated by Set and Delete, Get always reads from redis
+func (c *Client) Pipeline(ctx context.Context, fn func(p Pipeliner) error) (err error) {
+
+	// Record a NewRelic datastore segment (if enabled)
+	defer c.startSegment(ctx, operationPipeline).End()
+
+	// Add the operation, key and engine to the error
+	defer func() {
+		err = c.wrapError(operationPipeline, "", err)
+	}()
+
+	// Update the metrics
+	start := time.Now()
+	defer func() {
+		c.observe(ctx, operationPipeline, "", start, writeResult(err))
+	}()
+
+	// Return a panic as an error (if enabled, see: WithPanicRecovery)
+	defer c.recoverPanic(&err)
+
+	// Require the function
+	if fn == nil {
+		return ErrPipelineRequired
+	}
+
+	// Buffer the commands
+	p := &pipeline{client: c}
+	if err = fn(p); err != nil {
+		return err
+	} else if len(p.cmds) == 0 {
+		return nil
+	}
+
+	// Limit the operation to the timeout (see: WithOperationTimeout)
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// Stop if the context is done
+	if err = checkContext(ctx); err != nil {
+		return err
+	}
+
+	// Redis (a single round trip)
+	if c.Engine().usesRedis() && !isRedisCluster(c.options.redis) {
+		if err = c.flushPipelineRedis(ctx, p.cmds); err != nil {
+			for _, cmd := range p.cmds {
+				if cmd.err == nil {
+					cmd.err = err
+				}
+			}
+			return err
+		}
+		return firstPipelineError(p.cmds)
+	}
+
+	// FreeCache, memcached and a redis cluster (one by one)
+	for _, cmd := range p.cmds {
+		if cmd.err == nil {
+			c.runPipelineCmd(ctx, cmd)
+		}
+	}
+	return firstPipelineError(p.cmds)
+}
+
+// flushPipelineRedis will send the commands in a single round trip and set the replies on the commands
+//
+// Returns an error if the commands cannot be sent (connection), an error reading the replies is set on all the commands
+func (c *Client) flushPipelineRedis(ctx context.Context, cmds []*PipelineCmd) error {
+	conn, err := c.options.redis.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.options.redis.CloseConnection(conn)
+
+	// Queue the valid commands
+	sent := make([]*PipelineCmd, 0, len(cmds))
+	for _, cmd := range cmds {
+		if cmd.err != nil {
+			continue
+		} else if err = conn.Send(cmd.command, cmd.args...); err != nil {
+			return err
+		}
+		sent = append(sent, cmd)
+	}
+
+	// Flush the pipeline and read all the replies
+	replies, err := redis.Values(conn.Do(""))
+	for i, cmd := range sent {
+		if err != nil {
+			cmd.err = err
+		} else {
+			c.setPipelineReply(cmd, replies[i])
+		}
+		c.onPipelineCmd(cmd)
+	}
+	return nil
+}
+
+// setPipelineReply will set the result of the command from the redis reply
+func (c *Client) setPipelineReply(cmd *PipelineCmd, reply interface{}) {
+	if replyErr, ok := reply.(redis.Error); ok {
+		cmd.err = replyErr
+		return
+	}
+	switch cmd.command {
+	case cache.GetCommand:
+		if reply == nil { // A nil reply is a missing key
+			return
+		}
+		var str string
+		if str, cmd.err = redis.String(reply, nil); cmd.err != nil {
+			return
+		}
+		cmd.found = true
+		cmd.result, cmd.err = c.decodeString(str)
+	case cache.SetCommand:
+		c.setFallback(cmd.args[0].(string), valueToBytes(cmd.args[1]), cmd.ttl)
+		c.setLocal(cmd.args[0].(string), valueToBytes(cmd.args[1]), cmd.ttl)
+	default:
+		c.deleteFallback(cmd.args[0].(string))
+		c.deleteLocal(cmd.args[0].(string))
+	}
+}
+
+// onPipelineCmd will update the statistics and run the hooks for a command sent to redis
+func (c *Client) onPipelineCmd(cmd *PipelineCmd) {
+	switch cmd.command {
+	case cache.GetCommand:
+		c.options.stats.read(cmd.found, cmd.err)
+		c.onRead(cmd.operation, cmd.key, cmd.found, cmd.err)
+	case cache.SetCommand:
+		c.options.stats.stored(1, cmd.err)
+		c.onSet(cmd.operation, cmd.key, cmd.err)
+	default:
+		c.options.stats.deleted(1, cmd.err)
+		c.onError(cmd.operation, cmd.key, cmd.err)
+	}
+}
+
+// runPipelineCmd will run the command as a regular operation (FreeCache, memcached and a redis cluster)
+func (c *Client) runPipelineCmd(ctx context.Context, cmd *PipelineCmd) {
+	switch cmd.command {
+	case cache.GetCommand:
+		cmd.result, cmd.found, cmd.err = c.get(ctx, operationGet, cmd.key)
+	case cache.SetCommand:
+		cmd.err = c.SetTTL(ctx, cmd.key, cmd.value, cmd.ttl)
+	default:
+		cmd.err = c.Delete(ctx, cmd.key)
+	}
+}
+
+// firstPipelineError will return the first error of the commands (in order)
+func firstPipelineError(cmds []*PipelineCmd) error {
+	for _, cmd := range cmds {
+		if cmd.err != nil {
+			return cmd.err
+		}
+	}
+	return nil
+}
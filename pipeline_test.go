@@ -0,0 +1,256 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mrz1836/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_Pipeline will test the method Pipeline()
+func TestClient_Pipeline(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - set, get and delete in order", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+			require.NoError(t, c.Set(context.Background(), testKey+"-2", testValue+"-2"))
+
+			var get1, get2, getMissing, getDeleted *PipelineCmd
+			err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+				p.Set(testKey+"-1", testValue+"-1", time.Minute)
+				get1 = p.Get(testKey + "-1")
+				get2 = p.Get(testKey + "-2")
+				getMissing = p.Get(testKey + "-missing")
+				p.Delete(testKey + "-2")
+				getDeleted = p.Get(testKey + "-2")
+				return nil
+			})
+			require.NoError(t, err)
+
+			assert.True(t, get1.Found())
+			assert.Equal(t, testValue+"-1", get1.Value())
+			assert.True(t, get2.Found())
+			assert.Equal(t, testValue+"-2", get2.Value())
+			for _, cmd := range []*PipelineCmd{getMissing, getDeleted} {
+				require.NoError(t, cmd.Err())
+				assert.False(t, cmd.Found())
+				assert.Empty(t, cmd.Value())
+			}
+
+			// The values are stored
+			var value string
+			value, err = c.Get(context.Background(), testKey+"-1")
+			require.NoError(t, err)
+			assert.Equal(t, testValue+"-1", value)
+
+			stats := c.Stats()
+			assert.Equal(t, int64(2), stats.Sets)
+			assert.Equal(t, int64(1), stats.Deletes)
+
+			// The TTL is set
+			testCase.FastForward(2 * time.Minute)
+			if testCase.engine == Redis {
+				assert.False(t, testCase.redis.Exists(testKey+"-1"))
+			}
+		})
+
+		t.Run(testCase.name+" - partial failure", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			var invalid, set *PipelineCmd
+			err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+				invalid = p.Set("  ", testValue, 0)
+				set = p.Set(testKey, testValue, 0)
+				return nil
+			})
+			require.ErrorIs(t, err, ErrKeyRequired)
+			require.ErrorIs(t, invalid.Err(), ErrKeyRequired)
+			require.NoError(t, set.Err())
+
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+		})
+
+		t.Run(testCase.name+" - nothing is sent if the function fails", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			fnErr := errors.New("function failed")
+			err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+				p.Set(testKey, testValue, 0)
+				return fnErr
+			})
+			require.ErrorIs(t, err, fnErr)
+
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+
+	t.Run("function is required", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.ErrorIs(t, c.Pipeline(context.Background(), nil), ErrPipelineRequired)
+		require.NoError(t, c.Pipeline(context.Background(), func(Pipeliner) error { return nil }))
+	})
+
+	t.Run("["+Redis.String()+"] [mock] - a single flush", func(t *testing.T) {
+		c, conn := newMockRedisClient(t)
+
+		setCmd := conn.Command(cache.SetCommand, testKey+"-1", testValue, setExpireOption, int64(60000)).Expect("OK")
+		getCmd := conn.Command(cache.GetCommand, testKey+"-2").Expect([]byte(testValue))
+		delCmd := conn.Command(cache.DeleteCommand, testKey+"-3").Expect(int64(1))
+
+		var flushes int
+		conn.FlushSkippableMock = func() error {
+			flushes++
+			return nil
+		}
+
+		var get *PipelineCmd
+		err := c.Pipeline(context.Background(), func(p Pipeliner) error {
+			p.Set(testKey+"-1", testValue, time.Minute)
+			get = p.Get(testKey + "-2")
+			p.Delete(testKey + "-3")
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, flushes) // The pipeline, and returning the connection to the pool (nothing queued)
+		assert.True(t, setCmd.Called)
+		assert.True(t, getCmd.Called)
+		assert.True(t, delCmd.Called)
+		assert.Equal(t, testValue, get.Value())
+	})
+
+	t.Run("["+Redis.String()+"] - an error reply only fails the command", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithKeyPrefix("app:"))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		_, err = r.SetAdd("app:"+testKey+"-set", testValue)
+		require.NoError(t, err)
+
+		var wrongType, set *PipelineCmd
+		err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+			wrongType = p.Get(testKey + "-set")
+			set = p.Set(testKey, testValue, 0)
+			return nil
+		})
+		require.Error(t, err)
+		require.ErrorIs(t, err, wrongType.Err())
+		assert.Contains(t, err.Error(), "WRONGTYPE")
+		require.NoError(t, set.Err())
+
+		value, err := r.Get("app:" + testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+	})
+
+	t.Run("["+Redis.String()+"] - connection error", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+		r.Close()
+
+		var get *PipelineCmd
+		err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+			get = p.Get(testKey)
+			return nil
+		})
+		require.Error(t, err)
+		require.Error(t, get.Err())
+	})
+
+	t.Run("["+Redis.String()+"] [tiered] - set and delete update the local tier", func(t *testing.T) {
+		c, _, local := newTieredTestClient(t)
+		require.NoError(t, c.Set(context.Background(), testKey+"-2", testValue))
+		_, err := local.Get([]byte(testKey + "-2"))
+		require.NoError(t, err)
+
+		err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+			p.Set(testKey+"-1", testValue, 0)
+			p.Delete(testKey + "-2")
+			return nil
+		})
+		require.NoError(t, err)
+
+		value, err := local.Get([]byte(testKey + "-1"))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(value))
+		_, err = local.Get([]byte(testKey + "-2"))
+		require.Error(t, err)
+	})
+
+	t.Run("["+Redis.String()+"] [cluster] - one by one", func(t *testing.T) {
+		nodes := loadRedisInMemoryCluster(t)
+		c := newClusterTestClient(t, nodes)
+
+		keys := testClusterKeys(t, nodes, "key-")
+		gets := make([]*PipelineCmd, len(keys))
+		err := c.Pipeline(context.Background(), func(p Pipeliner) error {
+			for i, key := range keys {
+				p.Set(key, testValue+key, 0)
+				gets[i] = p.Get(key)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		for i, key := range keys {
+			assert.Equal(t, testValue+key, gets[i].Value())
+			value, getErr := testClusterOwner(nodes, key).Get(key)
+			require.NoError(t, getErr)
+			assert.Equal(t, testValue+key, value)
+		}
+	})
+
+	t.Run("["+Redis.String()+"] - value encoding", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(),
+			WithRedis(&RedisConfig{URL: r.Addr()}), WithValueEncoding(ValueEncodingBase64),
+		)
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var get *PipelineCmd
+		err = c.Pipeline(context.Background(), func(p Pipeliner) error {
+			p.Set(testKey, testValue, 0)
+			get = p.Get(testKey)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testValue, get.Value())
+
+		stored, err := r.Get(testKey)
+		require.NoError(t, err)
+		assert.NotEqual(t, testValue, stored)
+
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+	})
+}
@@ -2,12 +2,157 @@ package cachestore
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"github.com/mrz1836/go-cache"
+	"github.com/mrz1836/go-cache/nrredis"
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
+// Redis commands that are not provided by the go-cache package
+const (
+	appendCommand         = "APPEND"
+	decrementByCommand    = "DECRBY"
+	flushDBCommand        = "FLUSHDB"
+	getSetCommand         = "GETSET"
+	incrementByCommand    = "INCRBY"
+	multiGetCommand       = "MGET"
+	multiSetCommand       = "MSET"
+	pExpireCommand        = "PEXPIRE"
+	pTTLCommand           = "PTTL"
+	scanCommand           = "SCAN"
+	selectCommand         = "SELECT"
+	setCardinalityCommand = "SCARD"
+	sortedSetAddCommand   = "ZADD"
+	sortedSetCountCommand = "ZCARD"
+	sortedSetRangeCommand = "ZRANGE"
+	sortedSetRemCommand   = "ZREM"
+)
+
+// compareAndSwapScript will set the key->value only if the current value matches (returns 0 if missing or different)
+//
+// ARGV[3] is the expiration in milliseconds (0 does not expire)
+const compareAndSwapScript = `
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+else
+	redis.call("SET", KEYS[1], ARGV[2])
+end
+return 1
+`
+
+// getAndRefreshScript will return the value and update the expiration of a key (returns nil if the key does not exist)
+//
+// ARGV[1] is the expiration in milliseconds (0 removes the expiration)
+const getAndRefreshScript = `
+local v = redis.call("GET", KEYS[1])
+if v == false then
+	return false
+end
+if tonumber(ARGV[1]) > 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+else
+	redis.call("PERSIST", KEYS[1])
+end
+return v
+`
+
+// persistScript will remove the expiration of a key (returns 0 if the key does not exist)
+const persistScript = `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("PERSIST", KEYS[1])
+return 1
+`
+
+// refreshLockScript will update the expiration of a lock held with the same secret (returns 0 if not held)
+const refreshLockScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// releaseLockScript will remove a lock only if the secret matches (returns 1 if removed or not found)
+//
+// The secret is compared inside redis (exact match), it is never returned to the client
+const releaseLockScript = `
+local v = redis.call("GET", KEYS[1])
+if v == false then
+	return 1
+elseif v == ARGV[1] then
+	redis.call("DEL", KEYS[1])
+	return 1
+end
+return 0
+`
+
+// trimDependencyScript will record the order of the keys linked to a dependency and unlink the oldest keys over the max
+//
+// KEYS[1] is the dependency set, KEYS[2] the order (sorted set scored by the link time), ARGV[1] is the max,
+// ARGV[2] the link time (milliseconds, after the newest link) and ARGV[3...] the keys; returns the number of keys unlinked
+// Keys without an order (linked before the limit was set) are never unlinked, entries of keys no longer
+// in the set are removed once the order is twice the max
+const trimDependencyScript = `
+local max = tonumber(ARGV[1])
+local linked = tonumber(ARGV[2])
+local newest = redis.call("ZRANGE", KEYS[2], -1, -1, "WITHSCORES")
+if #newest > 0 and tonumber(newest[2]) >= linked then
+	linked = tonumber(newest[2]) + 1
+end
+for i = 3, #ARGV do
+	redis.call("ZADD", KEYS[2], "NX", linked, ARGV[i])
+end
+local trimmed = 0
+local over = redis.call("SCARD", KEYS[1]) - max
+while over > 0 do
+	local oldest = redis.call("ZRANGE", KEYS[2], 0, over - 1)
+	if #oldest == 0 then
+		break
+	end
+	for _, key in ipairs(oldest) do
+		redis.call("ZREM", KEYS[2], key)
+		local removed = redis.call("SREM", KEYS[1], key)
+		trimmed = trimmed + removed
+		over = over - removed
+	end
+end
+if redis.call("ZCARD", KEYS[2]) > 2 * max then
+	for _, key in ipairs(redis.call("ZRANGE", KEYS[2], 0, -1)) do
+		if redis.call("SISMEMBER", KEYS[1], key) == 0 then
+			redis.call("ZREM", KEYS[2], key)
+		end
+	end
+end
+return trimmed
+`
+
+// Redis URL schemes (rediss is TLS)
+const (
+	redisScheme    = "redis"
+	redisTLSScheme = "rediss"
+)
+
+// Redis SET options (locks and replace)
+const (
+	setExistsOption    = "XX" // Only set the key if it already exists
+	setExpireOption    = "PX" // Expiration in milliseconds
+	setNotExistsOption = "NX" // Only set the key if it does not exist
+)
+
 // loadRedisClient will load the cache client (redis)
 func loadRedisClient(
 	ctx context.Context,
@@ -15,9 +160,21 @@ func loadRedisClient(
 	newRelicEnabled bool,
 ) (*cache.Client, error) {
 
-	// Check for a config
-	if config == nil || config.URL == "" {
+	// Check for a config (sentinel and cluster do not require a URL)
+	if config == nil || (config.URL == "" && !config.isSentinel() && !config.isCluster()) {
 		return nil, ErrInvalidRedisConfig
+	} else if err := config.validateSentinel(); err != nil {
+		return nil, err
+	} else if err = config.validateCluster(); err != nil {
+		return nil, err
+	} else if err = config.validateReplicas(); err != nil {
+		return nil, err
+	} else if config.Database < 0 {
+		return nil, fmt.Errorf("%w: database cannot be negative (%d)", ErrInvalidRedisConfig, config.Database)
+	} else if err = config.validateAuth(); err != nil {
+		return nil, err
+	} else if err = config.validateURL(); err != nil {
+		return nil, err
 	}
 
 	// If NewRelic is enabled
@@ -29,22 +186,44 @@ func loadRedisClient(
 		}
 	}
 
-	// Attempt to create the client
-	client, err := cache.Connect(
-		ctx,
-		config.URL,
-		config.MaxActiveConnections,
-		config.MaxIdleConnections,
-		config.MaxConnectionLifetime,
-		config.MaxIdleTimeout,
-		config.DependencyMode,
-		newRelicEnabled,
-		redis.DialUseTLS(config.UseTLS),
-	)
+	// Attempt to create the client (using the sentinels to discover the master, or the cluster nodes)
+	var client *cache.Client
+	var err error
+	if config.isCluster() {
+		client, err = connectCluster(config, newRelicEnabled)
+	} else if config.isSentinel() {
+		client, err = connectSentinel(ctx, config, newRelicEnabled)
+	} else {
+		var redisURL string
+		if redisURL, err = config.connectionURL(); err != nil {
+			return nil, err
+		}
+		if client, err = cache.Connect(
+			ctx,
+			redisURL,
+			config.MaxActiveConnections,
+			config.MaxIdleConnections,
+			config.MaxConnectionLifetime,
+			config.MaxIdleTimeout,
+			config.DependencyMode,
+			false, // NewRelic wraps the pool once the wait is set
+			append(config.dialOptions(), config.authDialOptions()...)...,
+		); err == nil {
+			if pool, ok := client.Pool.(*redis.Pool); ok && config.database != nil {
+				selectDatabase(pool, *config.database)
+			}
+			client.Pool, err = config.wrapPool(client.Pool, redisURL, newRelicEnabled)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// Stop the commands when the context is done (cluster connections do not support a context)
+	if !config.isCluster() {
+		client.Pool = &contextPool{Pool: client.Pool, waitTimeout: config.PoolWaitTimeout}
+	}
+
 	// Test the connection if DependencyMode mode is off (no connection tested)
 	if !config.DependencyMode { // Fire a ping to make sure it works!
 		if err = cache.Ping(ctx, client); err != nil {
@@ -53,3 +232,989 @@ func loadRedisClient(
 	}
 	return client, nil
 }
+
+// wrapPool will set the wait for a free connection (see: PoolWaitTimeout) and wrap the pool for NewRelic (if enabled)
+func (r *RedisConfig) wrapPool(pool nrredis.Pool, redisURL string, newRelicEnabled bool) (nrredis.Pool, error) {
+	if redisPool, ok := pool.(*redis.Pool); ok {
+		redisPool.Wait = r.PoolWaitTimeout > 0
+	}
+	if !newRelicEnabled {
+		return pool, nil
+	}
+
+	// Same attributes as the go-cache package (host, port and database)
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return nil, err
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return nil, err
+	}
+	return nrredis.Wrap(
+		pool,
+		nrredis.WithDBName(strings.ReplaceAll(u.RequestURI(), "/", "")),
+		nrredis.WithHost(host),
+		nrredis.WithPortPathOrID(port),
+	), nil
+}
+
+// dialOptions will return the dial options for a connection (TLS, timeouts and the dial backoff)
+//
+// The read and write timeouts are applied to each command, zero is no timeout
+// NOTE: the dial backoff keeps the failed dials, use the same options for every dial of a pool
+func (r *RedisConfig) dialOptions() []redis.DialOption {
+	options := []redis.DialOption{redis.DialUseTLS(r.UseTLS)}
+	if r.UseTLS {
+		options = append(options, redis.DialTLSConfig(r.tlsConfig()))
+	}
+	if r.DialBackoff > 0 {
+		options = append(options, redis.DialContextFunc(r.newDialBackoff().dialContext))
+	} else if r.ConnectTimeout > 0 {
+		options = append(options, redis.DialConnectTimeout(r.ConnectTimeout))
+	}
+	if r.ReadTimeout > 0 {
+		options = append(options, redis.DialReadTimeout(r.ReadTimeout))
+	}
+	if r.WriteTimeout > 0 {
+		options = append(options, redis.DialWriteTimeout(r.WriteTimeout))
+	}
+	return options
+}
+
+// selectDatabase will SELECT the database on each new connection of the pool (see: WithRedisDatabase)
+//
+// The idle connections (dialed before) select the database when they are borrowed
+// NOTE: the pool is changed, it must not be used while the database is set
+func selectDatabase(pool *redis.Pool, db int) {
+	if pool.Dial == nil && pool.DialContext == nil {
+		return
+	}
+
+	// Select the database after dialing
+	dial, dialContext := pool.Dial, pool.DialContext
+	pool.DialContext = func(ctx context.Context) (redis.Conn, error) {
+		var conn redis.Conn
+		var err error
+		if dialContext != nil {
+			conn, err = dialContext(ctx)
+		} else {
+			conn, err = dial()
+		}
+		if err != nil {
+			return nil, err
+		} else if _, err = conn.Do(selectCommand, db); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+
+	// Select the database on the idle connections (returned to the pool before now)
+	selectedAt := time.Now()
+	testOnBorrow := pool.TestOnBorrow
+	pool.TestOnBorrow = func(conn redis.Conn, lastUsed time.Time) error {
+		if lastUsed.Before(selectedAt) {
+			if _, err := conn.Do(selectCommand, db); err != nil {
+				return err
+			}
+		}
+		if testOnBorrow != nil {
+			return testOnBorrow(conn, lastUsed)
+		}
+		return nil
+	}
+}
+
+// redigoPool will return the pool of the redis client (nil if wrapped, ie: NewRelic, or a cluster)
+func redigoPool(client *cache.Client) *redis.Pool {
+	pool := client.Pool
+	if wrapped, ok := pool.(*contextPool); ok {
+		pool = wrapped.Pool
+	}
+	switch p := pool.(type) {
+	case *redis.Pool:
+		return p
+	case *sentinelPool:
+		return p.Pool
+	}
+	return nil
+}
+
+// redisPrefix will return the URL prefix (rediss:// if using TLS)
+func redisPrefix(useTLS bool) string {
+	if useTLS {
+		return RedisTLSPrefix
+	}
+	return RedisPrefix
+}
+
+// redisURLWithScheme will add the scheme to a URL without one (rediss:// if using TLS)
+//
+// A redis:// URL is switched to rediss:// if using TLS, any other scheme is left untouched (validated when connecting)
+func redisURLWithScheme(rawURL string, useTLS bool) string {
+	rawURL = strings.TrimSpace(rawURL)
+	prefix := redisPrefix(useTLS)
+
+	// Empty or already using the scheme
+	if len(rawURL) == 0 || strings.HasPrefix(rawURL, prefix) {
+		return rawURL
+	} else if useTLS && strings.HasPrefix(rawURL, RedisPrefix) {
+		return prefix + strings.TrimPrefix(rawURL, RedisPrefix)
+	}
+
+	// Another scheme (the "://" is before the host, credentials or path)
+	if i := strings.Index(rawURL, "://"); i > 0 && !strings.ContainsAny(rawURL[:i], ":/?#@") {
+		return rawURL
+	}
+	return prefix + rawURL
+}
+
+// validateURL will validate the URL (scheme, host and port) before connecting
+//
+// Sentinel and cluster connections do not use the host of the URL (not validated)
+func (r *RedisConfig) validateURL() error {
+	if r.isSentinel() || r.isCluster() {
+		return nil
+	}
+
+	// The error of url.Parse is not wrapped (it contains the url and any password)
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return fmt.Errorf("%w: the url cannot be parsed", ErrInvalidRedisURL)
+	} else if u.Scheme != redisScheme && u.Scheme != redisTLSScheme {
+		return fmt.Errorf("%w: unsupported scheme %q (use %s or %s)", ErrInvalidRedisURL, u.Scheme, redisScheme, redisTLSScheme)
+	} else if len(u.Hostname()) == 0 {
+		return fmt.Errorf("%w: the host is missing", ErrInvalidRedisURL)
+	}
+
+	// The port is optional (default: 6379)
+	if port := u.Port(); len(port) > 0 {
+		if number, portErr := strconv.Atoi(port); portErr != nil || number < 1 || number > 65535 {
+			return fmt.Errorf("%w: invalid port %q", ErrInvalidRedisURL, port)
+		}
+	}
+	return nil
+}
+
+// tlsConfig will return the TLS configuration (TLSConfig, or the default verifying the server certificate)
+//
+// The server name is derived from the host of the URL (or the address) if not set
+func (r *RedisConfig) tlsConfig() *tls.Config {
+	if r.TLSConfig != nil {
+		return r.TLSConfig
+	}
+	return &tls.Config{MinVersion: tls.VersionTLS12}
+}
+
+// validateAuth will validate the credentials (a username requires a password, see: RedisConfig.Username)
+func (r *RedisConfig) validateAuth() error {
+	if username, password := r.aclCredentials(); len(username) > 0 && len(password) == 0 {
+		return fmt.Errorf("%w: username %q requires a password", ErrInvalidRedisConfig, username)
+	}
+	return nil
+}
+
+// aclCredentials will return the username and password of the ACL user (empty if the Username field is not set)
+//
+// The Password field is preferred over a password in the URL
+func (r *RedisConfig) aclCredentials() (username, password string) {
+	if len(r.Username) == 0 {
+		return "", ""
+	} else if password = r.Password; len(password) == 0 {
+		if u, err := url.Parse(r.URL); err == nil && u.User != nil {
+			password, _ = u.User.Password()
+		}
+	}
+	return r.Username, password
+}
+
+// authDialOptions will return the dial options to AUTH username password (redis 6+ ACL, see: RedisConfig.Username)
+//
+// Returns nil if the Username field is not set (the password of the URL is used, AUTH password)
+func (r *RedisConfig) authDialOptions() []redis.DialOption {
+	username, password := r.aclCredentials()
+	if len(username) == 0 {
+		return nil
+	}
+	return []redis.DialOption{redis.DialUsername(username), redis.DialPassword(password)}
+}
+
+// connectionURL will return the URL with the password and database fields applied (AUTH and SELECT)
+//
+// The Password field is preferred over a password in the URL, and the Database field
+// is preferred over a database in the URL (unless it's zero)
+// The credentials are removed if the Username field is set (sent using the dial options, see: authDialOptions)
+func (r *RedisConfig) connectionURL() (string, error) {
+
+	// Dialing a URL uses the scheme for TLS (rediss://) instead of the UseTLS dial option
+	redisURL := redisURLWithScheme(r.URL, r.UseTLS)
+	if len(r.Username) == 0 && len(r.Password) == 0 && r.Database == 0 {
+		return redisURL, nil
+	} else if len(redisURL) == 0 {
+		redisURL = redisPrefix(r.UseTLS)
+	}
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return "", err
+	}
+	if len(r.Username) > 0 { // AUTH username password (dial options)
+		u.User = nil
+	} else if len(r.Password) > 0 { // Keep the username (if any)
+		var username string
+		if u.User != nil {
+			username = u.User.Username()
+		}
+		u.User = url.UserPassword(username, r.Password)
+	}
+	if r.Database > 0 {
+		u.Path = "/" + strconv.Itoa(r.Database)
+	}
+	return u.String(), nil
+}
+
+// setRedis will set the key->value (SET) and link the dependencies
+func setRedis(ctx context.Context, client *cache.Client, key string, value interface{}, dependencies ...string) error {
+	if isRedisCluster(client) && len(dependencies) > 0 {
+		if err := cache.Set(ctx, client, key, value); err != nil {
+			return err
+		}
+		return linkDependenciesCluster(ctx, client, key, dependencies...)
+	}
+	return cache.Set(ctx, client, key, value, dependencies...)
+}
+
+// setExpRedis will set the key->value with a TTL (SETEX) and link the dependencies
+func setExpRedis(ctx context.Context, client *cache.Client, key string, value interface{},
+	ttl time.Duration, dependencies ...string) error {
+	if isRedisCluster(client) && len(dependencies) > 0 {
+		if err := cache.SetExp(ctx, client, key, value, ttl); err != nil {
+			return err
+		}
+		return linkDependenciesCluster(ctx, client, key, dependencies...)
+	}
+	return cache.SetExp(ctx, client, key, value, ttl, dependencies...)
+}
+
+// setWithTTLRedis will set the key->value with a TTL (SETEX), or without an expiration if the ttl is zero
+func setWithTTLRedis(ctx context.Context, client *cache.Client, key string, value interface{},
+	ttl time.Duration, dependencies ...string) error {
+	if ttl > 0 {
+		return setExpRedis(ctx, client, key, value, ttl, dependencies...)
+	}
+	return setRedis(ctx, client, key, value, dependencies...)
+}
+
+// replaceRedis will set the key->value only if the key exists (SET XX) with a TTL (PX) and link the dependencies
+//
+// Returns false if the key does not exist (nothing is written), a zero ttl does not expire
+func replaceRedis(ctx context.Context, client *cache.Client, key string, value interface{},
+	ttl time.Duration, dependencies ...string) (bool, error) {
+	args := []interface{}{key, value, setExistsOption}
+	if ttl > 0 {
+		args = append(args, setExpireOption, ttl.Milliseconds())
+	}
+	reply, err := doRedis(ctx, client, cache.SetCommand, args...)
+	if err != nil || reply == nil { // A nil reply is a missing key
+		return false, err
+	}
+	return true, linkDependenciesCluster(ctx, client, key, dependencies...)
+}
+
+// getMultiRedis will get several keys using a single MGET command
+//
+// A redis cluster uses an MGET command per slot
+// Keys that are not found are not included in the results
+func getMultiRedis(ctx context.Context, client *cache.Client, keys []string) (map[string]string, error) {
+	values := make(map[string]string, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	for _, slotKeys := range groupKeysBySlot(client, keys) {
+		if err := getMultiRedisKeys(ctx, client, slotKeys, values); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// getMultiRedisKeys will get the keys using an MGET command and add the found keys to the values
+func getMultiRedisKeys(ctx context.Context, client *cache.Client, keys []string, values map[string]string) error {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.CloseConnection(conn)
+
+	// Create the arguments
+	args := make([]interface{}, len(keys))
+	for i, key := range keys {
+		args[i] = key
+	}
+
+	// Fire the command
+	var replies []interface{}
+	if replies, err = redis.Values(conn.Do(multiGetCommand, args...)); err != nil {
+		return err
+	}
+
+	// Missing keys are returned as nil
+	for i, reply := range replies {
+		if reply == nil || i >= len(keys) {
+			continue
+		}
+		var value string
+		if value, err = redis.String(reply, nil); err != nil {
+			return err
+		}
+		values[keys[i]] = value
+	}
+	return nil
+}
+
+// setMultiRedis will set several keys using a pipeline (MSET + dependencies) in a single round trip
+//
+// Each key expires after the ttl (PEXPIRE), a zero ttl does not expire
+// A redis cluster uses an MSET command per slot and a command per dependency
+func setMultiRedis(ctx context.Context, client *cache.Client, items map[string]string,
+	ttl time.Duration, dependencies ...string) error {
+	if len(items) == 0 {
+		return nil
+	} else if isRedisCluster(client) {
+		return setMultiRedisCluster(ctx, client, items, ttl, dependencies...)
+	}
+
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.CloseConnection(conn)
+
+	// Sort the keys so the command is deterministic
+	keys := sortedKeys(items)
+	args := make([]interface{}, 0, len(keys)*2)
+	for _, key := range keys {
+		args = append(args, key, items[key])
+	}
+
+	// Queue the set command
+	if err = conn.Send(multiSetCommand, args...); err != nil {
+		return err
+	}
+
+	// Queue the expiration of each key
+	if ttl > 0 {
+		for _, key := range keys {
+			if err = conn.Send(pExpireCommand, key, ttl.Milliseconds()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Queue linking each dependency to all the keys
+	for _, dependency := range dependencies {
+		depArgs := make([]interface{}, 0, len(keys)+1)
+		depArgs = append(depArgs, cache.DependencyPrefix+dependency)
+		for _, key := range keys {
+			depArgs = append(depArgs, key)
+		}
+		if err = conn.Send(cache.AddToSetCommand, depArgs...); err != nil {
+			return err
+		}
+	}
+
+	// Flush the pipeline and read all the replies
+	_, err = flushPipeline(conn)
+	return err
+}
+
+// setMultiRedisCluster will set several keys using an MSET command per slot and link the dependencies
+func setMultiRedisCluster(ctx context.Context, client *cache.Client, items map[string]string,
+	ttl time.Duration, dependencies ...string) error {
+	keys := sortedKeys(items)
+	for _, slotKeys := range groupKeysBySlot(client, keys) {
+		args := make([]interface{}, 0, len(slotKeys)*2)
+		for _, key := range slotKeys {
+			args = append(args, key, items[key])
+		}
+		if _, err := doRedis(ctx, client, multiSetCommand, args...); err != nil {
+			return err
+		}
+	}
+
+	// Set the expiration of each key
+	if ttl > 0 {
+		for _, key := range keys {
+			if _, err := doRedis(ctx, client, pExpireCommand, key, ttl.Milliseconds()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Link each dependency to all the keys
+	for _, dependency := range dependencies {
+		args := make([]interface{}, 0, len(keys)+1)
+		args = append(args, cache.DependencyPrefix+dependency)
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		if _, err := doRedis(ctx, client, cache.AddToSetCommand, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// doRedis will fire a single command using a new connection
+func doRedis(ctx context.Context, client *cache.Client, command string, args ...interface{}) (interface{}, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseConnection(conn)
+	return conn.Do(command, args...)
+}
+
+// flushPipeline will flush all queued commands and return the replies
+//
+// The first error reply (if any) is returned as the error
+func flushPipeline(conn redis.Conn) ([]interface{}, error) {
+	replies, err := redis.Values(conn.Do(""))
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		if replyErr, ok := reply.(redis.Error); ok {
+			return replies, replyErr
+		}
+	}
+	return replies, nil
+}
+
+// getWithTTLRedis will get the value and the remaining ttl using a pipeline (GET + PTTL) in a single round trip
+//
+// Returns ErrKeyNotFound if the key does not exist, a ttl of zero is no expiration
+func getWithTTLRedis(ctx context.Context, client *cache.Client, key string) ([]byte, time.Duration, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer client.CloseConnection(conn)
+
+	// Queue both commands (same key, same node for a cluster)
+	if err = conn.Send(cache.GetCommand, key); err != nil {
+		return nil, 0, err
+	}
+	if err = conn.Send(pTTLCommand, key); err != nil {
+		return nil, 0, err
+	}
+
+	// Flush the pipeline and read both replies
+	var replies []interface{}
+	if replies, err = flushPipeline(conn); err != nil {
+		return nil, 0, err
+	}
+	value, err := redis.Bytes(replies[0], nil)
+	if errors.Is(err, redis.ErrNil) || (err == nil && len(value) == 0) {
+		return nil, 0, ErrKeyNotFound
+	} else if err != nil {
+		return nil, 0, err
+	}
+
+	// PTTL is -1 if the key does not expire (-2 if the key does not exist)
+	var remaining int64
+	if remaining, err = redis.Int64(replies[1], nil); err != nil {
+		return nil, 0, err
+	} else if remaining == -2 {
+		return nil, 0, ErrKeyNotFound
+	} else if remaining < 0 {
+		remaining = 0
+	}
+	return value, time.Duration(remaining) * time.Millisecond, nil
+}
+
+// incrementRedis will fire the given counter command (INCRBY or DECRBY) and return the new value
+func incrementRedis(ctx context.Context, client *cache.Client, command, key string, delta int64) (int64, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseConnection(conn)
+
+	var value int64
+	if value, err = redis.Int64(conn.Do(command, key, delta)); err != nil {
+		var replyErr redis.Error
+		if errors.As(err, &replyErr) && strings.Contains(replyErr.Error(), "not an integer") {
+			return 0, fmt.Errorf("%w: %s", ErrValueNotNumeric, replyErr.Error())
+		}
+		return 0, err
+	}
+	return value, nil
+}
+
+// appendRedis will append the value to the string stored at the key (APPEND) and return the new length
+func appendRedis(ctx context.Context, client *cache.Client, key, value string) (int, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseConnection(conn)
+	return redis.Int(conn.Do(appendCommand, key, value))
+}
+
+// getSetRedis will set the key->value and return the previous value (GETSET) if the key existed
+//
+// The key expires after the ttl using a pipeline (GETSET + PEXPIRE), a zero ttl does not expire
+func getSetRedis(ctx context.Context, client *cache.Client, key string, value interface{},
+	ttl time.Duration) (string, bool, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	defer client.CloseConnection(conn)
+
+	// Queue both commands (same key, same node for a cluster)
+	if err = conn.Send(getSetCommand, key, value); err != nil {
+		return "", false, err
+	}
+	if ttl > 0 {
+		if err = conn.Send(pExpireCommand, key, ttl.Milliseconds()); err != nil {
+			return "", false, err
+		}
+	}
+
+	// Flush the pipeline, the first reply is the previous value (nil if the key did not exist)
+	var replies []interface{}
+	if replies, err = flushPipeline(conn); err != nil {
+		return "", false, err
+	}
+	old, err := redis.String(replies[0], nil)
+	if errors.Is(err, redis.ErrNil) {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return old, true, nil
+}
+
+// compareAndSwapRedis will set the key->value only if the current value is the expected value (GET, compare and SET in a single script)
+//
+// Returns false if the key does not exist or has a different value (nothing is written), a zero ttl does not expire
+func compareAndSwapRedis(ctx context.Context, client *cache.Client, key string, expected, value interface{},
+	ttl time.Duration) (bool, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.CloseConnection(conn)
+	return redis.Bool(redis.NewScript(1, compareAndSwapScript).Do(conn, key, expected, value, ttl.Milliseconds()))
+}
+
+// getAndRefreshRedis will return the value and update the expiration of the key (GET and PEXPIRE in a single script)
+//
+// Returns ErrKeyNotFound if the key does not exist (nothing is written), a zero ttl removes the expiration
+func getAndRefreshRedis(ctx context.Context, client *cache.Client, key string, ttl time.Duration) ([]byte, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseConnection(conn)
+
+	value, err := redis.Bytes(redis.NewScript(1, getAndRefreshScript).Do(conn, key, ttl.Milliseconds()))
+	if errors.Is(err, redis.ErrNil) {
+		return nil, ErrKeyNotFound
+	}
+	return value, err
+}
+
+// trimDependencyRedis will record the order of the keys linked to the dependency and unlink the oldest keys over maxKeys
+//
+// The order is a sorted set next to the dependency set (see: dependencyOrderPrefix), returns the number of keys unlinked
+// A single script is used, or a command per step for a cluster (the set and the order are on different slots)
+func trimDependencyRedis(ctx context.Context, client *cache.Client, dependency string, maxKeys int,
+	keys ...string) (int, error) {
+	linked := time.Now().UnixMilli()
+	if isRedisCluster(client) {
+		return trimDependencyCluster(ctx, client, dependency, maxKeys, linked, keys...)
+	}
+
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseConnection(conn)
+
+	args := make([]interface{}, 0, len(keys)+4)
+	args = append(args, cache.DependencyPrefix+dependency, dependencyOrderPrefix+dependency, maxKeys, linked)
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	return redis.Int(redis.NewScript(2, trimDependencyScript).Do(conn, args...))
+}
+
+// touchRedis will update the expiration of an existing key (ttl <= 0 removes the expiration)
+func touchRedis(ctx context.Context, client *cache.Client, key string, ttl time.Duration) error {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.CloseConnection(conn)
+
+	// Update (or remove) the expiration
+	var updated bool
+	if ttl > 0 {
+		updated, err = redis.Bool(conn.Do(pExpireCommand, key, ttl.Milliseconds()))
+	} else {
+		updated, err = redis.Bool(redis.NewScript(1, persistScript).Do(conn, key))
+	}
+	if err != nil {
+		return err
+	} else if !updated {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// writeLockRedis will create a lock using SET NX PX (the secret is stored in the lock key)
+//
+// ttl is in seconds
+// An existing lock with the same secret has its expiration updated (ErrLockMismatch if the secret is different)
+func writeLockRedis(ctx context.Context, client *cache.Client, lockKey, secret string, ttl int64) (bool, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.CloseConnection(conn)
+
+	// Acquire the lock (only if the lock does not exist)
+	expiration := (time.Duration(ttl) * time.Second).Milliseconds()
+	if _, err = redis.String(conn.Do(
+		cache.SetCommand, lockKey, secret, setNotExistsOption, setExpireOption, expiration,
+	)); err == nil {
+		return true, nil
+	} else if !errors.Is(err, redis.ErrNil) {
+		return false, err
+	}
+
+	// The lock exists, update the expiration if the secret matches
+	var refreshed bool
+	if refreshed, err = redis.Bool(redis.NewScript(1, refreshLockScript).Do(
+		conn, lockKey, secret, expiration,
+	)); err != nil {
+		return false, err
+	} else if !refreshed {
+		return false, cache.ErrLockMismatch
+	}
+	return true, nil
+}
+
+// extendLockRedis will update the expiration of the lock only if the secret matches (compare and PEXPIRE in a single script)
+//
+// ttl is in seconds, a missing lock or a different secret returns ErrLockMismatch
+func extendLockRedis(ctx context.Context, client *cache.Client, lockKey, secret string, ttl int64) (bool, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.CloseConnection(conn)
+
+	var extended bool
+	if extended, err = redis.Bool(redis.NewScript(1, refreshLockScript).Do(
+		conn, lockKey, secret, (time.Duration(ttl) * time.Second).Milliseconds(),
+	)); err != nil {
+		return false, err
+	} else if !extended {
+		return false, cache.ErrLockMismatch
+	}
+	return true, nil
+}
+
+// releaseLockRedis will remove the lock only if the secret matches (compare and delete in a single script)
+//
+// A lock that does not exist is released (true), a different secret returns ErrLockMismatch
+func releaseLockRedis(ctx context.Context, client *cache.Client, lockKey, secret string) (bool, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer client.CloseConnection(conn)
+
+	var released bool
+	if released, err = redis.Bool(redis.NewScript(1, releaseLockScript).Do(
+		conn, lockKey, secret,
+	)); err != nil {
+		return false, err
+	} else if !released {
+		return false, cache.ErrLockMismatch
+	}
+	return true, nil
+}
+
+// forceReleaseLockRedis will remove the lock regardless of the secret (returns true if the lock existed)
+func forceReleaseLockRedis(ctx context.Context, client *cache.Client, lockKey string) (bool, error) {
+	deleted, err := redis.Int(doRedis(ctx, client, cache.DeleteCommand, lockKey))
+	return deleted > 0, err
+}
+
+// listLocksRedis will return the remaining ttl of the lock keys matching the pattern (SCAN, never KEYS)
+//
+// The locks are only read using PTTL (never changed), a redis cluster uses a PTTL pipeline per node
+func listLocksRedis(ctx context.Context, client *cache.Client, pattern string,
+	count int) (map[string]time.Duration, error) {
+	locks := make(map[string]time.Duration)
+	if err := scanRedis(ctx, client, pattern, count, func(conn redis.Conn, keys []string) error {
+		return ttlConnKeys(conn, keys, locks)
+	}); err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+// ttlConnKeys will get the remaining ttl of the keys using a PTTL pipeline on the connection and add them to the ttls
+//
+// Keys that do not exist are skipped, a ttl of zero is no expiration
+func ttlConnKeys(conn redis.Conn, keys []string, ttls map[string]time.Duration) (err error) {
+	for _, key := range keys {
+		if err = conn.Send(pTTLCommand, key); err != nil {
+			return err
+		}
+	}
+	var replies []interface{}
+	if replies, err = flushPipeline(conn); err != nil {
+		return err
+	}
+
+	// PTTL is -1 if the key does not expire (-2 if the key does not exist)
+	for i, reply := range replies {
+		var remaining int64
+		if remaining, err = redis.Int64(reply, nil); err != nil {
+			return err
+		} else if remaining == -2 || i >= len(keys) {
+			continue
+		}
+		ttls[keys[i]] = time.Duration(max(remaining, 0)) * time.Millisecond
+	}
+	return nil
+}
+
+// scanRedis will iterate all the keys matching the pattern using SCAN (cursor based, never KEYS)
+//
+// A redis cluster will scan each master node
+// fn is called for each batch of keys, iterating stops if fn returns an error or the context is done
+func scanRedis(ctx context.Context, client *cache.Client, pattern string, count int,
+	fn func(conn redis.Conn, keys []string) error) error {
+
+	// Redis cluster (scan each master node)
+	if cluster, ok := redisCluster(client); ok {
+		return cluster.EachNode(false, func(_ string, conn redis.Conn) error {
+			return scanConn(ctx, conn, pattern, count, fn)
+		})
+	}
+
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.CloseConnection(conn)
+	return scanConn(ctx, conn, pattern, count, fn)
+}
+
+// scanConn will iterate all the keys matching the pattern using SCAN on the connection
+//
+// The context is checked before each SCAN command
+func scanConn(ctx context.Context, conn redis.Conn, pattern string, count int,
+	fn func(conn redis.Conn, keys []string) error) (err error) {
+	cursor := 0
+	for {
+		if err = checkContext(ctx); err != nil {
+			return err
+		}
+
+		var values []interface{}
+		if values, err = redis.Values(
+			conn.Do(scanCommand, cursor, "MATCH", pattern, "COUNT", count),
+		); err != nil {
+			return err
+		}
+
+		var keys []string
+		if _, err = redis.Scan(values, &cursor, &keys); err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err = fn(conn, keys); err != nil {
+				return err
+			}
+		}
+
+		// A cursor of zero is the end of the iteration
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// deleteByPatternRedis will remove all keys matching the pattern using SCAN and batched DEL commands
+//
+// The keys are found first (removing keys while scanning can skip keys on some servers)
+// removed (optional) is called with each batch of keys before it's removed
+// A redis cluster uses a DEL command per slot
+func deleteByPatternRedis(ctx context.Context, client *cache.Client, pattern string, count int,
+	removed func(keys []string)) (int, error) {
+
+	// Find the keys
+	var keys []string
+	if err := scanRedis(ctx, client, pattern, count, func(_ redis.Conn, batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	}); err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	// Remove the keys in batches (same size as the scan count)
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseConnection(conn)
+
+	var total, deleted int
+	for start := 0; start < len(keys); start += count {
+		batch := keys[start:min(start+count, len(keys))]
+		if removed != nil {
+			removed(batch)
+		}
+		deleted, err = deleteKeysRedis(conn, client, batch)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// deleteMultiRedis will remove all the keys (a single DEL, or one per slot for a cluster)
+//
+// Returns the number of keys removed (missing keys are ignored)
+func deleteMultiRedis(ctx context.Context, client *cache.Client, keys []string) (int, error) {
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer client.CloseConnection(conn)
+	return deleteKeysRedis(conn, client, keys)
+}
+
+// deleteKeysRedis will remove the keys using an existing connection (a DEL per slot for a cluster)
+func deleteKeysRedis(conn redis.Conn, client *cache.Client, keys []string) (total int, err error) {
+	for _, slotKeys := range groupKeysBySlot(client, keys) {
+		args := make([]interface{}, len(slotKeys))
+		for i, key := range slotKeys {
+			args[i] = key
+		}
+		var deleted int
+		deleted, err = redis.Int(conn.Do(cache.DeleteCommand, args...))
+		total += deleted
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// deleteDependencyRedis will remove the dependency set and all the keys linked to it (returns the keys removed)
+//
+// The dependency script is used if registered (DependencyMode) and removed is nil, otherwise the keys are
+// read from the dependency set (SMEMBERS) and removed with the set (a DEL per slot for a cluster)
+// The order of the keys (see: trimDependencyRedis) is removed first
+// removed (optional) is called with the keys before they're removed
+func deleteDependencyRedis(ctx context.Context, client *cache.Client, dependency string,
+	removed func(keys []string)) (int, error) {
+	order := dependencyOrderPrefix + dependency
+	dependency = cache.DependencyPrefix + dependency
+
+	// Remove the order of the keys (see: trimDependencyRedis)
+	if _, err := doRedis(ctx, client, cache.DeleteCommand, order); err != nil {
+		return 0, err
+	}
+
+	// Remove the set and the keys in a single script (the set is counted if it exists)
+	if removed == nil && len(client.DependencyScriptSha) > 0 && !isRedisCluster(client) {
+		total, err := redis.Int(doRedis(ctx, client, cache.EvalCommand, client.DependencyScriptSha, 0, dependency))
+		if err != nil || total == 0 {
+			return 0, err
+		}
+		return total - 1, nil
+	}
+
+	// Read the keys linked to the dependency
+	keys, err := redis.Strings(doRedis(ctx, client, cache.MembersCommand, dependency))
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+	if removed != nil {
+		removed(keys)
+	}
+
+	// Remove the keys and the set (the set is not counted)
+	var total int
+	if total, err = deleteMultiRedis(ctx, client, append(keys, dependency)); err != nil {
+		return 0, err
+	} else if total > 0 {
+		total--
+	}
+	return total, nil
+}
+
+// unlinkDependenciesRedis will remove the key from all the dependency sets matching the pattern (SCAN + SREM)
+//
+// The sets are found first (removing an empty set while scanning can skip sets on some servers)
+func unlinkDependenciesRedis(ctx context.Context, client *cache.Client, pattern, key string, count int) error {
+
+	// Find the dependency sets
+	var dependencies []string
+	if err := scanRedis(ctx, client, pattern, count, func(_ redis.Conn, batch []string) error {
+		dependencies = append(dependencies, batch...)
+		return nil
+	}); err != nil || len(dependencies) == 0 {
+		return err
+	}
+
+	// Remove the key from each set
+	conn, err := client.GetConnectionWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.CloseConnection(conn)
+	for _, dependency := range dependencies {
+		if _, err = conn.Do(cache.RemoveMemberCommand, dependency, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapePattern will escape the glob characters used by SCAN MATCH
+func escapePattern(value string) string {
+	var builder strings.Builder
+	for _, char := range value {
+		switch char {
+		case '*', '?', '[', ']', '\\':
+			builder.WriteRune('\\')
+		}
+		builder.WriteRune(char)
+	}
+	return builder.String()
+}
+
+// sortedKeys will return the keys of the map in a deterministic order
+func sortedKeys(items map[string]string) []string {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,65 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mrz1836/go-cache"
+)
+
+// isReplicated will return true if the config has read replicas
+func (r *RedisConfig) isReplicated() bool {
+	return len(r.ReadReplicaURLs) > 0
+}
+
+// validateReplicas will validate the read replicas (a cluster routes the reads itself)
+func (r *RedisConfig) validateReplicas() error {
+	if r.isReplicated() && r.isCluster() {
+		return fmt.Errorf("%w: read replicas cannot be used with a cluster", ErrInvalidRedisConfig)
+	}
+	return nil
+}
+
+// replicaConfig will return the config of a read replica (the URL replaces the primary, sentinel is not used)
+//
+// The username, password, database, TLS, timeouts and pool settings of the primary are kept
+func (r *RedisConfig) replicaConfig(replicaURL string) *RedisConfig {
+	config := *r
+	config.MasterName = ""
+	config.ReadReplicaURLs = nil
+	config.SentinelAddresses = nil
+	config.URL = redisURLWithScheme(replicaURL, r.UseTLS)
+	return &config
+}
+
+// loadRedisReplicas will connect to each read replica (any connection error closes the replicas already loaded)
+func loadRedisReplicas(ctx context.Context, config *RedisConfig, newRelicEnabled bool) ([]*cache.Client, error) {
+	replicas := make([]*cache.Client, 0, len(config.ReadReplicaURLs))
+	for _, replicaURL := range config.ReadReplicaURLs {
+		replica, err := loadRedisClient(ctx, config.replicaConfig(replicaURL), newRelicEnabled)
+		if err != nil {
+			closeRedisReplicas(replicas)
+			return nil, err
+		}
+		replicas = append(replicas, replica)
+	}
+	return replicas, nil
+}
+
+// closeRedisReplicas will close the connections of the read replicas
+func closeRedisReplicas(replicas []*cache.Client) {
+	for _, replica := range replicas {
+		replica.Close()
+	}
+}
+
+// readRedis will return the redis client for a read, the next read replica (round-robin) or the primary
+//
+// Only the reads of a single key use the read replicas: Get, GetBytes, GetModel, GetModelWithTTL and Exists
+// (and the methods reading through them, ie: GetInt or GetOrSet), see: WithRedis
+func (c *Client) readRedis() *cache.Client {
+	if count := uint64(len(c.options.replicas)); count > 0 {
+		return c.options.replicas[(c.options.replicaNext.Add(1)-1)%count]
+	}
+	return c.options.redis
+}
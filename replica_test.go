@@ -0,0 +1,198 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newReplicatedTestClient will return a client using the primary and the read replicas (no replication)
+func newReplicatedTestClient(t *testing.T, primary *miniredis.Miniredis, replicas ...*miniredis.Miniredis) ClientInterface {
+	replicaURLs := make([]string, 0, len(replicas))
+	for _, replica := range replicas {
+		replicaURLs = append(replicaURLs, replica.Addr())
+	}
+	c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+		ReadReplicaURLs: replicaURLs,
+		URL:             primary.Addr(),
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c
+}
+
+// TestRedisConfig_replicaConfig will test the method replicaConfig()
+func TestRedisConfig_replicaConfig(t *testing.T) {
+	t.Parallel()
+
+	config := &RedisConfig{
+		Database:          2,
+		MasterName:        testMasterName,
+		Password:          "secret",
+		ReadReplicaURLs:   []string{"replica:6379"},
+		SentinelAddresses: []string{"localhost:26379"},
+		URL:               RedisPrefix + "primary:6379",
+		UseTLS:            true,
+	}
+
+	replica := config.replicaConfig("replica:6379")
+	assert.Equal(t, RedisTLSPrefix+"replica:6379", replica.URL)
+	assert.Equal(t, 2, replica.Database)
+	assert.Equal(t, "secret", replica.Password)
+	assert.True(t, replica.UseTLS)
+	assert.False(t, replica.isSentinel())
+	assert.False(t, replica.isReplicated())
+
+	// The primary config is not changed
+	assert.Equal(t, RedisPrefix+"primary:6379", config.URL)
+	assert.True(t, config.isSentinel())
+	assert.True(t, config.isReplicated())
+}
+
+// TestClient_ReadReplicas will test the read replicas (RedisConfig.ReadReplicaURLs)
+func TestClient_ReadReplicas(t *testing.T) {
+
+	t.Run("writes use the primary, reads use the replica", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, replica)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		value, err := primary.Get(testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+		assert.False(t, replica.Exists(testKey))
+
+		// Not replicated yet (stale read)
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Empty(t, value)
+
+		// Replicated
+		require.NoError(t, replica.Set(testKey, testValue+"-replica"))
+		value, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue+"-replica", value)
+
+		var found bool
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		// Deleted on the primary only
+		require.NoError(t, c.Delete(context.Background(), testKey))
+		assert.False(t, primary.Exists(testKey))
+		assert.True(t, replica.Exists(testKey))
+	})
+
+	t.Run("models are read from the replica", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, replica)
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+		assert.True(t, primary.Exists(testKey))
+
+		err := c.GetModel(context.Background(), testKey, new(genericStruct))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		require.NoError(t, replica.Set(testKey, `{"string_field":"from-replica"}`))
+		replica.SetTTL(testKey, time.Minute)
+
+		model := new(genericStruct)
+		require.NoError(t, c.GetModel(context.Background(), testKey, model))
+		assert.Equal(t, "from-replica", model.StringField)
+
+		var ttl time.Duration
+		ttl, err = c.GetModelWithTTL(context.Background(), testKey, model)
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, ttl)
+	})
+
+	t.Run("bytes are read from the replica", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, replica)
+
+		require.NoError(t, c.SetBytes(context.Background(), testKey, []byte(testValue)))
+		assert.True(t, primary.Exists(testKey))
+
+		_, err := c.GetBytes(context.Background(), testKey)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		require.NoError(t, replica.Set(testKey, testValue+"-replica"))
+		var value []byte
+		value, err = c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, []byte(testValue+"-replica"), value)
+	})
+
+	t.Run("locks use the primary", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, replica)
+
+		secret, err := c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+		assert.True(t, primary.Exists(testLockKey))
+		assert.False(t, replica.Exists(testLockKey))
+
+		var released bool
+		released, err = c.ReleaseLock(context.Background(), testKey, secret)
+		require.NoError(t, err)
+		assert.True(t, released)
+	})
+
+	t.Run("reads are round-robin", func(t *testing.T) {
+		primary := loadRedisInMemoryClient(t)
+		first, second := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, first, second)
+
+		require.NoError(t, first.Set(testKey, "first"))
+		require.NoError(t, second.Set(testKey, "second"))
+
+		values := make(map[string]int)
+		for i := 0; i < 10; i++ {
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			values[value]++
+		}
+		assert.Equal(t, map[string]int{"first": 5, "second": 5}, values)
+	})
+
+	t.Run("close closes the replicas", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		c := newReplicatedTestClient(t, primary, replica)
+
+		_, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		require.Equal(t, 1, replica.CurrentConnectionCount())
+
+		c.Close(context.Background())
+		require.Eventually(t, func() bool {
+			return replica.CurrentConnectionCount() == 0
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("a replica that cannot connect fails the client", func(t *testing.T) {
+		primary, replica := loadRedisInMemoryClient(t), loadRedisInMemoryClient(t)
+		replicaURL := replica.Addr()
+		replica.Close()
+
+		_, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+			ReadReplicaURLs: []string{replicaURL},
+			URL:             primary.Addr(),
+		}))
+		require.Error(t, err)
+	})
+
+	t.Run("replicas cannot be used with a cluster", func(t *testing.T) {
+		_, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+			ClusterAddresses: []string{"localhost:7000"},
+			ReadReplicaURLs:  []string{"localhost:6380"},
+		}))
+		require.ErrorIs(t, err, ErrInvalidRedisConfig)
+	})
+}
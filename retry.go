@@ -0,0 +1,41 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// retry will call fn again if it fails with a transient error, up to the retry attempts (see: WithRetry)
+//
+// The delay between the attempts starts at the backoff and doubles after each attempt
+// Stops if the context is done while waiting (returns the checkContext error)
+func (c *Client) retry(ctx context.Context, fn func() error) error {
+	delay := c.options.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.options.retryAttempts || !isRetryableError(err) {
+			return err
+		}
+		if err = sleepContext(ctx, delay); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// isRetryableError will return true for a transient connection error (connection refused or reset, EOF or timeout)
+//
+// Errors from the server (redis.Error), a done context and the cachestore errors are never retried
+func isRetryableError(err error) bool {
+	if err == nil || errors.Is(err, ErrContextDone) {
+		return false
+	} else if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,149 @@
+package cachestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/mrz1836/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyDialer is a redis dial function that fails a number of times before connecting
+type flakyDialer struct {
+	address  string
+	attempts int
+	failures int
+	sync.Mutex
+}
+
+// dial will return a connection refused error until the failures are used
+func (d *flakyDialer) dial() (redis.Conn, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.attempts++
+	if d.attempts <= d.failures {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+	return redis.Dial("tcp", d.address)
+}
+
+// newFlakyRedisClient will return a client that fails to connect a number of times (no idle connections are kept)
+func newFlakyRedisClient(t *testing.T, failures int, opts ...ClientOps) (ClientInterface, *flakyDialer) {
+	dialer := &flakyDialer{address: loadRedisInMemoryClient(t).Addr(), failures: failures}
+	c, err := NewClient(context.Background(), append([]ClientOps{
+		WithRedisConnection(&cache.Client{Pool: &redis.Pool{Dial: dialer.dial}}),
+	}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c, dialer
+}
+
+// TestClient_Retry will test the option WithRetry() using a flaky connection
+func TestClient_Retry(t *testing.T) {
+
+	t.Run("no retries by default", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 1)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		assert.Equal(t, 1, dialer.attempts)
+	})
+
+	t.Run("succeeds after the failures", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 2, WithRetry(3, time.Millisecond))
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		assert.Equal(t, 3, dialer.attempts)
+
+		dialer.failures = 5
+		value, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+	})
+
+	t.Run("fails after the retries are used", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 5, WithRetry(2, time.Millisecond))
+
+		_, err := c.Get(context.Background(), testKey)
+		require.ErrorIs(t, err, syscall.ECONNREFUSED)
+		assert.Equal(t, 3, dialer.attempts)
+	})
+
+	t.Run("logical errors are not retried", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 0, WithRetry(3, time.Millisecond))
+
+		err := c.GetModel(context.Background(), testKey+"-missing", &genericStruct{})
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		assert.Equal(t, 1, dialer.attempts)
+
+		err = c.Set(context.Background(), "", testValue)
+		require.ErrorIs(t, err, ErrKeyRequired)
+		assert.Equal(t, 1, dialer.attempts)
+	})
+
+	t.Run("lock mismatch is not retried", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 0, WithRetry(3, time.Millisecond))
+
+		_, err := c.WriteLock(context.Background(), testKey, 30)
+		require.NoError(t, err)
+
+		_, err = c.ReleaseLock(context.Background(), testKey, "wrong-secret")
+		require.ErrorIs(t, err, cache.ErrLockMismatch)
+		assert.Equal(t, 2, dialer.attempts) // The lock and the release
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		c, dialer := newFlakyRedisClient(t, 5, WithRetry(5, time.Minute))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := c.Set(ctx, testKey, testValue)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, 1, dialer.attempts)
+	})
+}
+
+// Test_isRetryableError will test the method isRetryableError()
+func Test_isRetryableError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		err       error
+		name      string
+		retryable bool
+	}{
+		{name: "nil", err: nil, retryable: false},
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, retryable: true},
+		{name: "connection reset", err: syscall.ECONNRESET, retryable: true},
+		{name: "broken pipe", err: fmt.Errorf("write: %w", syscall.EPIPE), retryable: true},
+		{name: "eof", err: io.EOF, retryable: true},
+		{name: "unexpected eof", err: io.ErrUnexpectedEOF, retryable: true},
+		{name: "timeout", err: os.ErrDeadlineExceeded, retryable: true},
+		{name: "redis error", err: redis.Error("WRONGTYPE Operation against a key"), retryable: false},
+		{name: "nil reply", err: redis.ErrNil, retryable: false},
+		{name: "key required", err: ErrKeyRequired, retryable: false},
+		{name: "lock mismatch", err: cache.ErrLockMismatch, retryable: false},
+		{name: "context done", err: fmt.Errorf("%w: %w", ErrContextDone, context.DeadlineExceeded), retryable: false},
+		{name: "other", err: errors.New("other"), retryable: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.retryable, isRetryableError(test.err))
+		})
+	}
+}
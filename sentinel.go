@@ -0,0 +1,148 @@
+package cachestore
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/FZambia/sentinel"
+	"github.com/gomodule/redigo/redis"
+	"github.com/mrz1836/go-cache"
+	"github.com/mrz1836/go-cache/nrredis"
+)
+
+// sentinelMasterRole is the role of the master (ROLE command)
+const sentinelMasterRole = "master"
+
+// sentinelPool is the redis pool (master) that also closes the connections to the sentinels
+type sentinelPool struct {
+	*redis.Pool
+	sentinel *sentinel.Sentinel
+}
+
+// Close will close the pool and the connections to the sentinels
+func (p *sentinelPool) Close() error {
+	_ = p.sentinel.Close()
+	return p.Pool.Close()
+}
+
+// isSentinel will return true if the config is using Redis Sentinel
+func (r *RedisConfig) isSentinel() bool {
+	return len(r.SentinelAddresses) > 0 || len(r.MasterName) > 0
+}
+
+// validateSentinel will validate the Redis Sentinel configuration
+func (r *RedisConfig) validateSentinel() error {
+	if len(r.MasterName) > 0 && len(r.SentinelAddresses) == 0 {
+		return fmt.Errorf("%w: master name %q requires at least one sentinel address", ErrInvalidSentinelConfig, r.MasterName)
+	} else if len(r.SentinelAddresses) > 0 && len(r.MasterName) == 0 {
+		return fmt.Errorf("%w: sentinel addresses require a master name", ErrInvalidSentinelConfig)
+	}
+	return nil
+}
+
+// connectSentinel will create a pool for the master discovered by the sentinels
+//
+// Every new connection asks the sentinels for the current master, and connections
+// to a former master (after a failover) are discarded when borrowed from the pool
+func connectSentinel(ctx context.Context, config *RedisConfig, newRelicEnabled bool) (*cache.Client, error) {
+
+	// The URL of the master (password and database), the host is replaced
+	redisURL, err := config.connectionURL()
+	if err != nil {
+		return nil, err
+	}
+
+	// The sentinels to discover the master (the addresses are re-ordered, use a copy)
+	sntnl := &sentinel.Sentinel{
+		Addrs:      append([]string(nil), config.SentinelAddresses...),
+		MasterName: config.MasterName,
+		Dial: func(addr string) (redis.Conn, error) {
+			return redis.Dial("tcp", addr, config.dialOptions()...)
+		},
+	}
+
+	// Create the pool (dial the current master, the options keep the dial backoff of the pool and the ACL user)
+	options := append(config.dialOptions(), config.authDialOptions()...)
+	pool := &sentinelPool{
+		Pool: &redis.Pool{
+			Dial: func() (redis.Conn, error) {
+				masterAddr, err := sentinelMasterAddr(sntnl, len(config.SentinelAddresses))
+				if err != nil {
+					return nil, err
+				}
+				var masterURL string
+				if masterURL, err = sentinelMasterURL(redisURL, masterAddr); err != nil {
+					return nil, err
+				}
+				return cache.ConnectToURL(masterURL, options...)
+			},
+			IdleTimeout:     config.MaxIdleTimeout,
+			MaxActive:       config.MaxActiveConnections,
+			MaxConnLifetime: config.MaxConnectionLifetime,
+			MaxIdle:         config.MaxIdleConnections,
+			Wait:            config.PoolWaitTimeout > 0,
+			TestOnBorrow: func(c redis.Conn, _ time.Time) error {
+				if !sentinel.TestRole(c, sentinelMasterRole) {
+					return ErrSentinelRoleCheckFailed
+				}
+				return nil
+			},
+		},
+		sentinel: sntnl,
+	}
+
+	// Select the database (if set, see: WithRedisDatabase)
+	if config.database != nil {
+		selectDatabase(pool.Pool, *config.database)
+	}
+
+	// Wrap if NewRelic is enabled
+	client := &cache.Client{Pool: pool}
+	if newRelicEnabled {
+		client.Pool = nrredis.Wrap(
+			pool,
+			nrredis.WithHost(config.MasterName),
+			nrredis.WithPortPathOrID(strings.Join(config.SentinelAddresses, ",")),
+		)
+	}
+
+	// Register scripts if enabled
+	if config.DependencyMode {
+		if err = client.RegisterScripts(ctx); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// sentinelMasterAddr will ask the sentinels for the address of the current master
+//
+// A failed sentinel is moved to the bottom of the list while iterating, which can
+// skip the next sentinel, so the query is retried once for each sentinel
+func sentinelMasterAddr(sntnl *sentinel.Sentinel, attempts int) (masterAddr string, err error) {
+	for i := 0; i < attempts; i++ {
+		if masterAddr, err = sntnl.MasterAddr(); err == nil {
+			return
+		}
+	}
+	return
+}
+
+// sentinelMasterURL will replace the host of the redis URL with the master address
+//
+// Any credentials or database set in the URL are kept
+func sentinelMasterURL(redisURL, masterAddr string) (string, error) {
+	if len(redisURL) == 0 {
+		redisURL = RedisPrefix
+	}
+	u, err := url.Parse(redisURL)
+	if err != nil {
+		return "", err
+	}
+	u.Host = masterAddr
+	return u.String(), nil
+}
@@ -0,0 +1,152 @@
+package cachestore
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/shamaton/msgpack/v2"
+)
+
+// msgpackMarker is the first byte of the values encoded by the MsgpackSerializer
+//
+// Not a JSON byte, a compression header (0xc0 and 0xc1) or another marker (binary and version)
+const msgpackMarker byte = 0xc4
+
+// legacyMsgpackMarker is the marker of the values encoded before msgpackMarker (read only)
+//
+// It is the same byte as the snappy header, these values are only read without snappy compression
+const legacyMsgpackMarker byte = 0xc1
+
+// binaryMarker is the first byte of the models encoded using encoding.BinaryMarshaler (not used by JSON, msgpack or the compression)
+const binaryMarker byte = 0xc2
+
+// Serializer is used to encode and decode models (SetModel and GetModel)
+type Serializer interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONSerializer is the default serializer (encoding/json)
+//
+// The zero value behaves like json.Marshal and json.Unmarshal (see: WithJSONEncoderOptions)
+type JSONSerializer struct {
+	JSONEncoderOptions
+}
+
+// JSONEncoderOptions are the options of the JSON serializer (the defaults match encoding/json)
+type JSONEncoderOptions struct {
+	DisableHTMLEscape bool // Keep &, < and > as is (encoding/json escapes them as \u0026, \u003c and \u003e)
+	UseNumber         bool // Decode the numbers into an interface{} as a json.Number (not a float64, keeps large integers)
+}
+
+// Marshal will encode the model into JSON
+func (s *JSONSerializer) Marshal(v interface{}) ([]byte, error) {
+	if !s.DisableHTMLEscape {
+		return json.Marshal(v)
+	}
+
+	// The encoder adds a newline after the value (json.Marshal does not)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Unmarshal will decode the JSON into the model (model needs to be a pointer)
+func (s *JSONSerializer) Unmarshal(data []byte, v interface{}) error {
+	// Invalid data returns the json.Unmarshal error (the decoder would ignore the data after the first value)
+	if !s.UseNumber || !json.Valid(data) {
+		return json.Unmarshal(data, v)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// MsgpackSerializer is a msgpack serializer, faster and smaller than JSON (see: WithMsgpack)
+//
+// Structs are encoded as maps keyed by the field name or the msgpack tag (json tags are not used)
+// The values start with a marker byte, decoding a value that was not encoded using msgpack (ie: JSON) returns ErrNotMsgpack
+type MsgpackSerializer struct{}
+
+// Marshal will encode the model into msgpack (after the marker byte)
+func (s *MsgpackSerializer) Marshal(v interface{}) ([]byte, error) {
+	data, err := msgpack.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{msgpackMarker}, data...), nil
+}
+
+// Unmarshal will decode the msgpack into the model (model needs to be a pointer)
+func (s *MsgpackSerializer) Unmarshal(data []byte, v interface{}) error {
+	if len(data) == 0 || (data[0] != msgpackMarker && data[0] != legacyMsgpackMarker) {
+		return ErrNotMsgpack
+	}
+	return msgpack.Unmarshal(data[1:], v)
+}
+
+// marshalModel will encode the model using encoding.BinaryMarshaler (if implemented) or the configured serializer
+//
+// The binary encoding starts with a marker byte, a nil pointer always uses the serializer
+// The version header is added first (if enabled, see: WithModelVersioning)
+func (c *Client) marshalModel(ctx context.Context, model interface{}) (data []byte, err error) {
+	if marshaler, ok := model.(encoding.BinaryMarshaler); ok && !isNilPointer(model) {
+		if data, err = marshaler.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = append([]byte{binaryMarker}, data...)
+	} else if data, err = c.options.serializer.Marshal(model); err != nil {
+		return nil, err
+	}
+	return c.addVersion(ctx, data, model), nil
+}
+
+// unmarshalModel will decode the data into the model using the configured serializer
+//
+// A model implementing encoding.BinaryUnmarshaler decodes the values starting with the binary marker (see: marshalModel),
+// other values use the serializer (ie: stored before the model implemented encoding.BinaryMarshaler)
+// A slice or a map is reset first (the stored value replaces the entries, a stored nil is decoded as nil)
+// The error wraps both ErrModelUnmarshal and the serializer (or UnmarshalBinary) error
+// A stored version that does not match returns ErrModelVersionMismatch (if enabled, see: WithModelVersioning)
+func (c *Client) unmarshalModel(ctx context.Context, data []byte, model interface{}) (err error) {
+	if data, err = c.checkVersion(ctx, data, model); err != nil {
+		return err
+	}
+	if unmarshaler, ok := model.(encoding.BinaryUnmarshaler); ok && len(data) > 0 && data[0] == binaryMarker {
+		err = unmarshaler.UnmarshalBinary(data[1:])
+	} else {
+		resetCollection(model)
+		err = c.options.serializer.Unmarshal(data, model)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrModelUnmarshal, err)
+	}
+	return nil
+}
+
+// isNilPointer will return true if the model is a nil pointer
+func isNilPointer(model interface{}) bool {
+	value := reflect.ValueOf(model)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
+
+// resetCollection will set the slice or map of the pointer to nil (other models are not changed)
+//
+// JSON adds the entries to an existing map, and msgpack does not change the model for a nil value
+func resetCollection(model interface{}) {
+	value := reflect.ValueOf(model)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return
+	}
+	if elem := value.Elem(); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map {
+		elem.Set(reflect.Zero(elem.Type()))
+	}
+}
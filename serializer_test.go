@@ -0,0 +1,627 @@
+package cachestore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testURL is a URL with the characters escaped by encoding/json (&, < and >)
+const testURL = "https://example.com/search?a=1&b=<c>"
+
+// urlStruct is a model with a URL
+type urlStruct struct {
+	URL string `json:"url"`
+}
+
+// gobSerializer is an example serializer for testing (encoding/gob)
+type gobSerializer struct{}
+
+// Marshal will encode the model using gob
+func (s *gobSerializer) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Unmarshal will decode the gob data into the model
+func (s *gobSerializer) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// panicSerializer is a malformed serializer for testing (panics on a nil model)
+type panicSerializer struct{}
+
+// Marshal will panic on a nil model
+func (s *panicSerializer) Marshal(v interface{}) ([]byte, error) {
+	return []byte(v.(*genericStruct).StringField), nil
+}
+
+// Unmarshal will panic on a nil model
+func (s *panicSerializer) Unmarshal(data []byte, v interface{}) error {
+	v.(*genericStruct).StringField = string(data)
+	return nil
+}
+
+// binaryModel is a model with a compact binary encoding (encoding.BinaryMarshaler and encoding.BinaryUnmarshaler)
+type binaryModel struct {
+	ID   uint32 `json:"id"`
+	Name string `json:"name"`
+}
+
+// errBinaryModel is returned by MarshalBinary for a model without a name
+var errBinaryModel = errors.New("name is required")
+
+// MarshalBinary will encode the id (4 bytes, big endian) followed by the name
+func (m *binaryModel) MarshalBinary() ([]byte, error) {
+	if len(m.Name) == 0 {
+		return nil, errBinaryModel
+	}
+	return append(binary.BigEndian.AppendUint32(nil, m.ID), m.Name...), nil
+}
+
+// UnmarshalBinary will decode the id and the name
+func (m *binaryModel) UnmarshalBinary(data []byte) error {
+	if len(data) < 4 {
+		return errBinaryModel
+	}
+	m.ID, m.Name = binary.BigEndian.Uint32(data), string(data[4:])
+	return nil
+}
+
+// TestJSONSerializer will test the default JSON serializer
+func TestJSONSerializer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		s := &JSONSerializer{}
+		data, err := s.Marshal(&genericStruct{StringField: testValue, IntField: 123})
+		require.NoError(t, err)
+		assert.Equal(t, `{"bool_field":false,"float_field":0,"int_field":123,"string_field":"test-value"}`, string(data))
+
+		model := new(genericStruct)
+		err = s.Unmarshal(data, model)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, model.StringField)
+		assert.Equal(t, 123, model.IntField)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		s := &JSONSerializer{}
+		err := s.Unmarshal([]byte("not-json"), new(genericStruct))
+		require.Error(t, err)
+
+		s.UseNumber = true
+		err = s.Unmarshal([]byte("not-json"), new(genericStruct))
+		require.Error(t, err)
+		err = s.Unmarshal([]byte(`{"int_field":1} extra`), new(genericStruct))
+		require.Error(t, err)
+	})
+
+	t.Run("html is escaped by default", func(t *testing.T) {
+		data, err := (&JSONSerializer{}).Marshal(&urlStruct{URL: testURL})
+		require.NoError(t, err)
+		assert.Equal(t, `{"url":"https://example.com/search?a=1\u0026b=\u003cc\u003e"}`, string(data))
+	})
+
+	t.Run("disable html escape", func(t *testing.T) {
+		s := &JSONSerializer{JSONEncoderOptions: JSONEncoderOptions{DisableHTMLEscape: true}}
+		data, err := s.Marshal(&urlStruct{URL: testURL})
+		require.NoError(t, err)
+		assert.Equal(t, `{"url":"`+testURL+`"}`, string(data)) // No trailing newline
+
+		model := new(urlStruct)
+		require.NoError(t, s.Unmarshal(data, model))
+		assert.Equal(t, testURL, model.URL)
+	})
+
+	t.Run("use number", func(t *testing.T) {
+		data := []byte(`{"id":9007199254740993}`)
+
+		var model map[string]interface{}
+		require.NoError(t, (&JSONSerializer{}).Unmarshal(data, &model))
+		assert.InDelta(t, float64(9007199254740992), model["id"], 0) // Precision is lost
+
+		s := &JSONSerializer{JSONEncoderOptions: JSONEncoderOptions{UseNumber: true}}
+		require.NoError(t, s.Unmarshal(data, &model))
+		assert.Equal(t, json.Number("9007199254740993"), model["id"])
+	})
+}
+
+// nestedStruct is a complex model (nested structs, pointers, slices and maps)
+type nestedStruct struct {
+	Children  []genericStruct
+	CreatedAt time.Time
+	Data      []byte
+	Labels    map[string]int
+	Name      string
+	Parent    *genericStruct
+	Tags      []string
+}
+
+// newNestedStruct will return a complex model for testing
+func newNestedStruct() *nestedStruct {
+	return &nestedStruct{
+		Children: []genericStruct{
+			{StringField: "first", IntField: 1},
+			{StringField: "second", BoolField: true, FloatField: 2.5},
+		},
+		CreatedAt: time.Date(2024, 5, 6, 7, 8, 9, 10, time.UTC),
+		Data:      []byte{0x00, 0xc1, 0xff},
+		Labels:    map[string]int{"a": 1, "b": -2},
+		Name:      testValue,
+		Parent:    &genericStruct{StringField: "parent", IntField: 99},
+		Tags:      []string{"x", "y"},
+	}
+}
+
+// TestMsgpackSerializer will test the msgpack serializer
+func TestMsgpackSerializer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		s := &MsgpackSerializer{}
+		data, err := s.Marshal(&genericStruct{StringField: testValue, IntField: 123})
+		require.NoError(t, err)
+		assert.Equal(t, msgpackMarker, data[0])
+
+		model := new(genericStruct)
+		require.NoError(t, s.Unmarshal(data, model))
+		assert.Equal(t, &genericStruct{StringField: testValue, IntField: 123}, model)
+	})
+
+	t.Run("nested model", func(t *testing.T) {
+		s := &MsgpackSerializer{}
+		data, err := s.Marshal(newNestedStruct())
+		require.NoError(t, err)
+
+		model := new(nestedStruct)
+		require.NoError(t, s.Unmarshal(data, model))
+		expected := newNestedStruct()
+		assert.True(t, expected.CreatedAt.Equal(model.CreatedAt))
+		expected.CreatedAt, model.CreatedAt = time.Time{}, time.Time{}
+		assert.Equal(t, expected, model)
+	})
+
+	t.Run("json is not msgpack", func(t *testing.T) {
+		s := &MsgpackSerializer{}
+		data, err := (&JSONSerializer{}).Marshal(&genericStruct{StringField: testValue})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, s.Unmarshal(data, new(genericStruct)), ErrNotMsgpack)
+		require.ErrorIs(t, s.Unmarshal(nil, new(genericStruct)), ErrNotMsgpack)
+	})
+
+	t.Run("legacy marker", func(t *testing.T) {
+		s := &MsgpackSerializer{}
+		data, err := s.Marshal(&genericStruct{IntField: 123})
+		require.NoError(t, err)
+		data[0] = legacyMsgpackMarker
+
+		model := new(genericStruct)
+		require.NoError(t, s.Unmarshal(data, model))
+		assert.Equal(t, 123, model.IntField)
+	})
+
+	t.Run("the marker is not a compression header", func(t *testing.T) {
+		assert.NotEqual(t, compressionHeaderGzip, msgpackMarker)
+		assert.NotEqual(t, compressionHeaderSnappy, msgpackMarker)
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		s := &MsgpackSerializer{}
+		err := s.Unmarshal([]byte{msgpackMarker, 0xc1}, new(genericStruct))
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrNotMsgpack)
+	})
+}
+
+// TestClient_Msgpack will test SetModel() and GetModel() using WithMsgpack()
+func TestClient_Msgpack(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - nested model round trip", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMsgpack())
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, newNestedStruct(), time.Minute))
+
+			model := new(nestedStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, testValue, model.Name)
+			assert.Equal(t, newNestedStruct().Children, model.Children)
+			assert.Equal(t, newNestedStruct().Parent, model.Parent)
+			assert.Equal(t, newNestedStruct().Labels, model.Labels)
+			assert.Equal(t, newNestedStruct().Data, model.Data)
+
+			// Maps and slices of models
+			items := map[string][]*genericStruct{"items": {{IntField: 1}, {IntField: 2}}}
+			require.NoError(t, c.SetModel(context.Background(), testKey, items, 0))
+			var decoded map[string][]*genericStruct
+			require.NoError(t, c.GetModel(context.Background(), testKey, &decoded))
+			assert.Equal(t, items, decoded)
+		})
+
+		t.Run(testCase.name+" - snappy compression round trip", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMsgpack(),
+				WithCompression(CompressionSnappy), WithCompressionThreshold(256),
+			)
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// Below and above the compression threshold
+			for _, value := range []string{testValue, strings.Repeat(testValue, 50)} {
+				require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: value}, 0))
+
+				model := new(genericStruct)
+				require.NoError(t, c.GetModel(context.Background(), testKey, model))
+				assert.Equal(t, value, model.StringField)
+			}
+		})
+
+		t.Run(testCase.name+" - json values fail clearly", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithMsgpack())
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, `{"string_field":"test-value"}`))
+
+			err = c.GetModel(context.Background(), testKey, new(genericStruct))
+			require.ErrorIs(t, err, ErrModelUnmarshal)
+			require.ErrorIs(t, err, ErrNotMsgpack)
+		})
+	}
+}
+
+// BenchmarkSerializer_JSON will benchmark encoding and decoding a model using JSON
+func BenchmarkSerializer_JSON(b *testing.B) {
+	benchmarkSerializer(b, &JSONSerializer{})
+}
+
+// BenchmarkSerializer_Msgpack will benchmark encoding and decoding a model using msgpack
+func BenchmarkSerializer_Msgpack(b *testing.B) {
+	benchmarkSerializer(b, &MsgpackSerializer{})
+}
+
+// benchmarkSerializer will encode and decode the genericStruct using the serializer
+func benchmarkSerializer(b *testing.B, s Serializer) {
+	model := &genericStruct{BoolField: true, FloatField: 12.34, IntField: 123, StringField: testValue}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data, err := s.Marshal(model)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err = s.Unmarshal(data, new(genericStruct)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestClient_JSONEncoderOptions will test SetModel() and GetModel() using WithJSONEncoderOptions()
+func TestClient_JSONEncoderOptions(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - html is not escaped", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithJSONEncoderOptions(JSONEncoderOptions{
+				DisableHTMLEscape: true,
+			}))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, &urlStruct{URL: testURL}, 0)
+			require.NoError(t, err)
+
+			// Stored payload is identical to a non-escaping encoder (without the newline)
+			var buf bytes.Buffer
+			encoder := json.NewEncoder(&buf)
+			encoder.SetEscapeHTML(false)
+			require.NoError(t, encoder.Encode(&urlStruct{URL: testURL}))
+
+			var stored []byte
+			stored, err = c.GetBytes(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, bytes.TrimSuffix(buf.Bytes(), []byte("\n")), stored)
+
+			model := new(urlStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, testURL, model.URL)
+		})
+
+		t.Run(testCase.name+" - large integers are kept", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithJSONEncoderOptions(JSONEncoderOptions{
+				UseNumber: true,
+			}))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, map[string]uint64{"id": 9007199254740993}, 0)
+			require.NoError(t, err)
+
+			var model map[string]interface{}
+			require.NoError(t, c.GetModel(context.Background(), testKey, &model))
+			assert.Equal(t, json.Number("9007199254740993"), model["id"])
+		})
+	}
+}
+
+// TestClient_Serializer will test SetModel() and GetModel() using a custom serializer
+func TestClient_Serializer(t *testing.T) {
+
+	testModel := &genericStruct{
+		StringField: testValue,
+		IntField:    123,
+		BoolField:   true,
+		FloatField:  12.34,
+	}
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - round trip using gob", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithSerializer(&gobSerializer{}))
+			require.NotNil(t, c)
+			require.NoError(t, err)
+
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, testModel, time.Minute)
+			require.NoError(t, err)
+
+			// Stored payload is the gob encoding (not JSON)
+			var stored string
+			stored, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			expected, err := (&gobSerializer{}).Marshal(testModel)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), stored)
+
+			model := new(genericStruct)
+			err = c.GetModel(context.Background(), testKey, model)
+			require.NoError(t, err)
+			assert.Equal(t, testModel, model)
+		})
+	}
+
+	t.Run("["+FreeCache.String()+"] - mixing serializers returns an error", func(t *testing.T) {
+		freeClient := loadFreeCache(DefaultCacheSize, DefaultGCPercent, realClock{})
+
+		gobClient, err := NewClient(context.Background(),
+			WithFreeCacheConnection(freeClient), WithSerializer(&gobSerializer{}),
+		)
+		require.NoError(t, err)
+
+		var jsonClient ClientInterface
+		jsonClient, err = NewClient(context.Background(), WithFreeCacheConnection(freeClient))
+		require.NoError(t, err)
+
+		err = gobClient.SetModel(context.Background(), testKey, testModel, 0)
+		require.NoError(t, err)
+
+		model := new(genericStruct)
+		err = jsonClient.GetModel(context.Background(), testKey, model)
+		require.Error(t, err)
+		assert.Empty(t, model.StringField)
+	})
+}
+
+// Test_resetCollection will test the method resetCollection()
+func Test_resetCollection(t *testing.T) {
+	t.Parallel()
+
+	slice := []string{testValue}
+	resetCollection(&slice)
+	assert.Nil(t, slice)
+
+	m := map[string]int{testKey: 1}
+	resetCollection(&m)
+	assert.Nil(t, m)
+
+	// Other models, a nil pointer and a value are not changed
+	model := genericStruct{StringField: testValue}
+	resetCollection(&model)
+	assert.Equal(t, testValue, model.StringField)
+	resetCollection((*[]string)(nil))
+	resetCollection(nil)
+	slice = []string{testValue}
+	resetCollection(slice)
+	assert.Equal(t, []string{testValue}, slice)
+}
+
+// TestClient_BinaryMarshaler will test the models implementing encoding.BinaryMarshaler (SetModel and GetModel)
+func TestClient_BinaryMarshaler(t *testing.T) {
+
+	model := &binaryModel{ID: 42, Name: testValue}
+	encoded, err := model.MarshalBinary()
+	require.NoError(t, err)
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		for _, opts := range []ClientOps{
+			testCase.opts,
+			withClientOps(testCase.opts, WithMsgpack()),
+			withClientOps(testCase.opts, WithCompression(CompressionSnappy), WithCompressionThreshold(0)),
+		} {
+			t.Run(testCase.name+" - the binary encoding is stored", func(t *testing.T) {
+				c, err := NewClient(context.Background(), opts)
+				require.NoError(t, err)
+				defer func() {
+					_ = c.EmptyCache(context.Background())
+				}()
+
+				require.NoError(t, c.SetModel(context.Background(), testKey, model, 0))
+
+				out := new(binaryModel)
+				require.NoError(t, c.GetModel(context.Background(), testKey, out))
+				assert.Equal(t, model, out)
+
+				// The stored bytes are the marker and the binary form (after the decompression)
+				var stored []byte
+				stored, err = c.GetBytes(context.Background(), testKey)
+				require.NoError(t, err)
+				assert.Equal(t, append([]byte{binaryMarker}, encoded...), stored)
+			})
+		}
+
+		t.Run(testCase.name+" - a value stored using the serializer is still read", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.Set(context.Background(), testKey, `{"id":7,"name":"json"}`))
+			out := new(binaryModel)
+			require.NoError(t, c.GetModel(context.Background(), testKey, out))
+			assert.Equal(t, &binaryModel{ID: 7, Name: "json"}, out)
+		})
+
+		t.Run(testCase.name+" - a model without the binary interfaces uses the serializer", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+			value, err := c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, `{"bool_field":false,"float_field":0,"int_field":0,"string_field":"`+testValue+`"}`, value)
+
+			// A binary value cannot be decoded into another model
+			require.NoError(t, c.SetModel(context.Background(), testKey, model, 0))
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey, new(genericStruct)), ErrModelUnmarshal)
+		})
+
+		t.Run(testCase.name+" - errors", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			// MarshalBinary fails, nothing is stored
+			err = c.SetModel(context.Background(), testKey, &binaryModel{ID: 1}, 0)
+			require.ErrorIs(t, err, errBinaryModel)
+			var found bool
+			found, err = c.Exists(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			// UnmarshalBinary fails
+			require.NoError(t, c.SetBytes(context.Background(), testKey, []byte{binaryMarker, 0}))
+			err = c.GetModel(context.Background(), testKey, new(binaryModel))
+			require.ErrorIs(t, err, ErrModelUnmarshal)
+			require.ErrorIs(t, err, errBinaryModel)
+
+			// A nil pointer uses the serializer
+			require.NoError(t, c.SetModel(context.Background(), testKey, (*binaryModel)(nil), 0))
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, "null", value)
+		})
+	}
+}
+
+// TestClient_PanicRecovery will test a serializer that panics (see: WithPanicRecovery)
+func TestClient_PanicRecovery(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - the panic is returned as an error", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts,
+				WithSerializer(&panicSerializer{}), WithPanicRecovery(),
+			)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			err = c.SetModel(context.Background(), testKey, nil, time.Minute)
+			require.ErrorIs(t, err, ErrInternalPanic)
+			assert.Contains(t, err.Error(), "interface conversion")
+
+			// The recovered error is wrapped
+			var runtimeErr runtime.Error
+			require.ErrorAs(t, err, &runtimeErr)
+
+			// Reading into a nil model
+			err = c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, time.Minute)
+			require.NoError(t, err)
+			err = c.GetModel(context.Background(), testKey, nil)
+			require.ErrorIs(t, err, ErrInternalPanic)
+
+			// The client still works
+			model := new(genericStruct)
+			err = c.GetModel(context.Background(), testKey, model)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, model.StringField)
+		})
+	}
+
+	t.Run("a value that is not an error", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}), WithPanicRecovery())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		err = c.Scan(context.Background(), "*", func(string) error {
+			panic("scan failed")
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+		err = c.Scan(context.Background(), "*", func(string) error {
+			panic("scan failed")
+		})
+		require.ErrorIs(t, err, ErrInternalPanic)
+		assert.Contains(t, err.Error(), "scan failed")
+	})
+
+	t.Run("disabled by default (fail fast)", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithSerializer(&panicSerializer{}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		assert.Panics(t, func() {
+			_ = c.SetModel(context.Background(), testKey, nil, time.Minute)
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package cachestore
+
+import (
+	"context"
+)
+
+// Prefixes of the shared read keys (the value and model reads of a key return different results)
+const (
+	sharedReadModel = "model:"
+	sharedReadValue = "value:"
+)
+
+// sharedValue is the result of a shared value read (see: readValue)
+type sharedValue struct {
+	found bool
+	value string
+}
+
+// shareRead will run the read once for the concurrent callers of the same key, all callers share the result (see: WithSingleFlight)
+//
+// The read is called directly if single flight is not enabled
+// The read uses the context of the first caller without the cancellation (limited by WithOperationTimeout),
+// each caller stops waiting when its own context is done
+func (c *Client) shareRead(ctx context.Context, key string,
+	read func(ctx context.Context) (interface{}, error),
+) (interface{}, error) {
+	if c.options.reads == nil {
+		return read(ctx)
+	}
+
+	results := c.options.reads.DoChan(key, func() (interface{}, error) {
+		readCtx, cancel := c.withTimeout(withoutCancel(ctx))
+		defer cancel()
+		return read(readCtx)
+	})
+
+	// A nil context is never done
+	var done <-chan struct{}
+	if ctx != nil {
+		done = ctx.Done()
+	}
+	select {
+	case <-done:
+		return nil, checkContext(ctx)
+	case result := <-results:
+		return result.Val, result.Err
+	}
+}
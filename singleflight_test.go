@@ -0,0 +1,210 @@
+package cachestore
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/mrz1836/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowReadConn is a redis connection that counts the GET commands and delays the replies
+type slowReadConn struct {
+	redis.Conn
+	delay time.Duration
+	gets  *atomic.Int64
+}
+
+// Do will count and delay a GET command
+func (s *slowReadConn) Do(commandName string, args ...interface{}) (interface{}, error) {
+	s.read(commandName)
+	return s.Conn.Do(commandName, args...)
+}
+
+// DoContext will count and delay a GET command (the pool requires a connection with a context)
+func (s *slowReadConn) DoContext(ctx context.Context, commandName string, args ...interface{}) (interface{}, error) {
+	s.read(commandName)
+	return s.Conn.(redis.ConnWithContext).DoContext(ctx, commandName, args...)
+}
+
+// read will count and delay a GET command
+func (s *slowReadConn) read(commandName string) {
+	if strings.EqualFold(commandName, "GET") {
+		s.gets.Add(1)
+		time.Sleep(s.delay)
+	}
+}
+
+// newSlowReadRedisClient will return a client where each GET is counted and takes the delay
+func newSlowReadRedisClient(t *testing.T, delay time.Duration, opts ...ClientOps) (ClientInterface, *atomic.Int64) {
+	address := loadRedisInMemoryClient(t).Addr()
+	gets := new(atomic.Int64)
+	c, err := NewClient(context.Background(), append([]ClientOps{
+		WithRedisConnection(&cache.Client{Pool: &redis.Pool{Dial: func() (redis.Conn, error) {
+			conn, err := redis.Dial("tcp", address)
+			if err != nil {
+				return nil, err
+			}
+			return &slowReadConn{Conn: conn, delay: delay, gets: gets}, nil
+		}}}),
+	}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c, gets
+}
+
+// concurrently will run the function from many goroutines at the same time
+func concurrently(callers int, fn func()) {
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			fn()
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+// TestClient_SingleFlight will test the option WithSingleFlight()
+func TestClient_SingleFlight(t *testing.T) {
+
+	const callers = 50
+
+	t.Run("concurrent gets share one read", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 100*time.Millisecond, WithSingleFlight())
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		var values sync.Map
+		concurrently(callers, func() {
+			value, err := c.Get(context.Background(), testKey)
+			values.Store(value, err)
+		})
+		assert.Equal(t, int64(1), gets.Load())
+
+		err, ok := values.Load(testValue)
+		require.True(t, ok)
+		require.Nil(t, err)
+
+		// Each caller is still counted
+		assert.Equal(t, int64(callers), c.Stats().Hits)
+	})
+
+	t.Run("concurrent misses share one read", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 100*time.Millisecond, WithSingleFlight())
+
+		var failed atomic.Int64
+		concurrently(callers, func() {
+			if value, err := c.Get(context.Background(), testKey); err != nil || value != "" {
+				failed.Add(1)
+			}
+		})
+		assert.Equal(t, int64(1), gets.Load())
+		assert.Equal(t, int64(0), failed.Load())
+		assert.Equal(t, int64(callers), c.Stats().Misses)
+	})
+
+	t.Run("concurrent get models share one read", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 100*time.Millisecond, WithSingleFlight())
+		testModel := &genericStruct{StringField: testValue, IntField: 123}
+		require.NoError(t, c.SetModel(context.Background(), testKey, testModel, 0))
+
+		var failed atomic.Int64
+		concurrently(callers, func() {
+			model := new(genericStruct)
+			if err := c.GetModel(context.Background(), testKey, model); err != nil || *model != *testModel {
+				failed.Add(1)
+			}
+		})
+		assert.Equal(t, int64(1), gets.Load())
+		assert.Equal(t, int64(0), failed.Load())
+	})
+
+	t.Run("value and model reads are not shared", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 100*time.Millisecond, WithSingleFlight())
+		require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+
+		var calls atomic.Int64
+		concurrently(2, func() {
+			if calls.Add(1) == 1 {
+				_, _ = c.Get(context.Background(), testKey)
+			} else {
+				_ = c.GetModel(context.Background(), testKey, new(genericStruct))
+			}
+		})
+		assert.Equal(t, int64(2), gets.Load())
+	})
+
+	t.Run("each get reads without single flight", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 10*time.Millisecond)
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		concurrently(callers, func() {
+			_, _ = c.Get(context.Background(), testKey)
+		})
+		assert.Equal(t, int64(callers), gets.Load())
+	})
+
+	t.Run("a caller stops waiting when its context is done", func(t *testing.T) {
+		c, gets := newSlowReadRedisClient(t, 200*time.Millisecond, WithSingleFlight())
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		var value string
+		var err error
+		go func() {
+			defer wg.Done()
+			value, err = c.Get(context.Background(), testKey)
+		}()
+
+		// Wait for the shared read to start
+		require.Eventually(t, func() bool { return gets.Load() == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, ctxErr := c.Get(ctx, testKey)
+		require.ErrorIs(t, ctxErr, ErrContextDone)
+
+		// The first caller still gets the value
+		wg.Wait()
+		require.NoError(t, err)
+		assert.Equal(t, testValue, value)
+		assert.Equal(t, int64(1), gets.Load())
+	})
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - get and get model", func(t *testing.T) {
+			c, err := NewClient(context.Background(), testCase.opts, WithSingleFlight())
+			require.NoError(t, err)
+			defer c.Close(context.Background())
+
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			var value string
+			value, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			assert.Equal(t, testValue, value)
+
+			testModel := &genericStruct{StringField: testValue}
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", testModel, 0))
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey+"-model", model))
+			assert.Equal(t, testModel, model)
+
+			err = c.GetModel(context.Background(), testKey+"-missing", model)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+		})
+	}
+}
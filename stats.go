@@ -0,0 +1,156 @@
+package cachestore
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Stats are the cache statistics since the client was created (or the stats were reset)
+//
+// Hits and misses are counted per key for Get, GetBytes, GetModel, GetModelWithTTL, GetMulti, GetOrSet and GetOrSetModel
+// Sets and deletes are counted per key for successful operations
+type Stats struct {
+	Deletes          int64 `json:"deletes"`           // Keys deleted (DeleteByPattern and DeleteDependency count the keys removed)
+	FreeCacheEntries int64 `json:"freecache_entries"` // Entries stored in FreeCache (freecache or the local tier)
+	FreeCacheHits    int64 `json:"freecache_hits"`    // Native FreeCache hit count (includes locks and counters)
+	FreeCacheMisses  int64 `json:"freecache_misses"`  // Native FreeCache miss count (includes locks and counters)
+	Hits             int64 `json:"hits"`              // Keys found
+	Misses           int64 `json:"misses"`            // Keys not found
+	Sets             int64 `json:"sets"`              // Keys stored
+}
+
+// FreeCacheStats are the native statistics of the FreeCache instance (FreeCache or the local tier)
+//
+// The counts include locks and counters, and are reset by ResetStats()
+type FreeCacheStats struct {
+	EntryCount    int64 `json:"entry_count"`    // Entries currently stored
+	EvacuateCount int64 `json:"evacuate_count"` // Entries evicted to make room for new entries (memory pressure)
+	ExpiredCount  int64 `json:"expired_count"`  // Entries removed after they expired
+	HitCount      int64 `json:"hit_count"`      // Lookups that found the key
+	MissCount     int64 `json:"miss_count"`     // Lookups that did not find the key
+}
+
+// PoolStats are the statistics of the redis connection pool (see: RedisConfig.MaxActiveConnections)
+//
+// A cluster combines the pools of all the nodes, the read replicas are not included
+type PoolStats struct {
+	ActiveCount  int           `json:"active_count"`  // Connections in the pool (idle and in use)
+	IdleCount    int           `json:"idle_count"`    // Idle connections
+	InUseCount   int           `json:"in_use_count"`  // Connections borrowed (active - idle)
+	WaitCount    int64         `json:"wait_count"`    // Total connections waited for (see: RedisConfig.PoolWaitTimeout)
+	WaitDuration time.Duration `json:"wait_duration"` // Total time waiting for a connection
+}
+
+// HitRatio will return the ratio of hits to reads (0 if there were no reads)
+func (s Stats) HitRatio() float64 {
+	if reads := s.Hits + s.Misses; reads > 0 {
+		return float64(s.Hits) / float64(reads)
+	}
+	return 0
+}
+
+// statsCounters are the counters for the cache statistics (updated atomically)
+type statsCounters struct {
+	deletes atomic.Int64
+	hits    atomic.Int64
+	misses  atomic.Int64
+	sets    atomic.Int64
+}
+
+// read will count a hit or a miss (failed reads are not counted)
+func (s *statsCounters) read(found bool, err error) {
+	if err != nil {
+		return
+	} else if found {
+		s.hits.Add(1)
+		return
+	}
+	s.misses.Add(1)
+}
+
+// readModel will count a hit or a miss for a model (ErrKeyNotFound is a miss)
+func (s *statsCounters) readModel(err error) {
+	if errors.Is(err, ErrKeyNotFound) {
+		s.read(false, nil)
+		return
+	}
+	s.read(true, err)
+}
+
+// stored will count the keys stored (failed writes are not counted)
+func (s *statsCounters) stored(keys int, err error) {
+	if err == nil {
+		s.sets.Add(int64(keys))
+	}
+}
+
+// deleted will count the keys deleted (failed deletes are not counted)
+func (s *statsCounters) deleted(keys int, err error) {
+	if err == nil {
+		s.deletes.Add(int64(keys))
+	}
+}
+
+// Stats will return the cache statistics (across all engines)
+func (c *Client) Stats() Stats {
+	stats := Stats{
+		Deletes: c.options.stats.deletes.Load(),
+		Hits:    c.options.stats.hits.Load(),
+		Misses:  c.options.stats.misses.Load(),
+		Sets:    c.options.stats.sets.Load(),
+	}
+
+	// Native FreeCache statistics
+	if c.options.freeCache != nil {
+		stats.FreeCacheEntries = c.options.freeCache.EntryCount()
+		stats.FreeCacheHits = c.options.freeCache.HitCount()
+		stats.FreeCacheMisses = c.options.freeCache.MissCount()
+	}
+	return stats
+}
+
+// ResetStats will reset the cache statistics to zero (including the native FreeCache statistics)
+func (c *Client) ResetStats() {
+	c.options.stats.deletes.Store(0)
+	c.options.stats.hits.Store(0)
+	c.options.stats.misses.Store(0)
+	c.options.stats.sets.Store(0)
+	if c.options.freeCache != nil {
+		c.options.freeCache.ResetStatistics()
+	}
+}
+
+// FreeCacheStats will return the native statistics of the FreeCache instance
+//
+// Returns ErrEngineNotSupported if the engine does not use FreeCache (freecache or the local tier)
+func (c *Client) FreeCacheStats() (*FreeCacheStats, error) {
+	freeCacheClient := c.options.freeCache
+	if freeCacheClient == nil {
+		return nil, c.engineNotSupported()
+	}
+	return &FreeCacheStats{
+		EntryCount:    freeCacheClient.EntryCount(),
+		EvacuateCount: freeCacheClient.EvacuateCount(),
+		ExpiredCount:  freeCacheClient.ExpiredCount(),
+		HitCount:      freeCacheClient.HitCount(),
+		MissCount:     freeCacheClient.MissCount(),
+	}, nil
+}
+
+// PoolStats will return the live statistics of the redis connection pool (read-only diagnostics)
+//
+// Returns ErrEngineNotSupported if the engine does not use redis (redis or tiered)
+func (c *Client) PoolStats() (*PoolStats, error) {
+	if !c.Engine().usesRedis() || c.options.redis == nil || c.options.redis.Pool == nil {
+		return nil, c.engineNotSupported()
+	}
+	stats := c.options.redis.Pool.Stats()
+	return &PoolStats{
+		ActiveCount:  stats.ActiveCount,
+		IdleCount:    stats.IdleCount,
+		InUseCount:   stats.ActiveCount - stats.IdleCount,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: stats.WaitDuration,
+	}, nil
+}
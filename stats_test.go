@@ -0,0 +1,267 @@
+package cachestore
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStats_HitRatio will test the method HitRatio()
+func TestStats_HitRatio(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no reads", func(t *testing.T) {
+		assert.InDelta(t, 0, Stats{}.HitRatio(), 0)
+	})
+
+	t.Run("hits and misses", func(t *testing.T) {
+		assert.InDelta(t, 0.75, Stats{Hits: 3, Misses: 1}.HitRatio(), 0)
+	})
+}
+
+// TestClient_Stats will test the methods Stats() and ResetStats()
+func TestClient_Stats(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		t.Run(testCase.name+" - count operations", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			assert.Equal(t, Stats{}, c.Stats())
+
+			// Sets
+			require.NoError(t, c.Set(context.Background(), testKey, testValue))
+			require.NoError(t, c.SetModel(context.Background(), testKey+"-model", &genericStruct{StringField: testValue}, 0))
+			require.NoError(t, c.SetMulti(context.Background(), map[string]string{
+				testKey + "-1": testValue,
+				testKey + "-2": testValue,
+			}))
+
+			// Failed operations are not counted
+			require.ErrorIs(t, c.Set(context.Background(), "", testValue), ErrKeyRequired)
+			_, err = c.Get(context.Background(), "")
+			require.ErrorIs(t, err, ErrKeyRequired)
+
+			// Hits and misses
+			_, err = c.Get(context.Background(), testKey)
+			require.NoError(t, err)
+			_, err = c.Get(context.Background(), testKey+"-missing")
+			require.NoError(t, err)
+			require.NoError(t, c.GetModel(context.Background(), testKey+"-model", &genericStruct{}))
+			require.ErrorIs(t, c.GetModel(context.Background(), testKey+"-missing", &genericStruct{}), ErrKeyNotFound)
+			_, err = c.GetMulti(context.Background(), testKey+"-1", testKey+"-2", testKey+"-missing")
+			require.NoError(t, err)
+
+			// Deletes
+			require.NoError(t, c.Delete(context.Background(), testKey))
+			require.NoError(t, c.DeleteMany(context.Background(), testKey+"-1", testKey+"-2"))
+
+			stats := c.Stats()
+			assert.Equal(t, int64(4), stats.Sets)
+			assert.Equal(t, int64(4), stats.Hits)
+			assert.Equal(t, int64(3), stats.Misses)
+			assert.Equal(t, int64(3), stats.Deletes)
+			assert.InDelta(t, 4.0/7.0, stats.HitRatio(), 0.0001)
+
+			// Native FreeCache statistics
+			if testCase.engine == FreeCache {
+				assert.Equal(t, int64(1), stats.FreeCacheEntries)
+				assert.Positive(t, stats.FreeCacheHits)
+				assert.Positive(t, stats.FreeCacheMisses)
+			} else {
+				assert.Equal(t, int64(0), stats.FreeCacheEntries)
+			}
+
+			// Reset
+			c.ResetStats()
+			stats = c.Stats()
+			assert.Equal(t, int64(0), stats.Sets)
+			assert.Equal(t, int64(0), stats.Hits)
+			assert.Equal(t, int64(0), stats.Misses)
+			assert.Equal(t, int64(0), stats.Deletes)
+			assert.Equal(t, int64(0), stats.FreeCacheHits)
+			assert.Equal(t, int64(0), stats.FreeCacheMisses)
+		})
+
+		t.Run(testCase.name+" - concurrent access", func(t *testing.T) {
+			c, err := NewClient(context.Background(), sharedClientOpts(testCase))
+			require.NoError(t, err)
+			require.NotNil(t, c)
+			defer c.Close(context.Background())
+
+			const workers = 10
+			const operations = 20
+
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(worker int) {
+					defer wg.Done()
+					for j := 0; j < operations; j++ {
+						key := testKey + "-" + strconv.Itoa(worker) + "-" + strconv.Itoa(j)
+						assert.NoError(t, c.Set(context.Background(), key, testValue))
+						_, getErr := c.Get(context.Background(), key)
+						assert.NoError(t, getErr)
+						_, getErr = c.Get(context.Background(), key+"-missing")
+						assert.NoError(t, getErr)
+						_ = c.Stats()
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			stats := c.Stats()
+			assert.Equal(t, int64(workers*operations), stats.Sets)
+			assert.Equal(t, int64(workers*operations), stats.Hits)
+			assert.Equal(t, int64(workers*operations), stats.Misses)
+		})
+	}
+}
+
+// TestClient_FreeCacheStats will test the method FreeCacheStats()
+func TestClient_FreeCacheStats(t *testing.T) {
+
+	t.Run("["+FreeCache.String()+"] - entries, hits and misses", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(i), testValue))
+		}
+		_, err = c.Get(context.Background(), testKey+"-0")
+		require.NoError(t, err)
+		_, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+
+		var stats *FreeCacheStats
+		stats, err = c.FreeCacheStats()
+		require.NoError(t, err)
+		require.NotNil(t, stats)
+		assert.Equal(t, int64(5), stats.EntryCount)
+		assert.Equal(t, int64(1), stats.HitCount)
+		assert.Equal(t, int64(1), stats.MissCount)
+		assert.Equal(t, int64(0), stats.EvacuateCount)
+		assert.Equal(t, int64(0), stats.ExpiredCount)
+	})
+
+	t.Run("["+Tiered.String()+"] - local tier", func(t *testing.T) {
+		c, _, _ := newTieredTestClient(t)
+
+		require.NoError(t, c.Set(context.Background(), testKey, testValue))
+
+		stats, err := c.FreeCacheStats()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.EntryCount)
+	})
+
+	t.Run("["+Redis.String()+"] - not supported", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{URL: r.Addr()}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var stats *FreeCacheStats
+		stats, err = c.FreeCacheStats()
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+		assert.Nil(t, stats)
+	})
+
+	t.Run("concurrent access", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				assert.NoError(t, c.Set(context.Background(), testKey+"-"+strconv.Itoa(worker), testValue))
+				_, statsErr := c.FreeCacheStats()
+				assert.NoError(t, statsErr)
+			}(i)
+		}
+		wg.Wait()
+
+		stats, err := c.FreeCacheStats()
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), stats.EntryCount)
+	})
+}
+
+// TestClient_PoolStats will test the method PoolStats()
+func TestClient_PoolStats(t *testing.T) {
+
+	t.Run("["+Redis.String()+"] - a borrowed connection is active", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithRedis(&RedisConfig{
+			MaxIdleConnections: 5,
+			PoolWaitTimeout:    time.Second,
+			URL:                r.Addr(),
+		}))
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var stats *PoolStats
+		stats, err = c.PoolStats()
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.InUseCount)
+		before := stats.ActiveCount
+
+		conn, err := c.Redis().GetConnectionWithContext(context.Background())
+		require.NoError(t, err)
+
+		stats, err = c.PoolStats()
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.InUseCount)
+		assert.Equal(t, max(before, 1), stats.ActiveCount)
+		assert.Equal(t, stats.ActiveCount-1, stats.IdleCount)
+
+		// Returned to the pool (idle)
+		c.Redis().CloseConnection(conn)
+		stats, err = c.PoolStats()
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.InUseCount)
+		assert.Equal(t, stats.ActiveCount, stats.IdleCount)
+		assert.GreaterOrEqual(t, stats.ActiveCount, 1)
+		assert.Equal(t, int64(0), stats.WaitCount)
+	})
+
+	t.Run("["+Tiered.String()+"] - redis pool", func(t *testing.T) {
+		c, _, _ := newTieredTestClient(t)
+
+		stats, err := c.PoolStats()
+		require.NoError(t, err)
+		require.NotNil(t, stats)
+	})
+
+	t.Run("["+Redis.String()+"] [cluster] - all the nodes", func(t *testing.T) {
+		c := newClusterTestClient(t, loadRedisInMemoryCluster(t))
+
+		stats, err := c.PoolStats()
+		require.NoError(t, err)
+		require.NotNil(t, stats)
+		assert.GreaterOrEqual(t, stats.ActiveCount, stats.IdleCount)
+	})
+
+	t.Run("["+FreeCache.String()+"] - not supported", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		var stats *PoolStats
+		stats, err = c.PoolStats()
+		require.ErrorIs(t, err, ErrEngineNotSupported)
+		assert.Contains(t, err.Error(), FreeCache.String())
+		assert.Nil(t, stats)
+	})
+}
@@ -0,0 +1,91 @@
+package cachestore
+
+import (
+	"context"
+	"time"
+)
+
+// isTiered will return true if the client is using a local tier in front of Redis
+func (c *Client) isTiered() bool {
+	return c.Engine() == Tiered && c.options.freeCache != nil
+}
+
+// getLocal will return the value from the local tier (false if not found or not tiered)
+func (c *Client) getLocal(key string) ([]byte, bool) {
+	if !c.isTiered() {
+		return nil, false
+	}
+	data, err := c.options.freeCache.Get([]byte(key))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// setLocal will store the value in the local tier (if tiered)
+//
+// The local copy expires after the local ttl, or sooner if the ttl of the key is shorter
+// Errors are ignored (value too large), the value is still stored in Redis
+func (c *Client) setLocal(key string, value []byte, ttl time.Duration) {
+	if !c.isTiered() {
+		return
+	}
+	_ = c.options.freeCache.Set([]byte(key), value, localTTLSeconds(c.options.localTTL, ttl))
+}
+
+// deleteLocal will remove the keys from the local tier (if tiered)
+func (c *Client) deleteLocal(keys ...string) {
+	if !c.isTiered() {
+		return
+	}
+	for _, key := range keys {
+		_ = c.options.freeCache.Del([]byte(key))
+	}
+}
+
+// localTTLSeconds will return the expiration (seconds) of a local copy (at least one second)
+func localTTLSeconds(localTTL, ttl time.Duration) int {
+	if ttl > 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	if seconds := int(localTTL.Seconds()); seconds > 0 {
+		return seconds
+	}
+	return 1
+}
+
+// getMultiTiered will return the values from the local tier, and fetch the missing keys from Redis
+//
+// The values fetched from Redis are stored in the local tier
+func (c *Client) getMultiTiered(ctx context.Context, keys []string) (map[string]string, error) {
+
+	// Not tiered, get all the keys from Redis
+	if !c.isTiered() {
+		return getMultiRedis(ctx, c.options.redis, keys)
+	}
+
+	// Check the local tier first
+	values := make(map[string]string, len(keys))
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if data, ok := c.getLocal(key); ok {
+			values[key] = string(data)
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+
+	// Fetch the missing keys from Redis
+	fetched, err := getMultiRedis(ctx, c.options.redis, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range fetched {
+		values[key] = value
+		c.setLocal(key, []byte(value), 0)
+	}
+	return values, nil
+}
@@ -0,0 +1,272 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/coocood/freecache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTieredTestClient will load a tiered client (local FreeCache in front of an in-memory Redis)
+func newTieredTestClient(t *testing.T) (ClientInterface, *miniredis.Miniredis, *freecache.Cache) {
+	r := loadRedisInMemoryClient(t)
+	local := freecache.NewCache(DefaultCacheSize)
+
+	c, err := NewClient(context.Background(), WithTieredCache(local, &RedisConfig{
+		URL: r.Addr(),
+	}, time.Minute))
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	t.Cleanup(func() {
+		c.Close(context.Background())
+	})
+	return c, r, local
+}
+
+// Test_localTTLSeconds will test the method localTTLSeconds()
+func Test_localTTLSeconds(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		name     string
+		localTTL time.Duration
+		ttl      time.Duration
+		expected int
+	}{
+		{"no ttl uses the local ttl", time.Minute, 0, 60},
+		{"shorter ttl is used", time.Minute, 10 * time.Second, 10},
+		{"longer ttl uses the local ttl", time.Minute, time.Hour, 60},
+		{"at least one second", time.Minute, time.Millisecond, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, localTTLSeconds(test.localTTL, test.ttl))
+		})
+	}
+}
+
+// TestClient_Tiered will test using a local FreeCache in front of Redis
+func TestClient_Tiered(t *testing.T) {
+
+	t.Run("new client without a local cache", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithTieredCache(nil, &RedisConfig{
+			URL: r.Addr(),
+		}, 0))
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		defer c.Close(context.Background())
+
+		assert.Equal(t, Tiered, c.Engine())
+		assert.NotNil(t, c.FreeCache())
+		assert.NotNil(t, c.Redis())
+	})
+
+	t.Run("set writes to both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.SetTTL(context.Background(), testKey, testValue, 10*time.Second)
+		require.NoError(t, err)
+
+		val, err := r.Get(testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+
+		var data []byte
+		data, err = local.Get([]byte(testKey))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(data))
+
+		// The local copy does not outlive the key
+		var ttl uint32
+		ttl, err = local.TTL([]byte(testKey))
+		require.NoError(t, err)
+		assert.LessOrEqual(t, ttl, uint32(10))
+	})
+
+	t.Run("get uses the local tier first (stale until the local ttl)", func(t *testing.T) {
+		c, r, _ := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+
+		// Changed by another node
+		require.NoError(t, r.Set(testKey, testValue+"-changed"))
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+	})
+
+	t.Run("get falls back to redis and populates the local tier", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		require.NoError(t, r.Set(testKey, testValue))
+
+		val, err := c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, testValue, val)
+
+		var data []byte
+		data, err = local.Get([]byte(testKey))
+		require.NoError(t, err)
+		assert.Equal(t, testValue, string(data))
+
+		var found bool
+		found, err = c.Exists(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.True(t, found)
+
+		val, err = c.Get(context.Background(), testKey+"-missing")
+		require.NoError(t, err)
+		assert.Empty(t, val)
+	})
+
+	t.Run("delete removes from both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+
+		err = c.Delete(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.False(t, r.Exists(testKey))
+
+		_, err = local.Get([]byte(testKey))
+		require.ErrorIs(t, err, freecache.ErrNotFound)
+	})
+
+	t.Run("delete many removes from both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey+"-1", testValue)
+		require.NoError(t, err)
+		err = c.Set(context.Background(), testKey+"-2", testValue)
+		require.NoError(t, err)
+
+		err = c.DeleteMany(context.Background(), testKey+"-1", testKey+"-2")
+		require.NoError(t, err)
+		assert.Empty(t, r.Keys())
+		assert.Equal(t, int64(0), local.EntryCount())
+	})
+
+	t.Run("delete by pattern removes from both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), "user:123:name", testValue)
+		require.NoError(t, err)
+		err = c.Set(context.Background(), "user:456:name", testValue)
+		require.NoError(t, err)
+
+		var total int
+		total, err = c.DeleteByPattern(context.Background(), "user:123:*")
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []string{"user:456:name"}, r.Keys())
+
+		_, err = local.Get([]byte("user:123:name"))
+		require.ErrorIs(t, err, freecache.ErrNotFound)
+		_, err = local.Get([]byte("user:456:name"))
+		require.NoError(t, err)
+	})
+
+	t.Run("set and get a model", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		model := &genericStruct{StringField: testValue, IntField: 123}
+		err := c.SetModel(context.Background(), testKey, model, 0)
+		require.NoError(t, err)
+		assert.True(t, r.Exists(testKey))
+
+		// Only in redis (another node)
+		local.Clear()
+
+		result := new(genericStruct)
+		err = c.GetModel(context.Background(), testKey, result)
+		require.NoError(t, err)
+		assert.Equal(t, model.StringField, result.StringField)
+		assert.Equal(t, model.IntField, result.IntField)
+
+		_, err = local.Get([]byte(testKey))
+		require.NoError(t, err)
+	})
+
+	t.Run("get multi mixes both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey+"-1", testValue+"-1")
+		require.NoError(t, err)
+		require.NoError(t, r.Set(testKey+"-2", testValue+"-2"))
+
+		var values map[string]string
+		values, err = c.GetMulti(context.Background(), testKey+"-1", testKey+"-2", testKey+"-3")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			testKey + "-1": testValue + "-1",
+			testKey + "-2": testValue + "-2",
+		}, values)
+
+		_, err = local.Get([]byte(testKey + "-2"))
+		require.NoError(t, err)
+	})
+
+	t.Run("increment removes the local copy", func(t *testing.T) {
+		c, _, _ := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey, "1")
+		require.NoError(t, err)
+
+		var count int64
+		count, err = c.Increment(context.Background(), testKey, 2)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		var val string
+		val, err = c.Get(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.Equal(t, "3", val)
+	})
+
+	t.Run("locks use redis", func(t *testing.T) {
+		c, r, _ := newTieredTestClient(t)
+
+		secret, err := c.WriteLock(context.Background(), testKey, 10)
+		require.NoError(t, err)
+		assert.True(t, r.Exists(testLockKey))
+
+		var released bool
+		released, err = c.ReleaseLock(context.Background(), testKey, secret)
+		require.NoError(t, err)
+		assert.True(t, released)
+	})
+
+	t.Run("empty cache clears both tiers", func(t *testing.T) {
+		c, r, local := newTieredTestClient(t)
+
+		err := c.Set(context.Background(), testKey, testValue)
+		require.NoError(t, err)
+
+		err = c.EmptyCache(context.Background())
+		require.NoError(t, err)
+		assert.False(t, r.Exists(testKey))
+		assert.Equal(t, int64(0), local.EntryCount())
+	})
+
+	t.Run("close removes both tiers", func(t *testing.T) {
+		r := loadRedisInMemoryClient(t)
+		c, err := NewClient(context.Background(), WithTieredCache(nil, &RedisConfig{
+			URL: r.Addr(),
+		}, time.Minute))
+		require.NoError(t, err)
+
+		c.Close(context.Background())
+		assert.Nil(t, c.Redis())
+		assert.Nil(t, c.FreeCache())
+		assert.Equal(t, Empty, c.Engine())
+	})
+}
@@ -0,0 +1,81 @@
+package cachestore
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// versionMarker is the first byte of a model stored with a version (see: WithModelVersioning)
+const versionMarker byte = 0xc3
+
+// versionHeaderSize is the size of the version header (the marker and the version as 4 bytes, big endian)
+const versionHeaderSize = 5
+
+// VersionedModel is a model with a version (see: WithModelVersioning)
+//
+// Increment the version when the fields of the model change, the models stored with another version are a miss
+type VersionedModel interface {
+	CacheVersion() int
+}
+
+// modelVersionKey is the context key of the model version
+type modelVersionKey struct{}
+
+// ContextWithModelVersion will return a child context with the version of the models (see: WithModelVersioning)
+//
+// The version of the context is used instead of CacheVersion() (see: VersionedModel), zero or less is unversioned
+func ContextWithModelVersion(ctx context.Context, version int) context.Context {
+	return context.WithValue(ctx, modelVersionKey{}, version)
+}
+
+// modelVersion will return the version of the model (the context first, then CacheVersion), zero if unversioned
+func modelVersion(ctx context.Context, model interface{}) uint32 {
+	if ctx != nil {
+		if version, ok := ctx.Value(modelVersionKey{}).(int); ok {
+			return validVersion(version)
+		}
+	}
+	if versioned, ok := model.(VersionedModel); ok && !isNilPointer(model) {
+		return validVersion(versioned.CacheVersion())
+	}
+	return 0
+}
+
+// validVersion will return the version, zero if the version is out of range (unversioned)
+func validVersion(version int) uint32 {
+	if version <= 0 || version > math.MaxUint32 {
+		return 0
+	}
+	return uint32(version)
+}
+
+// addVersion will prepend the version header to the data (if enabled and the model has a version)
+func (c *Client) addVersion(ctx context.Context, data []byte, model interface{}) []byte {
+	version := modelVersion(ctx, model)
+	if !c.options.modelVersioning || version == 0 {
+		return data
+	}
+	versioned := make([]byte, versionHeaderSize, versionHeaderSize+len(data))
+	versioned[0] = versionMarker
+	binary.BigEndian.PutUint32(versioned[1:], version)
+	return append(versioned, data...)
+}
+
+// checkVersion will remove the version header and return ErrModelVersionMismatch if the version is not the model version
+//
+// A value without the header is unversioned (zero), the data is returned as is if versioning is disabled
+func (c *Client) checkVersion(ctx context.Context, data []byte, model interface{}) ([]byte, error) {
+	if !c.options.modelVersioning {
+		return data, nil
+	}
+	var stored uint32
+	if len(data) >= versionHeaderSize && data[0] == versionMarker {
+		stored, data = binary.BigEndian.Uint32(data[1:versionHeaderSize]), data[versionHeaderSize:]
+	}
+	if expected := modelVersion(ctx, model); stored != expected {
+		return nil, fmt.Errorf("%w: stored version %d, model version %d", ErrModelVersionMismatch, stored, expected)
+	}
+	return data, nil
+}
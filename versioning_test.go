@@ -0,0 +1,155 @@
+package cachestore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionedModel is a model with a version (see: VersionedModel), the version is not stored in the value
+type versionedModel struct {
+	Name    string `json:"name" msgpack:"name"`
+	Version int    `json:"-" msgpack:"-"`
+}
+
+// CacheVersion will return the version of the model
+func (m *versionedModel) CacheVersion() int {
+	return m.Version
+}
+
+// TestClient_ModelVersioning will test storing and checking the version of the models (WithModelVersioning)
+func TestClient_ModelVersioning(t *testing.T) {
+
+	testCases := getInMemoryTestCases(t)
+	for _, testCase := range testCases {
+		opts := withClientOps(testCase.opts, WithModelVersioning())
+
+		t.Run(testCase.name+" - reading another version is a mismatch", func(t *testing.T) {
+			c, err := NewClient(context.Background(), opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			v1 := ContextWithModelVersion(context.Background(), 1)
+			v2 := ContextWithModelVersion(context.Background(), 2)
+			require.NoError(t, c.SetModel(v1, testKey, &genericStruct{StringField: testValue}, 0))
+
+			model := new(genericStruct)
+			err = c.GetModel(v2, testKey, model)
+			require.ErrorIs(t, err, ErrModelVersionMismatch)
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			assert.Empty(t, model.StringField)
+
+			// The same version is read
+			require.NoError(t, c.GetModel(v1, testKey, model))
+			assert.Equal(t, testValue, model.StringField)
+
+			// A mismatch is a miss
+			stats := c.Stats()
+			assert.Equal(t, int64(1), stats.Hits)
+			assert.Equal(t, int64(1), stats.Misses)
+		})
+
+		t.Run(testCase.name+" - the version of the model (CacheVersion)", func(t *testing.T) {
+			c, err := NewClient(context.Background(), withClientOps(opts, WithMsgpack()))
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &versionedModel{Name: testValue, Version: 1}, 0))
+
+			model := &versionedModel{Version: 2}
+			_, err = c.GetModelWithTTL(context.Background(), testKey, model)
+			require.ErrorIs(t, err, ErrModelVersionMismatch)
+
+			model.Version = 1
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			assert.Equal(t, testValue, model.Name)
+
+			// The version of the context is used first
+			require.ErrorIs(t, c.GetModel(ContextWithModelVersion(context.Background(), 3), testKey, model), ErrModelVersionMismatch)
+		})
+
+		t.Run(testCase.name+" - unversioned values are a mismatch", func(t *testing.T) {
+			c, err := NewClient(context.Background(), opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &genericStruct{StringField: testValue}, 0))
+			model := new(genericStruct)
+			require.NoError(t, c.GetModel(context.Background(), testKey, model))
+			require.ErrorIs(t, c.GetModel(ContextWithModelVersion(context.Background(), 1), testKey, model), ErrModelVersionMismatch)
+		})
+
+		t.Run(testCase.name+" - GetOrSetModel loads a mismatch again", func(t *testing.T) {
+			c, err := NewClient(context.Background(), opts)
+			require.NoError(t, err)
+			defer func() {
+				_ = c.EmptyCache(context.Background())
+			}()
+
+			require.NoError(t, c.SetModel(context.Background(), testKey, &versionedModel{Name: "old", Version: 1}, 0))
+
+			var loads int
+			model := &versionedModel{Version: 2}
+			err = c.GetOrSetModel(context.Background(), testKey, model, 0, func(context.Context) (interface{}, error) {
+				loads++
+				return &versionedModel{Name: testValue, Version: 2}, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, 1, loads)
+			assert.Equal(t, testValue, model.Name)
+
+			// The new version is stored
+			require.NoError(t, c.GetModel(context.Background(), testKey, &versionedModel{Version: 2}))
+		})
+	}
+
+	t.Run("disabled: the version is not stored or checked", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &versionedModel{Name: testValue, Version: 1}, 0))
+		stored, err := c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"`+testValue+`"}`, string(stored))
+
+		model := &versionedModel{Version: 2}
+		require.NoError(t, c.GetModel(context.Background(), testKey, model))
+		assert.Equal(t, testValue, model.Name)
+	})
+
+	t.Run("the version header is stored before the value", func(t *testing.T) {
+		c, err := NewClient(context.Background(), WithFreeCache(), WithModelVersioning())
+		require.NoError(t, err)
+		defer c.Close(context.Background())
+
+		require.NoError(t, c.SetModel(context.Background(), testKey, &versionedModel{Name: testValue, Version: 258}, 0))
+		stored, err := c.GetBytes(context.Background(), testKey)
+		require.NoError(t, err)
+		require.Greater(t, len(stored), versionHeaderSize)
+		assert.Equal(t, []byte{versionMarker, 0, 0, 1, 2}, stored[:versionHeaderSize])
+		assert.JSONEq(t, `{"name":"`+testValue+`"}`, string(stored[versionHeaderSize:]))
+	})
+}
+
+// Test_modelVersion will test the method modelVersion()
+func Test_modelVersion(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	assert.Equal(t, uint32(0), modelVersion(ctx, new(genericStruct)))
+	assert.Equal(t, uint32(3), modelVersion(ctx, &versionedModel{Version: 3}))
+	assert.Equal(t, uint32(0), modelVersion(ctx, &versionedModel{Version: -1}))
+	assert.Equal(t, uint32(0), modelVersion(ctx, (*versionedModel)(nil)))
+	assert.Equal(t, uint32(5), modelVersion(ContextWithModelVersion(ctx, 5), &versionedModel{Version: 3}))
+	assert.Equal(t, uint32(0), modelVersion(ContextWithModelVersion(ctx, 0), &versionedModel{Version: 3}))
+	assert.Equal(t, uint32(7), modelVersion(nil, &versionedModel{Version: 7})) //nolint:staticcheck // testing a nil context
+}